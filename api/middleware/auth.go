@@ -10,6 +10,12 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// UsernameKey is the key of the authenticated username in the gin context.
+const UsernameKey = "username"
+
+// AnonymousUsername is used for changes made when authentication is skipped.
+const AnonymousUsername = "anonymous"
+
 func GenerateToken(username string, jwtKey []byte) (string, error) {
 	expirationTime := time.Now().Add(7 * 24 * time.Hour)
 	claims := &models.Claims{
@@ -37,7 +43,7 @@ func AuthMiddleware(jwtKey []byte) gin.HandlerFunc {
 			c.Abort()
 			return
 		}
-		c.Set("username", claims.Username)
+		c.Set(UsernameKey, claims.Username)
 		c.Next()
 	}
 }
@@ -45,3 +51,11 @@ func AuthMiddleware(jwtKey []byte) gin.HandlerFunc {
 func AllowAnonymous() gin.HandlerFunc {
 	return func(ctx *gin.Context) {}
 }
+
+// Username returns the authenticated username, or AnonymousUsername when there is none.
+func Username(ctx *gin.Context) string {
+	if username := ctx.GetString(UsernameKey); username != "" {
+		return username
+	}
+	return AnonymousUsername
+}
@@ -0,0 +1,71 @@
+package rest
+
+import (
+	"errors"
+	"gotranslate/core/contracts"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDeadLetters lists the messages that failed all their retries, 'limit' defaults to 50.
+func GetDeadLetters(queueClient contracts.QueueService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		deadLetters, supported := queueClient.(contracts.DeadLetterQueue)
+		if !supported {
+			errorResult(ctx, http.StatusNotImplemented, "the queue doesn't keep dead letters")
+			return
+		}
+
+		limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+		if err != nil || limit <= 0 {
+			badRequest(ctx, "limit must be a positive number")
+			return
+		}
+
+		results, err := deadLetters.DeadLetters(limit)
+		if err != nil {
+			errorResult(ctx, http.StatusInternalServerError, "there was a problem retrieving the dead letters")
+			return
+		}
+
+		okData(ctx, results)
+	}
+}
+
+// ReplayDeadLetters publishes dead letters again, the body has the 'ids' to replay or 'all' to replay all of them.
+func ReplayDeadLetters(queueClient contracts.QueueService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		deadLetters, supported := queueClient.(contracts.DeadLetterQueue)
+		if !supported {
+			errorResult(ctx, http.StatusNotImplemented, "the queue doesn't keep dead letters")
+			return
+		}
+
+		var request struct {
+			IDs []string `json:"ids"`
+			All bool     `json:"all"`
+		}
+		if err := ctx.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+			badRequest(ctx, "invalid request")
+			return
+		}
+		if request.All && len(request.IDs) > 0 {
+			badRequest(ctx, "set either 'ids' or 'all'")
+			return
+		} else if !request.All && len(request.IDs) == 0 {
+			badRequest(ctx, "set the 'ids' to replay, or 'all': true to replay all the dead letters")
+			return
+		}
+
+		replayed, err := deadLetters.ReplayDeadLetters(request.IDs...)
+		if err != nil {
+			errorResult(ctx, http.StatusInternalServerError, "there was a problem replaying the dead letters")
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"replayed": replayed})
+	}
+}
@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"gotranslate/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// deadLetterQueueStub records the ids that were replayed
+type deadLetterQueueStub struct {
+	queueStub
+	replays [][]string
+}
+
+func (q *deadLetterQueueStub) DeadLetters(limit int) ([]models.DeadLetter, error) {
+	return []models.DeadLetter{}, nil
+}
+
+func (q *deadLetterQueueStub) ReplayDeadLetters(ids ...string) (int, error) {
+	q.replays = append(q.replays, ids)
+	return len(ids), nil
+}
+
+func TestReplayDeadLetters_ShouldOnlyReplayAllWhenAsked(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name           string
+		body           string
+		chunked        bool
+		expectedCode   int
+		expectedReplay []string
+	}{
+		{name: "no body", body: "", expectedCode: http.StatusBadRequest},
+		{name: "chunked ids", body: `{"ids": ["a"]}`, chunked: true, expectedCode: http.StatusOK, expectedReplay: []string{"a"}},
+		{name: "all", body: `{"all": true}`, expectedCode: http.StatusOK, expectedReplay: []string{}},
+		{name: "ids and all", body: `{"ids": ["a"], "all": true}`, expectedCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			queue := &deadLetterQueueStub{}
+			router := gin.New()
+			router.POST("/admin/deadletters/replay", ReplayDeadLetters(queue))
+			request := httptest.NewRequest(http.MethodPost, "/admin/deadletters/replay", strings.NewReader(tt.body))
+			if tt.chunked {
+				request.ContentLength = -1
+			}
+			recorder := httptest.NewRecorder()
+
+			// act
+			router.ServeHTTP(recorder, request)
+
+			// assert
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedReplay == nil {
+				assert.Empty(t, queue.replays)
+			} else {
+				assert.Len(t, queue.replays, 1)
+				assert.ElementsMatch(t, tt.expectedReplay, queue.replays[0])
+			}
+		})
+	}
+}
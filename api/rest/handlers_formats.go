@@ -0,0 +1,276 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gotranslate/api/middleware"
+	"gotranslate/core/contracts"
+	"gotranslate/core/formats"
+	"gotranslate/models"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ExportResources(repo contracts.ResoureRepository, formatters *formats.Registry) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		format := ctx.Query("format")
+		formatter, found := formatters.Get(format)
+		if !found {
+			badRequest(ctx, fmt.Sprintf("unsupported format '%v', use one of: %v", format, strings.Join(formatters.Names(), ", ")))
+			return
+		}
+
+		options := formatOptionsFromQuery(ctx)
+		if len(options.LanguageCodes) == 0 {
+			badRequest(ctx, "language code must be 2 letters")
+			return
+		}
+
+		statuses, err := statusesFromQuery(ctx)
+		if err != nil {
+			badRequest(ctx, err.Error())
+			return
+		}
+
+		project, found := projectFromRequest(ctx, repo)
+		if !found {
+			return
+		}
+
+		sourceLanguage := exportSourceLanguage(ctx, project, options)
+		var resources []models.Resource
+		for _, languageCode := range options.LanguageCodes {
+			if !languageCodeIsValid(languageCode) {
+				badRequest(ctx, "language code must be 2 letters")
+				return
+			}
+
+			languageResources, err := repo.GetResourcesByLanguageCode(project.Name, languageCode)
+			if err != nil {
+				errorResult(ctx, http.StatusInternalServerError, "there was a problem retrieving the resources from the database")
+				return
+			}
+			if languageCode != sourceLanguage {
+				languageResources = withStatus(languageResources, statuses)
+			}
+			resources = append(resources, languageResources...)
+		}
+
+		file, err := formatter.Encode(resources, options)
+		if err != nil {
+			badRequest(ctx, err.Error())
+			return
+		}
+
+		if len(file.RenamedKeys) > 0 {
+			mapping, _ := json.Marshal(file.RenamedKeys)
+			ctx.Header("X-Renamed-Keys", string(mapping))
+		}
+		fileResult(ctx, file.FileName, file.ContentType, file.Data)
+	}
+}
+
+func ImportResources(repo contracts.ResoureRepository, formatters *formats.Registry, queueClient contracts.QueueService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		format := ctx.Query("format")
+		formatter, found := formatters.Get(format)
+		if !found {
+			badRequest(ctx, fmt.Sprintf("unsupported format '%v', use one of: %v", format, strings.Join(formatters.Names(), ", ")))
+			return
+		}
+
+		project, found := projectFromRequest(ctx, repo)
+		if !found {
+			return
+		}
+
+		retranslate, err := retranslateFromQuery(ctx, project)
+		if err != nil {
+			badRequest(ctx, err.Error())
+			return
+		}
+
+		data, err := readUploadedFile(ctx)
+		if err != nil {
+			badRequest(ctx, err.Error())
+			return
+		}
+
+		decoded, err := formatter.Decode(data, formatOptionsFromQuery(ctx))
+		if err != nil {
+			badRequest(ctx, err.Error())
+			return
+		}
+
+		results := []models.ImportResult{}
+		resourcesByLanguage := map[string][]models.Resource{}
+		var languageCodes []string
+		for _, item := range decoded {
+			resource := item.Resource
+			switch {
+			case resource.Key == "":
+				results = append(results, models.ImportResult{Status: models.ImportInvalid, Message: "entry has no key"})
+			case item.SkipReason != "":
+				results = append(results, models.ImportResult{Key: resource.Key, Status: models.ImportSkipped, Message: item.SkipReason})
+			case !languageCodeIsValid(resource.LanguageCode):
+				results = append(results, models.ImportResult{Key: resource.Key, Status: models.ImportInvalid, Message: "language code must be 2 letters, set it with 'languagecode' or in the language of the file"})
+			default:
+				if _, exists := resourcesByLanguage[resource.LanguageCode]; !exists {
+					languageCodes = append(languageCodes, resource.LanguageCode)
+				}
+				resourcesByLanguage[resource.LanguageCode] = append(resourcesByLanguage[resource.LanguageCode], resource)
+			}
+		}
+
+		var updated []models.Resource
+		for _, languageCode := range languageCodes {
+			saved, err := saveImportedResources(repo, project.Name, languageCode, middleware.Username(ctx), resourcesByLanguage[languageCode])
+			if err != nil {
+				errorResult(ctx, http.StatusInternalServerError, "there was a problem saving the imported resources")
+				return
+			}
+			for _, result := range saved {
+				if result.Status == models.ImportUpdated {
+					updated = append(updated, models.Resource{Key: result.Key, LanguageCode: languageCode})
+				}
+			}
+			results = append(results, saved...)
+		}
+
+		if retranslate {
+			queueRetranslations(ctx, repo, queueClient, project, updated)
+		}
+
+		okData(ctx, results)
+	}
+}
+
+// formatOptionsFromQuery reads the options of the formatters. 'languagecode' can be a comma separated list for formats
+// with many languages, and 'targetlanguagecode' is added after it for source/target formats.
+func formatOptionsFromQuery(ctx *gin.Context) models.FormatOptions {
+	options := models.FormatOptions{
+		Version:   ctx.Query("version"),
+		Nested:    ctx.Query("nested") == "true",
+		Separator: ctx.Query("separator"),
+	}
+
+	for _, languageCode := range strings.Split(ctx.Query("languagecode")+","+ctx.Query("targetlanguagecode"), ",") {
+		if languageCode = strings.TrimSpace(languageCode); languageCode != "" {
+			options.LanguageCodes = append(options.LanguageCodes, languageCode)
+		}
+	}
+
+	return options
+}
+
+// exportSourceLanguage returns the language that is exported without the status filter, the first language of
+// source/target formats or the source language of the project.
+func exportSourceLanguage(ctx *gin.Context, project models.Project, options models.FormatOptions) string {
+	if ctx.Query("targetlanguagecode") != "" {
+		return options.LanguageCodes[0]
+	}
+	return project.SourceLanguage
+}
+
+// saveImportedResources adds new resources and updates the existing ones that changed, reporting the outcome per resource.
+// The status is only changed for formats that store it, and only when the workflow allows the transition.
+func saveImportedResources(repo contracts.ResoureRepository, project, languageCode, username string, resources []models.Resource) ([]models.ImportResult, error) {
+	existingResources, err := repo.GetResourcesByLanguageCode(project, languageCode)
+	if err != nil {
+		return nil, err
+	}
+
+	existingByKey := map[string]models.Resource{}
+	for _, resource := range existingResources {
+		existingByKey[resource.Key] = resource
+	}
+
+	var results []models.ImportResult
+	var toAdd, toUpdate, statusChanges []models.Resource
+	seen := map[string]bool{}
+	for _, resource := range inProject(resources, project) {
+		if seen[resource.Key] {
+			results = append(results, models.ImportResult{Key: resource.Key, Status: models.ImportInvalid, Message: "duplicate key in file"})
+			continue
+		}
+		seen[resource.Key] = true
+
+		existing, exists := existingByKey[resource.Key]
+		textChanged := exists && existing.Text != resource.Text
+		// a changed text is a manual edit, the status in the file moves on from there
+		currentStatus := existing.Status
+		if textChanged {
+			currentStatus = models.StatusEdited
+		}
+		statusChanged := exists && resource.Status != "" && currentStatus != resource.Status
+		statusRejected := statusChanged && !models.CanTransition(currentStatus, resource.Status)
+		rejection := fmt.Sprintf("status can't change from '%v' to '%v'", currentStatus, resource.Status)
+		if !exists {
+			toAdd = append(toAdd, resource)
+			results = append(results, models.ImportResult{Key: resource.Key, Status: models.ImportCreated})
+		} else if statusRejected && !textChanged {
+			results = append(results, models.ImportResult{Key: resource.Key, Status: models.ImportInvalid, Message: rejection})
+		} else if textChanged || statusChanged {
+			result := models.ImportResult{Key: resource.Key, Status: models.ImportUpdated}
+			if textChanged {
+				toUpdate = append(toUpdate, resource)
+			}
+			if statusRejected {
+				result.Message = "text updated, " + rejection
+			} else if statusChanged {
+				statusChanges = append(statusChanges, resource)
+			}
+			results = append(results, result)
+		} else {
+			results = append(results, models.ImportResult{Key: resource.Key, Status: models.ImportSkipped, Message: "unchanged"})
+		}
+	}
+
+	if len(toAdd) > 0 {
+		if err := repo.AddResources(toAdd...); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(toUpdate) > 0 {
+		if _, err := repo.UpdateResourceValues(username, toUpdate...); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(statusChanges) > 0 {
+		if _, err := repo.UpdateResourceStatus(statusChanges...); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// readUploadedFile reads the 'file' field of a multipart form, or the raw request body for any other content type.
+func readUploadedFile(ctx *gin.Context) ([]byte, error) {
+	if ctx.ContentType() != "multipart/form-data" {
+		data, err := io.ReadAll(ctx.Request.Body)
+		if err != nil || len(data) == 0 {
+			return nil, errors.New("unable to read request body")
+		}
+		return data, nil
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return nil, errors.New("form field 'file' is missing")
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, errors.New("unable to read uploaded file")
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
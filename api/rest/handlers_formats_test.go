@@ -0,0 +1,124 @@
+package rest
+
+import (
+	"gotranslate/core/contracts"
+	"gotranslate/core/formats"
+	"gotranslate/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// resourceRepositoryStub serves the resources of the default project from memory
+type resourceRepositoryStub struct {
+	contracts.ResoureRepository
+	project       models.Project
+	resources     []models.Resource
+	updatedTexts  []models.Resource
+	updatedStatus []models.Resource
+}
+
+func (r *resourceRepositoryStub) UpdateResourceValues(username string, resources ...models.Resource) (int64, error) {
+	r.updatedTexts = append(r.updatedTexts, resources...)
+	return int64(len(resources)), nil
+}
+
+func (r *resourceRepositoryStub) UpdateResourceStatus(resources ...models.Resource) (int64, error) {
+	r.updatedStatus = append(r.updatedStatus, resources...)
+	return int64(len(resources)), nil
+}
+
+func (r *resourceRepositoryStub) GetProject(name string) (models.Project, bool, error) {
+	return r.project, r.project.Name == name, nil
+}
+
+func (r *resourceRepositoryStub) GetResourcesByLanguageCode(project, languageCode string) ([]models.Resource, error) {
+	results := []models.Resource{}
+	for _, resource := range r.resources {
+		if resource.Project == project && resource.LanguageCode == languageCode {
+			results = append(results, resource)
+		}
+	}
+	return results, nil
+}
+
+func (r *resourceRepositoryStub) ExistingLanguageCodes(project string) ([]models.LanguageResult, error) {
+	seen := map[string]bool{}
+	var results []models.LanguageResult
+	for _, resource := range r.resources {
+		if resource.Project == project && !seen[resource.LanguageCode] {
+			seen[resource.LanguageCode] = true
+			results = append(results, models.LanguageResult{LanguageCode: resource.LanguageCode})
+		}
+	}
+	return results, nil
+}
+
+func TestExportResources_WhenXLIFFFilteredByStatus_ShouldExportAllSourcesAndFilteredTargets(t *testing.T) {
+	// arrange
+	gin.SetMode(gin.TestMode)
+	repo := &resourceRepositoryStub{
+		project: models.Project{Name: models.DefaultProject, SourceLanguage: "en"},
+		resources: []models.Resource{
+			{Project: models.DefaultProject, Key: "hello", LanguageCode: "en", Text: "Hello", Status: models.StatusUntranslated},
+			{Project: models.DefaultProject, Key: "bye", LanguageCode: "en", Text: "Bye", Status: models.StatusUntranslated},
+			{Project: models.DefaultProject, Key: "hello", LanguageCode: "de", Text: "Hallo", Status: models.StatusApproved},
+			{Project: models.DefaultProject, Key: "bye", LanguageCode: "de", Text: "Tschüss", Status: models.StatusMachine},
+		},
+	}
+	router := gin.New()
+	router.GET("/resources/export", ExportResources(repo, formats.NewDefaultRegistry()))
+	request := httptest.NewRequest(http.MethodGet, "/resources/export?format=xliff&languagecode=en&targetlanguagecode=de&status=approved", nil)
+	recorder := httptest.NewRecorder()
+
+	// act
+	router.ServeHTTP(recorder, request)
+
+	// assert
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	document, err := formats.DecodeXLIFF(recorder.Body.Bytes())
+	assert.NoError(t, err)
+	units := map[string]formats.XLIFFUnit{}
+	for _, unit := range document.Units {
+		units[unit.ID] = unit
+	}
+	assert.Len(t, units, 2)
+	assert.Equal(t, "Hallo", units["hello"].Target)
+	assert.Equal(t, models.StatusApproved, units["hello"].Status())
+	assert.Equal(t, "Bye", units["bye"].Source)
+	assert.Equal(t, "", units["bye"].Target)
+}
+
+func TestSaveImportedResources_WhenStatusTransitionNotAllowed_ShouldReportIt(t *testing.T) {
+	// arrange
+	repo := &resourceRepositoryStub{
+		resources: []models.Resource{
+			{Project: models.DefaultProject, Key: "machine", LanguageCode: "de", Text: "Maschine", Status: models.StatusMachine},
+			{Project: models.DefaultProject, Key: "reviewed", LanguageCode: "de", Text: "Geprüft", Status: models.StatusReviewed},
+			{Project: models.DefaultProject, Key: "edited", LanguageCode: "de", Text: "Alt", Status: models.StatusMachine},
+		},
+	}
+	imported := []models.Resource{
+		{Project: models.DefaultProject, Key: "machine", LanguageCode: "de", Text: "Maschine", Status: models.StatusApproved},
+		{Project: models.DefaultProject, Key: "reviewed", LanguageCode: "de", Text: "Geprüft", Status: models.StatusApproved},
+		{Project: models.DefaultProject, Key: "edited", LanguageCode: "de", Text: "Neu", Status: models.StatusMachine},
+	}
+
+	// act
+	results, err := saveImportedResources(repo, models.DefaultProject, "de", "tester", imported)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ImportResult{
+		{Key: "machine", Status: models.ImportInvalid, Message: "status can't change from 'machine' to 'approved'"},
+		{Key: "reviewed", Status: models.ImportUpdated},
+		{Key: "edited", Status: models.ImportUpdated, Message: "text updated, status can't change from 'reviewed' to 'machine'"},
+	}, results)
+	assert.Len(t, repo.updatedStatus, 1)
+	assert.Equal(t, "reviewed", repo.updatedStatus[0].Key)
+	assert.Len(t, repo.updatedTexts, 1)
+	assert.Equal(t, "edited", repo.updatedTexts[0].Key)
+}
@@ -0,0 +1,70 @@
+package rest
+
+import (
+	"errors"
+	"gotranslate/api/middleware"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetResourceHistory lists the revisions of a key, newest first, optionally for one language.
+func GetResourceHistory(repo contracts.ResoureRepository) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		languageCode, key := ctx.Query("languagecode"), ctx.Query("key")
+		if len(key) == 0 {
+			badRequest(ctx, "To get the history of a resource you need to provide a valid key")
+			return
+		}
+		if len(languageCode) > 0 && !languageCodeIsValid(languageCode) {
+			badRequest(ctx, "language code must be 2 letters")
+			return
+		}
+
+		project, found := projectFromRequest(ctx, repo)
+		if !found {
+			return
+		}
+
+		revisions, err := repo.GetRevisions(project.Name, key, languageCode)
+		if err != nil {
+			errorResult(ctx, http.StatusInternalServerError, "there was a problem retrieving the history of the resource")
+			return
+		}
+
+		okData(ctx, revisions)
+	}
+}
+
+// RollbackResource sets the resource of a revision back to the text it had before it, and returns the new revision.
+func RollbackResource(repo contracts.ResoureRepository) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		revisionID, err := strconv.ParseInt(ctx.Param("revision"), 10, 64)
+		if err != nil {
+			badRequest(ctx, "revision must be a number")
+			return
+		}
+
+		project, found := projectFromRequest(ctx, repo)
+		if !found {
+			return
+		}
+
+		revision, err := repo.RollbackRevision(project.Name, revisionID, middleware.Username(ctx))
+		if errors.Is(err, contracts.ErrRevisionNotFound) {
+			errorResult(ctx, http.StatusNotFound, err.Error())
+			return
+		} else if errors.Is(err, contracts.ErrNothingToRollBack) {
+			badRequest(ctx, "the revision restored a deleted resource, roll back an earlier revision instead")
+			return
+		} else if err != nil {
+			errorResult(ctx, http.StatusInternalServerError, "there was a problem rolling back the resource")
+			return
+		}
+
+		okData(ctx, []models.Revision{revision})
+	}
+}
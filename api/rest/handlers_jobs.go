@@ -0,0 +1,40 @@
+package rest
+
+import (
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetJob(repo contracts.ResoureRepository) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+		if err != nil {
+			badRequest(ctx, "job id must be a number")
+			return
+		}
+
+		job, found, err := repo.GetJob(id)
+		if err != nil {
+			errorResult(ctx, http.StatusInternalServerError, "there was a problem retrieving the job")
+			return
+		} else if !found {
+			errorResult(ctx, http.StatusNotFound, fmt.Sprintf("job %d not found", id))
+			return
+		}
+
+		okData(ctx, []models.Job{job})
+	}
+}
+
+// acceptedJob responds that the work was queued, with the url to follow the job in the Location header.
+func acceptedJob(ctx *gin.Context, job models.Job) {
+	ctx.Header("Location", fmt.Sprintf("/jobs/%d", job.ID))
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"data": []models.Job{job},
+	})
+}
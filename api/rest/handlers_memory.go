@@ -0,0 +1,47 @@
+package rest
+
+import (
+	"gotranslate/core/contracts"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMemorySuggestions lists the approved translations of texts similar to 'text' from the whole catalog, the most
+// similar first. 'minsimilarity' is between 0 and 1 and defaults to 0.75, 'limit' defaults to 5.
+func GetMemorySuggestions(repo contracts.ResoureRepository) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		memory, supported := repo.(contracts.TranslationMemory)
+		if !supported {
+			errorResult(ctx, http.StatusNotImplemented, "the repository doesn't have a translation memory")
+			return
+		}
+
+		sourceLanguage, targetLanguage, text := ctx.Query("sourcelanguage"), ctx.Query("targetlanguage"), ctx.Query("text")
+		if !languageCodeIsValid(sourceLanguage) || !languageCodeIsValid(targetLanguage) || text == "" {
+			badRequest(ctx, "invalid input")
+			return
+		}
+
+		minSimilarity, err := strconv.ParseFloat(ctx.DefaultQuery("minsimilarity", "0.75"), 64)
+		if err != nil || minSimilarity <= 0 || minSimilarity > 1 {
+			badRequest(ctx, "minsimilarity must be above 0 and up to 1")
+			return
+		}
+
+		limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "5"))
+		if err != nil || limit <= 0 {
+			badRequest(ctx, "limit must be a positive number")
+			return
+		}
+
+		matches, err := memory.FindFuzzyMatches(sourceLanguage, targetLanguage, text, minSimilarity, limit)
+		if err != nil {
+			errorResult(ctx, http.StatusInternalServerError, "there was a problem searching the translation memory")
+			return
+		}
+
+		okData(ctx, matches)
+	}
+}
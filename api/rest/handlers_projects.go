@@ -0,0 +1,122 @@
+package rest
+
+import (
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetProjects(repo contracts.ResoureRepository) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		projects, err := repo.GetProjects()
+		if err != nil {
+			errorResult(ctx, http.StatusInternalServerError, "there was a problem retrieving the projects")
+			return
+		}
+
+		okData(ctx, projects)
+	}
+}
+
+func GetProject(repo contracts.ResoureRepository) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		project, found := projectFromRequest(ctx, repo)
+		if !found {
+			return
+		}
+
+		okData(ctx, []models.Project{project})
+	}
+}
+
+func AddProject(repo contracts.ResoureRepository) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var project models.Project
+		if err := ctx.ShouldBindJSON(&project); err != nil {
+			badRequest(ctx, "invalid request")
+			return
+		}
+
+		if errors := validateProjectData(project); errors.HasErrors() {
+			badRequest(ctx, errors.AllErrors()...)
+			return
+		}
+
+		_, exists, err := repo.GetProject(project.Name)
+		if err != nil {
+			errorResult(ctx, http.StatusInternalServerError, "there was a problem retrieving the project")
+			return
+		}
+		if exists {
+			errorResult(ctx, http.StatusConflict, fmt.Sprintf("project '%v' already exists", project.Name))
+			return
+		}
+
+		if err := repo.SaveProject(withTargetLanguages(project)); err != nil {
+			errorResult(ctx, http.StatusInternalServerError, "there was an error adding the project to the database")
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{})
+	}
+}
+
+// UpdateProject changes the source and target languages of the project in the url, the name can't be changed.
+func UpdateProject(repo contracts.ResoureRepository) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		existing, found := projectFromRequest(ctx, repo)
+		if !found {
+			return
+		}
+
+		var project models.Project
+		if err := ctx.ShouldBindJSON(&project); err != nil {
+			badRequest(ctx, "invalid request")
+			return
+		}
+		project.Name = existing.Name
+
+		if errors := validateProjectData(project); errors.HasErrors() {
+			badRequest(ctx, errors.AllErrors()...)
+			return
+		}
+
+		if err := repo.SaveProject(withTargetLanguages(project)); err != nil {
+			errorResult(ctx, http.StatusInternalServerError, "there was a problem updating the project")
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// projectFromRequest loads the project of the ':project' route parameter, or the default project on routes without it.
+// It writes the error response and returns false when the project can't be used.
+func projectFromRequest(ctx *gin.Context, repo contracts.ResoureRepository) (models.Project, bool) {
+	name := ctx.Param("project")
+	if name == "" {
+		name = models.DefaultProject
+	}
+
+	project, found, err := repo.GetProject(name)
+	if err != nil {
+		errorResult(ctx, http.StatusInternalServerError, "there was a problem retrieving the project")
+		return project, false
+	}
+	if !found {
+		errorResult(ctx, http.StatusNotFound, fmt.Sprintf("project '%v' not found", name))
+		return project, false
+	}
+
+	return project, true
+}
+
+func withTargetLanguages(project models.Project) models.Project {
+	if project.TargetLanguages == nil {
+		project.TargetLanguages = []string{}
+	}
+	return project
+}
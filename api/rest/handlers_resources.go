@@ -4,10 +4,14 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"gotranslate/api/middleware"
 	"gotranslate/core/contracts"
 	"gotranslate/models"
+	"gotranslate/slices"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,11 +25,22 @@ func GetResources(repo contracts.ResoureRepository) gin.HandlerFunc {
 			return
 		}
 
+		statuses, err := statusesFromQuery(ctx)
+		if err != nil {
+			badRequest(ctx, err.Error())
+			return
+		}
+
+		project, found := projectFromRequest(ctx, repo)
+		if !found {
+			return
+		}
+
 		var resources []models.Resource
 		if len(languageCode) > 0 {
-			resources, err = repo.GetResourcesByLanguageCode(languageCode)
+			resources, err = repo.GetResourcesByLanguageCode(project.Name, languageCode)
 		} else if len(key) > 0 {
-			resources, err = repo.GetResourcesByKey(key)
+			resources, err = repo.GetResourcesByKey(project.Name, key)
 		}
 
 		if err != nil {
@@ -33,7 +48,7 @@ func GetResources(repo contracts.ResoureRepository) gin.HandlerFunc {
 			return
 		}
 
-		okData(ctx, resources)
+		okData(ctx, withStatus(resources, statuses))
 	}
 }
 
@@ -50,8 +65,21 @@ func AddResources(repo contracts.ResoureRepository) gin.HandlerFunc {
 			return
 		}
 
-		err = repo.AddResources(resources...)
-		if err != nil {
+		if errors := validateNewStatus(resources); errors.HasErrors() {
+			badRequest(ctx, errors.AllErrors()...)
+			return
+		}
+
+		project, found := projectFromRequest(ctx, repo)
+		if !found {
+			return
+		}
+
+		err = repo.AddResources(inProject(resources, project.Name)...)
+		if errors.Is(err, contracts.ErrResourceConflict) {
+			errorResult(ctx, http.StatusConflict, "some of the resources already exist, update them or use PATCH to upsert")
+			return
+		} else if err != nil {
 			errorResult(ctx, http.StatusInternalServerError, "there was an error adding resources to the database")
 			return
 		}
@@ -82,6 +110,16 @@ func parseResourcesFromRequest(ctx *gin.Context) ([]models.Resource, error) {
 	return resources, nil
 }
 
+// inProject sets the project of the resources, the project in the url wins over the one in the body.
+func inProject(resources []models.Resource, project string) []models.Resource {
+	results := make([]models.Resource, len(resources))
+	for i, resource := range resources {
+		resource.Project = project
+		results[i] = resource
+	}
+	return results
+}
+
 func DeleteResources(repo contracts.ResoureRepository) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		languageCode, key := ctx.Query("languagecode"), ctx.Query("key")
@@ -95,7 +133,12 @@ func DeleteResources(repo contracts.ResoureRepository) gin.HandlerFunc {
 			return
 		}
 
-		_, err := repo.RemoveResources(key, languageCode)
+		project, found := projectFromRequest(ctx, repo)
+		if !found {
+			return
+		}
+
+		_, err := repo.RemoveResources(project.Name, key, languageCode, middleware.Username(ctx))
 		if err != nil {
 			errorResult(ctx, http.StatusInternalServerError, "there was a problem removing the specified resources")
 			return
@@ -105,8 +148,16 @@ func DeleteResources(repo contracts.ResoureRepository) gin.HandlerFunc {
 	}
 }
 
-func UpdateResources(repo contracts.ResoureRepository) gin.HandlerFunc {
+// UpdateResources changes the text of existing resources, with 'mode=upsert' it behaves like UpsertResources.
+// Changes to the source language can queue re-translations, see retranslateFromQuery.
+func UpdateResources(repo contracts.ResoureRepository, queueClient contracts.QueueService) gin.HandlerFunc {
+	upsert := UpsertResources(repo, queueClient)
 	return func(ctx *gin.Context) {
+		if ctx.Query("mode") == "upsert" {
+			upsert(ctx)
+			return
+		}
+
 		resources, err := parseResourcesFromRequest(ctx)
 		if err != nil {
 			badRequest(ctx, err.Error())
@@ -118,22 +169,197 @@ func UpdateResources(repo contracts.ResoureRepository) gin.HandlerFunc {
 			return
 		}
 
-		rowsAffected, err := repo.UpdateResourceValues(resources...)
+		project, found := projectFromRequest(ctx, repo)
+		if !found {
+			return
+		}
+
+		retranslate, err := retranslateFromQuery(ctx, project)
+		if err != nil {
+			badRequest(ctx, err.Error())
+			return
+		}
+
+		sourceResources, found := sourceResourcesBefore(ctx, repo, project, retranslate)
+		if !found {
+			return
+		}
+
+		rowsAffected, err := repo.UpdateResourceValues(middleware.Username(ctx), inProject(resources, project.Name)...)
 		if err != nil {
 			errorResult(ctx, http.StatusInternalServerError, "there was a problem updating the resources")
 			return
 		}
 		if rowsAffected == 0 {
 			errorResult(ctx, http.StatusNotFound, "no items to update found")
+			return
+		}
+
+		if retranslate {
+			queueRetranslations(ctx, repo, queueClient, project, changedTexts(sourceResources, resources))
+		}
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// UpsertResources adds the resources that don't exist and resolves the existing ones with the 'conflict' policy:
+// overwrite (default), keep or fail. Clients can sync resources without checking which ones exist.
+func UpsertResources(repo contracts.ResoureRepository, queueClient contracts.QueueService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		policy := models.ConflictPolicy(ctx.DefaultQuery("conflict", string(models.ConflictOverwrite)))
+		if !policy.IsValid() {
+			badRequest(ctx, "conflict must be one of: overwrite, keep, fail")
+			return
+		}
+
+		resources, err := parseResourcesFromRequest(ctx)
+		if err != nil {
+			badRequest(ctx, err.Error())
+			return
+		}
+
+		if errors := validateResourceData(resources); errors.HasErrors() {
+			badRequest(ctx, errors.AllErrors()...)
+			return
+		}
+
+		if errors := validateNewStatus(resources); errors.HasErrors() {
+			badRequest(ctx, errors.AllErrors()...)
+			return
+		}
+
+		project, found := projectFromRequest(ctx, repo)
+		if !found {
+			return
+		}
+
+		retranslate, err := retranslateFromQuery(ctx, project)
+		if err != nil {
+			badRequest(ctx, err.Error())
+			return
+		}
+
+		sourceResources, found := sourceResourcesBefore(ctx, repo, project, retranslate)
+		if !found {
+			return
+		}
+
+		result, err := repo.UpsertResources(policy, middleware.Username(ctx), inProject(resources, project.Name)...)
+		if errors.Is(err, contracts.ErrResourceConflict) {
+			errorResult(ctx, http.StatusConflict, err.Error())
+			return
+		} else if err != nil {
+			errorResult(ctx, http.StatusInternalServerError, "there was a problem saving the resources")
+			return
+		}
+
+		if retranslate && result.Updated > 0 && policy == models.ConflictOverwrite {
+			queueRetranslations(ctx, repo, queueClient, project, changedTexts(sourceResources, resources))
+		}
+		okData(ctx, []models.UpsertResult{result})
+	}
+}
+
+// UpdateResourcesStatus moves resources through the review workflow, the body has the Key, LanguageCode and new Status
+// of each resource. Nothing is saved when any of the transitions isn't allowed.
+func UpdateResourcesStatus(repo contracts.ResoureRepository) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		resources, err := parseResourcesFromRequest(ctx)
+		if err != nil {
+			badRequest(ctx, err.Error())
+			return
+		}
+
+		project, found := projectFromRequest(ctx, repo)
+		if !found {
+			return
+		}
+
+		var validationErrors ValidationErrors
+		loadedKeys, currentStatuses := map[string]bool{}, map[models.Resource]string{}
+		for _, resource := range resources {
+			if !languageCodeIsValid(resource.LanguageCode) || resource.Key == "" {
+				validationErrors.Add(errors.New("every resource needs a key and a language code of 2 letters"))
+				continue
+			}
+			if resource.Status != models.StatusReviewed && resource.Status != models.StatusApproved {
+				validationErrors.Add(fmt.Errorf("status of '%v' (%v) must be %v or %v", resource.Key, resource.LanguageCode, models.StatusReviewed, models.StatusApproved))
+				continue
+			}
+
+			if !loadedKeys[resource.Key] {
+				existing, err := repo.GetResourcesByKey(project.Name, resource.Key)
+				if err != nil {
+					errorResult(ctx, http.StatusInternalServerError, "there was a problem retrieving the resources from the database")
+					return
+				}
+				loadedKeys[resource.Key] = true
+				for _, item := range existing {
+					currentStatuses[models.Resource{Key: item.Key, LanguageCode: item.LanguageCode}] = item.Status
+				}
+			}
+
+			current, exists := currentStatuses[models.Resource{Key: resource.Key, LanguageCode: resource.LanguageCode}]
+			if !exists {
+				validationErrors.Add(fmt.Errorf("resource '%v' (%v) not found", resource.Key, resource.LanguageCode))
+			} else if current != resource.Status && !models.CanTransition(current, resource.Status) {
+				validationErrors.Add(fmt.Errorf("resource '%v' (%v) can't go from %v to %v", resource.Key, resource.LanguageCode, current, resource.Status))
+			}
+		}
+
+		if validationErrors.HasErrors() {
+			badRequest(ctx, validationErrors.AllErrors()...)
+			return
+		}
+
+		_, err = repo.UpdateResourceStatus(inProject(resources, project.Name)...)
+		if err != nil {
+			errorResult(ctx, http.StatusInternalServerError, "there was a problem updating the status of the resources")
+			return
 		}
 
 		ctx.Status(http.StatusNoContent)
 	}
 }
 
+// statusesFromQuery reads the 'status' filter, a comma separated list of statuses.
+func statusesFromQuery(ctx *gin.Context) ([]string, error) {
+	var statuses []string
+	for _, status := range strings.Split(ctx.Query("status"), ",") {
+		if status = strings.TrimSpace(status); status == "" {
+			continue
+		}
+		if !models.IsValidStatus(status) {
+			return nil, fmt.Errorf("status must be one of: %v, %v, %v, %v", models.StatusUntranslated, models.StatusMachine, models.StatusReviewed, models.StatusApproved)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// withStatus returns the resources in any of the statuses, or all of them when there are no statuses.
+func withStatus(resources []models.Resource, statuses []string) []models.Resource {
+	if len(statuses) == 0 {
+		return resources
+	}
+
+	results := []models.Resource{}
+	for _, resource := range resources {
+		if slices.Contains(statuses, func(status string) bool { return status == resource.Status }) {
+			results = append(results, resource)
+		}
+	}
+	return results
+}
+
 func GetAvailableLanguages(repo contracts.ResoureRepository) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		results, err := repo.ExistingLanguageCodes()
+		project, found := projectFromRequest(ctx, repo)
+		if !found {
+			return
+		}
+
+		results, err := repo.ExistingLanguageCodes(project.Name)
 		if err != nil {
 			errorResult(ctx, http.StatusInternalServerError, "there was a problem retrieving the available languages")
 			return
@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"gotranslate/core/messages"
+	"gotranslate/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddAndUpsertResources_WhenBodyHasReviewStatus_ShouldRejectIt(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &resourceRepositoryStub{project: models.Project{Name: models.DefaultProject}}
+	router := gin.New()
+	router.POST("/resources", AddResources(repo))
+	router.PATCH("/resources", UpsertResources(repo, &queueStub{}))
+
+	for _, method := range []string{http.MethodPost, http.MethodPatch} {
+		t.Run(method, func(t *testing.T) {
+			// arrange
+			body := `[{"Key": "title", "LanguageCode": "de", "Text": "Titel", "Status": "approved"}]`
+			recorder := httptest.NewRecorder()
+
+			// act
+			router.ServeHTTP(recorder, httptest.NewRequest(method, "/resources", strings.NewReader(body)))
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), "status of 'title' (de) can only be changed with PUT /resources/status")
+		})
+	}
+}
+
+// upsertRepositoryStub overwrites the resources in memory and creates jobs
+type upsertRepositoryStub struct {
+	resourceRepositoryStub
+}
+
+func (r *upsertRepositoryStub) UpsertResources(policy models.ConflictPolicy, username string, resources ...models.Resource) (models.UpsertResult, error) {
+	var result models.UpsertResult
+	for _, resource := range resources {
+		result.Created++
+		for i, existing := range r.resources {
+			if existing.Key == resource.Key && existing.LanguageCode == resource.LanguageCode {
+				r.resources[i].Text = resource.Text
+				result.Created--
+				result.Updated++
+			}
+		}
+	}
+	return result, nil
+}
+
+func (r *upsertRepositoryStub) CreateJob(job models.Job) (models.Job, error) {
+	job.ID = 1
+	return job, nil
+}
+
+func TestUpsertResources_WhenRetranslating_ShouldOnlyQueueChangedTexts(t *testing.T) {
+	// arrange
+	gin.SetMode(gin.TestMode)
+	repo := &upsertRepositoryStub{resourceRepositoryStub{
+		project: models.Project{Name: models.DefaultProject, SourceLanguage: "en"},
+		resources: []models.Resource{
+			{Project: models.DefaultProject, Key: "changed", LanguageCode: "en", Text: "Old"},
+			{Project: models.DefaultProject, Key: "same", LanguageCode: "en", Text: "Same"},
+			{Project: models.DefaultProject, Key: "changed", LanguageCode: "de", Text: "Alt"},
+		},
+	}}
+	queue := &queueStub{}
+	router := gin.New()
+	router.PATCH("/resources", UpsertResources(repo, queue))
+	body := `[{"Key": "changed", "LanguageCode": "en", "Text": "New"}, {"Key": "same", "LanguageCode": "en", "Text": "Same"}, {"Key": "added", "LanguageCode": "en", "Text": "Added"}]`
+	recorder := httptest.NewRecorder()
+
+	// act
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPatch, "/resources?retranslate=true", strings.NewReader(body)))
+
+	// assert
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Len(t, queue.published, 1)
+	assert.Equal(t, []string{"changed"}, queue.published[0].(*messages.RetranslateMessage).Keys)
+}
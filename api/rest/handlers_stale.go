@@ -0,0 +1,159 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gotranslate/api/middleware"
+	"gotranslate/core/contracts"
+	"gotranslate/core/messages"
+	"gotranslate/models"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetStaleTranslations lists the translations of 'languagecode' made from a source text that changed since.
+// The source language is the one of the project, or 'sourcelanguage' for projects without it.
+func GetStaleTranslations(repo contracts.ResoureRepository) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		project, found := projectFromRequest(ctx, repo)
+		if !found {
+			return
+		}
+
+		sourceLanguage, targetLanguage := ctx.DefaultQuery("sourcelanguage", project.SourceLanguage), ctx.Query("languagecode")
+		if !languageCodeIsValid(sourceLanguage) || !languageCodeIsValid(targetLanguage) {
+			badRequest(ctx, "languagecode and sourcelanguage must be 2 letters, sourcelanguage is only optional when the project has a source language")
+			return
+		}
+
+		sourceResources, err := repo.GetResourcesByLanguageCode(project.Name, sourceLanguage)
+		if err != nil {
+			errorResult(ctx, http.StatusInternalServerError, "there was a problem retrieving the resources from the database")
+			return
+		}
+
+		targetResources, err := repo.GetResourcesByLanguageCode(project.Name, targetLanguage)
+		if err != nil {
+			errorResult(ctx, http.StatusInternalServerError, "there was a problem retrieving the resources from the database")
+			return
+		}
+
+		okData(ctx, models.FindStaleTranslations(sourceResources, targetResources))
+	}
+}
+
+// RetranslateStale queues the re-translation of the stale machine translations of the target language.
+func RetranslateStale(repo contracts.ResoureRepository, translator contracts.Translator, queueClient contracts.QueueService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		project, sourceLanguage, targetLanguage, valid := translationLanguages(ctx, repo, false)
+		if !valid || !translatorSupports(ctx, translator, sourceLanguage, targetLanguage) {
+			return
+		}
+
+		message := &messages.RetranslateMessage{
+			Project:        project.Name,
+			Username:       middleware.Username(ctx),
+			SourceLanguage: sourceLanguage,
+			TargetLanguage: targetLanguage,
+		}
+		startJob(ctx, repo, queueClient, project.Name, sourceLanguage, targetLanguage, message, func(jobID int64) { message.JobID = jobID })
+	}
+}
+
+// retranslateFromQuery reads if changes to the source language should queue re-translations, 'retranslate' overrides
+// the setting of the project. Re-translating needs the project to have a source language.
+func retranslateFromQuery(ctx *gin.Context, project models.Project) (bool, error) {
+	switch ctx.Query("retranslate") {
+	case "":
+		return project.RetranslateStale && project.SourceLanguage != "", nil
+	case "false":
+		return false, nil
+	case "true":
+		if project.SourceLanguage == "" {
+			return false, fmt.Errorf("project '%v' needs a source language to re-translate", project.Name)
+		}
+		return true, nil
+	default:
+		return false, errors.New("retranslate must be true or false")
+	}
+}
+
+// sourceResourcesBefore loads the resources of the source language before they're changed, to find the texts that
+// changed when re-translating. It writes the error response when they can't be loaded.
+func sourceResourcesBefore(ctx *gin.Context, repo contracts.ResoureRepository, project models.Project, retranslate bool) ([]models.Resource, bool) {
+	if !retranslate {
+		return nil, true
+	}
+
+	resources, err := repo.GetResourcesByLanguageCode(project.Name, project.SourceLanguage)
+	if err != nil {
+		errorResult(ctx, http.StatusInternalServerError, "there was a problem retrieving the resources from the database")
+		return nil, false
+	}
+	return resources, true
+}
+
+// changedTexts returns the resources that existed with another text, new resources aren't re-translated.
+func changedTexts(existing, resources []models.Resource) []models.Resource {
+	texts := map[models.Resource]string{}
+	for _, resource := range existing {
+		texts[models.Resource{Key: resource.Key, LanguageCode: resource.LanguageCode}] = resource.Text
+	}
+
+	var changed []models.Resource
+	for _, resource := range resources {
+		if text, exists := texts[models.Resource{Key: resource.Key, LanguageCode: resource.LanguageCode}]; exists && text != resource.Text {
+			changed = append(changed, resource)
+		}
+	}
+	return changed
+}
+
+// queueRetranslations queues a re-translation of the changed keys of the source language to every other language,
+// the ids of the jobs are set in the X-Retranslation-Jobs header. Failing to queue them doesn't fail the request.
+func queueRetranslations(ctx *gin.Context, repo contracts.ResoureRepository, queueClient contracts.QueueService, project models.Project, changed []models.Resource) {
+	var keys []string
+	for _, resource := range changed {
+		if resource.LanguageCode == project.SourceLanguage {
+			keys = append(keys, resource.Key)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	languages, err := repo.ExistingLanguageCodes(project.Name)
+	if err != nil {
+		log.Printf("unable to queue re-translations of project %v: %v", project.Name, err)
+		return
+	}
+
+	jobIDs := []int64{}
+	for _, language := range languages {
+		if language.LanguageCode == project.SourceLanguage {
+			continue
+		}
+
+		message := &messages.RetranslateMessage{
+			Project:        project.Name,
+			Username:       middleware.Username(ctx),
+			SourceLanguage: project.SourceLanguage,
+			TargetLanguage: language.LanguageCode,
+			Keys:           keys,
+		}
+		job, err := queueJob(repo, queueClient, message.Username, project.Name, message.SourceLanguage, message.TargetLanguage, message, func(jobID int64) { message.JobID = jobID })
+		if err != nil {
+			log.Printf("unable to queue re-translation of project %v to %v: %v", project.Name, language.LanguageCode, err)
+			continue
+		}
+		jobIDs = append(jobIDs, job.ID)
+	}
+
+	if len(jobIDs) > 0 {
+		ids, _ := json.Marshal(jobIDs)
+		ctx.Header("X-Retranslation-Jobs", string(ids))
+	}
+}
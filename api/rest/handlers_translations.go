@@ -1,7 +1,9 @@
 package rest
 
 import (
+	"errors"
 	"fmt"
+	"gotranslate/api/middleware"
 	"gotranslate/core/contracts"
 	"gotranslate/core/messages"
 	"gotranslate/models"
@@ -28,12 +30,14 @@ func TranslateResource(translator contracts.Translator) gin.HandlerFunc {
 
 		if translationsCount := len(translations); translationsCount != 1 {
 			errorResult(ctx, http.StatusTeapot, fmt.Sprintf("expected 1 result from translation service but found %d", translationsCount))
+			return
 		}
 
 		results := models.Resource{
 			Key:          key,
 			LanguageCode: targetLanguage,
 			Text:         translations[0],
+			Status:       models.StatusMachine,
 		}
 
 		okData(ctx, []models.Resource{results})
@@ -42,37 +46,159 @@ func TranslateResource(translator contracts.Translator) gin.HandlerFunc {
 
 func TranslateAllToNewLanguage(repo contracts.ResoureRepository, translator contracts.Translator, queueClient contracts.QueueService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		sourceLanguage, targetLanguage := ctx.Param("sourceLanguageCode"), ctx.Param("targetLanguageCode")
-
-		if !languageCodeIsValid(targetLanguage) || !languageCodeIsValid(sourceLanguage) {
-			badRequest(ctx, "language code is invalid")
+		project, sourceLanguage, targetLanguage, valid := translationLanguages(ctx, repo, true)
+		if !valid || !translatorSupports(ctx, translator, sourceLanguage, targetLanguage) {
 			return
 		}
 
-		existingLanguages, err := repo.ExistingLanguageCodes()
-		if err != nil {
-			errorResult(ctx, http.StatusInternalServerError, "there was a problem retrieving existing languages")
-			return
+		message := &messages.TranslateLanguageMessage{
+			Project:        project.Name,
+			Username:       middleware.Username(ctx),
+			SourceLanguage: sourceLanguage,
+			TargetLanguage: targetLanguage,
 		}
+		startJob(ctx, repo, queueClient, project.Name, sourceLanguage, targetLanguage, message, func(jobID int64) { message.JobID = jobID })
+	}
+}
 
-		if slices.Contains(existingLanguages, func(item models.LanguageResult) bool { return item.LanguageCode == targetLanguage }) {
-			badRequest(ctx, "target language already exists")
-			return
-		} else if !slices.Contains(existingLanguages, func(item models.LanguageResult) bool { return item.LanguageCode == sourceLanguage }) {
-			badRequest(ctx, "source language doesn't exist")
+// TranslateMissingKeys translates the keys of the source language that don't exist in the target language yet.
+func TranslateMissingKeys(repo contracts.ResoureRepository, translator contracts.Translator, queueClient contracts.QueueService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		project, sourceLanguage, targetLanguage, valid := translationLanguages(ctx, repo, false)
+		if !valid || !translatorSupports(ctx, translator, sourceLanguage, targetLanguage) {
 			return
 		}
 
-		message := &messages.TranslateLanguageMessage{
+		message := &messages.FillGapsMessage{
+			Project:        project.Name,
+			Username:       middleware.Username(ctx),
 			SourceLanguage: sourceLanguage,
 			TargetLanguage: targetLanguage,
 		}
-		err = queueClient.Publish(message)
-		if err != nil {
-			errorResult(ctx, http.StatusInternalServerError, "something went wrong while starting the translation")
+		startJob(ctx, repo, queueClient, project.Name, sourceLanguage, targetLanguage, message, func(jobID int64) { message.JobID = jobID })
+	}
+}
+
+// translationLanguages validates the languages of the route against the project, the source language must exist and
+// the target language must be new when newTarget is set. It writes the error response when they're invalid.
+func translationLanguages(ctx *gin.Context, repo contracts.ResoureRepository, newTarget bool) (project models.Project, sourceLanguage, targetLanguage string, valid bool) {
+	sourceLanguage, targetLanguage = ctx.Param("sourceLanguageCode"), ctx.Param("targetLanguageCode")
+
+	if !languageCodeIsValid(targetLanguage) || !languageCodeIsValid(sourceLanguage) {
+		badRequest(ctx, "language code is invalid")
+		return
+	}
+
+	project, found := projectFromRequest(ctx, repo)
+	if !found {
+		return
+	}
+
+	if project.SourceLanguage != "" && project.SourceLanguage != sourceLanguage {
+		badRequest(ctx, fmt.Sprintf("the source language of project '%v' is '%v'", project.Name, project.SourceLanguage))
+		return
+	} else if !project.AllowsTargetLanguage(targetLanguage) {
+		badRequest(ctx, fmt.Sprintf("target language '%v' is not enabled for project '%v'", targetLanguage, project.Name))
+		return
+	}
+
+	existingLanguages, err := repo.ExistingLanguageCodes(project.Name)
+	if err != nil {
+		errorResult(ctx, http.StatusInternalServerError, "there was a problem retrieving existing languages")
+		return
+	}
+
+	if newTarget && slices.Contains(existingLanguages, func(item models.LanguageResult) bool { return item.LanguageCode == targetLanguage }) {
+		badRequest(ctx, "target language already exists")
+		return
+	} else if !slices.Contains(existingLanguages, func(item models.LanguageResult) bool { return item.LanguageCode == sourceLanguage }) {
+		badRequest(ctx, "source language doesn't exist")
+		return
+	}
+
+	return project, sourceLanguage, targetLanguage, true
+}
+
+// GetSupportedLanguages lists the languages the translator translates from, with the languages each one translates to.
+func GetSupportedLanguages(translator contracts.Translator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		lister, supported := translator.(contracts.LanguageLister)
+		if !supported {
+			errorResult(ctx, http.StatusNotImplemented, "the translator doesn't list its languages")
+			return
+		}
+
+		languages, err := lister.SupportedLanguages()
+		if errors.Is(err, contracts.ErrLanguagesUnknown) {
+			errorResult(ctx, http.StatusNotImplemented, "the translator doesn't list its languages")
+			return
+		} else if err != nil {
+			errorResult(ctx, http.StatusInternalServerError, fmt.Sprintf("there was a problem with the translation service: %v", err.Error()))
 			return
 		}
 
-		ctx.Status(http.StatusNoContent)
+		okData(ctx, languages)
+	}
+}
+
+// translatorSupports checks the languages with translators that list them, the others are assumed to support them.
+// It writes the error response when the translator doesn't translate between the languages.
+func translatorSupports(ctx *gin.Context, translator contracts.Translator, sourceLanguage, targetLanguage string) bool {
+	lister, supported := translator.(contracts.LanguageLister)
+	if !supported {
+		return true
 	}
+
+	languages, err := lister.SupportedLanguages()
+	if errors.Is(err, contracts.ErrLanguagesUnknown) {
+		return true
+	} else if err != nil {
+		errorResult(ctx, http.StatusInternalServerError, fmt.Sprintf("there was a problem with the translation service: %v", err.Error()))
+		return false
+	}
+
+	for _, language := range languages {
+		if language.Code == sourceLanguage && slices.Contains(language.Targets, func(target string) bool { return target == targetLanguage }) {
+			return true
+		}
+	}
+
+	badRequest(ctx, fmt.Sprintf("the translator doesn't translate from '%v' to '%v'", sourceLanguage, targetLanguage))
+	return false
+}
+
+// startJob queues the message with a new job and responds with the job.
+func startJob(ctx *gin.Context, repo contracts.ResoureRepository, queueClient contracts.QueueService, project, sourceLanguage, targetLanguage string, message contracts.BaseMessage, setJobID func(jobID int64)) {
+	job, err := queueJob(repo, queueClient, middleware.Username(ctx), project, sourceLanguage, targetLanguage, message, setJobID)
+	if err != nil {
+		errorResult(ctx, http.StatusInternalServerError, "something went wrong while starting the translation")
+		return
+	}
+
+	acceptedJob(ctx, job)
+}
+
+// queueJob creates a queued job for the message, sets its id with setJobID and publishes the message.
+// The job is marked as failed when the message can't be published.
+func queueJob(repo contracts.ResoureRepository, queueClient contracts.QueueService, username, project, sourceLanguage, targetLanguage string, message contracts.BaseMessage, setJobID func(jobID int64)) (models.Job, error) {
+	job, err := repo.CreateJob(models.Job{
+		Type:           message.GetType(),
+		Project:        project,
+		SourceLanguage: sourceLanguage,
+		TargetLanguage: targetLanguage,
+		Username:       username,
+		Status:         models.JobQueued,
+	})
+	if err != nil {
+		return job, err
+	}
+
+	setJobID(job.ID)
+	if err := queueClient.Publish(message); err != nil {
+		job.Status, job.Error = models.JobFailed, err.Error()
+		repo.UpdateJob(job)
+		return job, err
+	}
+
+	return job, nil
 }
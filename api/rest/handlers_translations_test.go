@@ -0,0 +1,60 @@
+package rest
+
+import (
+	"gotranslate/core/contracts"
+	"gotranslate/core/translators"
+	"gotranslate/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// listingTranslator only translates from English to German
+type listingTranslator struct {
+	translators.Fake
+}
+
+func (t *listingTranslator) SupportedLanguages() ([]models.SupportedLanguage, error) {
+	return []models.SupportedLanguage{{Code: "en", Name: "English", Targets: []string{"de"}}}, nil
+}
+
+// queueStub records the published messages
+type queueStub struct {
+	contracts.QueueService
+	published []contracts.BaseMessage
+}
+
+func (q *queueStub) Publish(message contracts.BaseMessage) error {
+	q.published = append(q.published, message)
+	return nil
+}
+
+func TestTranslationRoutes_WhenTranslatorDoesntSupportTarget_ShouldRejectBeforePublishing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &resourceRepositoryStub{
+		project:   models.Project{Name: models.DefaultProject},
+		resources: []models.Resource{{Project: models.DefaultProject, Key: "hello", LanguageCode: "en", Text: "Hello"}},
+	}
+	queue := &queueStub{}
+	router := gin.New()
+	router.POST("/translations/:sourceLanguageCode/to/:targetLanguageCode/missing", TranslateMissingKeys(repo, &listingTranslator{}, queue))
+	router.POST("/translations/:sourceLanguageCode/to/:targetLanguageCode/stale", RetranslateStale(repo, &listingTranslator{}, queue))
+
+	for _, route := range []string{"/translations/en/to/fr/missing", "/translations/en/to/fr/stale"} {
+		t.Run(route, func(t *testing.T) {
+			// arrange
+			recorder := httptest.NewRecorder()
+
+			// act
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, route, nil))
+
+			// assert
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), "doesn't translate from 'en' to 'fr'")
+			assert.Empty(t, queue.published)
+		})
+	}
+}
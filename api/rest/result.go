@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -21,3 +22,8 @@ func errorResult(ctx *gin.Context, statusCode int, errorMessages ...string) {
 		"errors": errorMessages,
 	})
 }
+
+func fileResult(ctx *gin.Context, fileName, contentType string, data []byte) {
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	ctx.Data(http.StatusOK, contentType, data)
+}
@@ -3,12 +3,13 @@ package rest
 import (
 	"gotranslate/api/middleware"
 	"gotranslate/core/contracts"
+	"gotranslate/core/formats"
 	"gotranslate/models"
 
 	"github.com/gin-gonic/gin"
 )
 
-func NewRouter(repo contracts.ResoureRepository, translator contracts.Translator, queueClient contracts.QueueService, auth models.AuthConfig) *gin.Engine {
+func NewRouter(repo contracts.ResoureRepository, translator contracts.Translator, queueClient contracts.QueueService, formatters *formats.Registry, auth models.AuthConfig) *gin.Engine {
 	r := gin.Default()
 
 	r.POST("/login", Authenticate(auth))
@@ -23,15 +24,42 @@ func NewRouter(repo contracts.ResoureRepository, translator contracts.Translator
 	protectedRouter := r.Group("/")
 	protectedRouter.Use(authMiddleware)
 	{
-		protectedRouter.GET("/resources", GetResources(repo))
-		protectedRouter.POST("/resources", AddResources(repo))
-		protectedRouter.PUT("/resources", UpdateResources(repo))
-		protectedRouter.DELETE("/resources", DeleteResources(repo))
-		protectedRouter.GET("/resources/languages", GetAvailableLanguages(repo))
+		protectedRouter.GET("/projects", GetProjects(repo))
+		protectedRouter.POST("/projects", AddProject(repo))
+		protectedRouter.GET("/projects/:project", GetProject(repo))
+		protectedRouter.PUT("/projects/:project", UpdateProject(repo))
 
 		protectedRouter.GET("/translations", TranslateResource(translator))
-		protectedRouter.POST("/translations/:sourceLanguageCode/to/:targetLanguageCode", TranslateAllToNewLanguage(repo, translator, queueClient))
+		protectedRouter.GET("/translations/languages", GetSupportedLanguages(translator))
+		protectedRouter.GET("/translations/memory", GetMemorySuggestions(repo))
+		protectedRouter.GET("/jobs/:id", GetJob(repo))
+
+		protectedRouter.GET("/admin/deadletters", GetDeadLetters(queueClient))
+		protectedRouter.POST("/admin/deadletters/replay", ReplayDeadLetters(queueClient))
+
+		// routes without a project use the default project
+		addProjectRoutes(protectedRouter, repo, translator, queueClient, formatters)
+		addProjectRoutes(protectedRouter.Group("/projects/:project"), repo, translator, queueClient, formatters)
 	}
 
 	return r
 }
+
+func addProjectRoutes(router *gin.RouterGroup, repo contracts.ResoureRepository, translator contracts.Translator, queueClient contracts.QueueService, formatters *formats.Registry) {
+	router.GET("/resources", GetResources(repo))
+	router.POST("/resources", AddResources(repo))
+	router.PUT("/resources", UpdateResources(repo, queueClient))
+	router.PATCH("/resources", UpsertResources(repo, queueClient))
+	router.PUT("/resources/status", UpdateResourcesStatus(repo))
+	router.DELETE("/resources", DeleteResources(repo))
+	router.GET("/resources/languages", GetAvailableLanguages(repo))
+	router.GET("/resources/stale", GetStaleTranslations(repo))
+	router.GET("/resources/history", GetResourceHistory(repo))
+	router.POST("/resources/history/:revision/rollback", RollbackResource(repo))
+	router.GET("/resources/export", ExportResources(repo, formatters))
+	router.POST("/resources/import", ImportResources(repo, formatters, queueClient))
+
+	router.POST("/translations/:sourceLanguageCode/to/:targetLanguageCode", TranslateAllToNewLanguage(repo, translator, queueClient))
+	router.POST("/translations/:sourceLanguageCode/to/:targetLanguageCode/missing", TranslateMissingKeys(repo, translator, queueClient))
+	router.POST("/translations/:sourceLanguageCode/to/:targetLanguageCode/stale", RetranslateStale(repo, translator, queueClient))
+}
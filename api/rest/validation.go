@@ -63,11 +63,63 @@ func validateResourceData(data []models.Resource) *ValidationErrors {
 		if item.Text == "" {
 			validationErrors.Add(errors.New("invalid text"))
 		}
+
+		if item.Status != "" && !models.IsValidStatus(item.Status) {
+			validationErrors.Add(errors.New("invalid status"))
+		}
 	}
 
 	return &validationErrors
 }
 
+// validateNewStatus rejects reviewed statuses in the resources that are added or upserted, the review workflow is only
+// followed by PUT /resources/status.
+func validateNewStatus(data []models.Resource) *ValidationErrors {
+	var validationErrors ValidationErrors
+
+	for _, item := range data {
+		if item.Status != "" && item.Status != models.StatusUntranslated {
+			validationErrors.Add(fmt.Errorf("status of '%v' (%v) can only be changed with PUT /resources/status", item.Key, item.LanguageCode))
+		}
+	}
+
+	return &validationErrors
+}
+
+func validateProjectData(project models.Project) *ValidationErrors {
+	var validationErrors ValidationErrors
+
+	if !projectNameIsValid(project.Name) {
+		validationErrors.Add(errors.New("project name must be 1 to 64 lowercase letters, digits, '-' or '_'"))
+	}
+
+	if project.SourceLanguage != "" && !languageCodeIsValid(project.SourceLanguage) {
+		validationErrors.Add(errors.New("source language code must be 2 letters"))
+	}
+
+	for _, languageCode := range project.TargetLanguages {
+		if !languageCodeIsValid(languageCode) {
+			validationErrors.Add(errors.New("target language codes must be 2 letters"))
+			break
+		}
+	}
+
+	return &validationErrors
+}
+
+func projectNameIsValid(name string) bool {
+	if len(name) == 0 || len(name) > 64 {
+		return false
+	}
+
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
 func languageCodeIsValid(languageCode string) bool {
 	return len(languageCode) == 2
 }
@@ -0,0 +1,10 @@
+package contracts
+
+import "gotranslate/models"
+
+// DeadLetterQueue is implemented by queues that keep the messages that failed, so they can be inspected and replayed.
+type DeadLetterQueue interface {
+	DeadLetters(limit int) ([]models.DeadLetter, error)
+	// ReplayDeadLetters publishes the dead letters again with their retries reset, all of them when no ids are given.
+	ReplayDeadLetters(ids ...string) (replayed int, err error)
+}
@@ -0,0 +1,8 @@
+package contracts
+
+import "gotranslate/models"
+
+type ResourceFormatter interface {
+	Encode(resources []models.Resource, options models.FormatOptions) (models.EncodedFile, error)
+	Decode(data []byte, options models.FormatOptions) ([]models.DecodedResource, error)
+}
@@ -1,13 +1,57 @@
 package contracts
 
-import "gotranslate/models"
+import (
+	"errors"
+	"gotranslate/models"
+)
 
+// ErrResourceConflict is returned when a resource already exists for its project, key and language.
+var ErrResourceConflict = errors.New("resource already exists")
+
+// ErrRevisionNotFound is returned when rolling back a revision that doesn't exist in the project.
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// ErrNothingToRollBack is returned when rolling back a revision that restored a deleted resource.
+var ErrNothingToRollBack = errors.New("the revision can't be rolled back")
+
+// ResoureRepository stores resources by project, resources being added or updated are scoped by their Project field.
+// Changes to existing texts are recorded as revisions with the username of whoever made them.
 type ResoureRepository interface {
 	Init() error
-	GetResourcesByLanguageCode(languageCode string) ([]models.Resource, error)
-	GetResourcesByKey(key string) ([]models.Resource, error)
+	GetResourcesByLanguageCode(project, languageCode string) ([]models.Resource, error)
+	GetResourcesByKey(project, key string) ([]models.Resource, error)
+	// GetResourcesPage returns up to limit resources of the language with a key after afterKey, ordered by key in byte order.
+	GetResourcesPage(project, languageCode, afterKey string, limit int) ([]models.Resource, error)
 	AddResources(resources ...models.Resource) error
-	UpdateResourceValues(resources ...models.Resource) (rowsAffected int64, err error)
-	RemoveResources(key, languageCode string) (rowsAffected int64, err error)
-	ExistingLanguageCodes() ([]models.LanguageResult, error)
+	// UpdateResourceValues sets the Text of existing resources as a manual edit: the status is set to models.StatusEdited,
+	// the provider is cleared and the source hash is taken from the current text of the source language.
+	UpdateResourceValues(username string, resources ...models.Resource) (rowsAffected int64, err error)
+	// UpdateResourceStatus sets the Status of existing resources, transitions are validated by the caller.
+	UpdateResourceStatus(resources ...models.Resource) (rowsAffected int64, err error)
+	// UpsertResources adds the new resources and resolves the existing ones with the policy, ConflictFail saves nothing when any resource exists.
+	// The description and status of existing resources are only changed when they are set.
+	UpsertResources(policy models.ConflictPolicy, username string, resources ...models.Resource) (models.UpsertResult, error)
+	RemoveResources(project, key, languageCode, username string) (rowsAffected int64, err error)
+	// GetRevisions returns the newest revisions first, languageCode is optional.
+	GetRevisions(project, key, languageCode string) ([]models.Revision, error)
+	// RollbackRevision sets the resource back to the text before the revision, re-creating it when the revision deleted it.
+	RollbackRevision(project string, revisionID int64, username string) (models.Revision, error)
+	ExistingLanguageCodes(project string) ([]models.LanguageResult, error)
+	GetProjects() ([]models.Project, error)
+	GetProject(name string) (project models.Project, found bool, err error)
+	SaveProject(project models.Project) error
+	// CreateJob saves a new job and returns it with its ID.
+	CreateJob(job models.Job) (models.Job, error)
+	UpdateJob(job models.Job) error
+	GetJob(id int64) (job models.Job, found bool, err error)
+}
+
+// TranslationMemory finds the approved translations of source texts in the whole catalog, for any project and key.
+// Approved translations whose source text changed since they were translated aren't used.
+type TranslationMemory interface {
+	// FindExactMatches returns the approved translations of the texts by source text.
+	FindExactMatches(sourceLanguage, targetLanguage string, texts []string) (map[string]string, error)
+	// FindFuzzyMatches returns up to limit approved translations of source texts at least minSimilarity similar to the
+	// text, the most similar first.
+	FindFuzzyMatches(sourceLanguage, targetLanguage, text string, minSimilarity float64, limit int) ([]models.MemoryMatch, error)
 }
@@ -1,9 +1,31 @@
 package contracts
 
-import "gotranslate/models"
+import (
+	"errors"
+	"gotranslate/models"
+)
+
+// ErrLanguagesUnknown is returned by translators that can't tell which languages they support.
+var ErrLanguagesUnknown = errors.New("the translator doesn't list its languages")
 
 type Translator interface {
 	Translate(tq models.TranslationQuery) ([]string, error)
 	TranslateResources(targetLanguageCode string, resources []models.Resource) ([]models.Resource, error)
 	GetBatchLimit() int
 }
+
+// ConcurrentTranslator is implemented by translators that can translate many batches at the same time.
+type ConcurrentTranslator interface {
+	Concurrency() int
+}
+
+// RequestCounter is implemented by translators that don't send one request to the provider per call, it returns how
+// many requests translating the texts takes.
+type RequestCounter interface {
+	Requests(texts int) int
+}
+
+// LanguageLister is implemented by translators that can tell which languages they translate between.
+type LanguageLister interface {
+	SupportedLanguages() ([]models.SupportedLanguage, error)
+}
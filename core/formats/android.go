@@ -0,0 +1,332 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var invalidAndroidNameCharacters = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+type androidElement struct {
+	Key   string
+	Name  string
+	Kind  string // string, plurals or string-array
+	Text  string
+	Items []androidItem
+}
+
+type androidItem struct {
+	Quantity string
+	Index    int
+	Text     string
+}
+
+// EncodeAndroidStrings renders resources as a res/values/strings.xml file. Plural forms and array items (see PluralKey)
+// are grouped into <plurals> and <string-array> elements.
+// Android names can only contain letters, digits and underscores, so keys are normalized and every renamed key is returned
+// in renamedKeys (original key to name) and written as a comment before its element, which DecodeAndroidStrings reads back.
+func EncodeAndroidStrings(resources []models.Resource) (data []byte, renamedKeys map[string]string) {
+	elements := groupAndroidElements(resources)
+	renamedKeys = map[string]string{}
+	usedNames := map[string]bool{}
+	for _, element := range elements {
+		if androidName(element.Key) == element.Key {
+			usedNames[element.Key] = true // valid keys keep their name, renamed keys get a suffix on collision
+		}
+	}
+	for i := range elements {
+		if androidName(elements[i].Key) == elements[i].Key {
+			elements[i].Name = elements[i].Key
+			continue
+		}
+
+		normalized := androidName(elements[i].Key)
+		name := normalized
+		for suffix := 2; usedNames[name]; suffix++ {
+			name = fmt.Sprintf("%s_%d", normalized, suffix)
+		}
+		usedNames[name] = true
+		elements[i].Name = name
+		renamedKeys[elements[i].Key] = name
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString("<resources>\n")
+	for _, element := range elements {
+		if element.Name != element.Key {
+			fmt.Fprintf(&buf, "    <!-- key: %s -->\n", escapeAndroidKey(element.Key))
+		}
+
+		switch element.Kind {
+		case "string":
+			fmt.Fprintf(&buf, "    <string name=\"%s\">%s</string>\n", element.Name, escapeAndroid(element.Text))
+		case "plurals":
+			fmt.Fprintf(&buf, "    <plurals name=\"%s\">\n", element.Name)
+			for _, item := range element.Items {
+				fmt.Fprintf(&buf, "        <item quantity=\"%s\">%s</item>\n", item.Quantity, escapeAndroid(item.Text))
+			}
+			buf.WriteString("    </plurals>\n")
+		case "string-array":
+			fmt.Fprintf(&buf, "    <string-array name=\"%s\">\n", element.Name)
+			for _, item := range element.Items {
+				fmt.Fprintf(&buf, "        <item>%s</item>\n", escapeAndroid(item.Text))
+			}
+			buf.WriteString("    </string-array>\n")
+		}
+	}
+	buf.WriteString("</resources>\n")
+
+	return buf.Bytes(), renamedKeys
+}
+
+func groupAndroidElements(resources []models.Resource) []androidElement {
+	var elements []androidElement
+	groups := map[string]int{}
+
+	addItem := func(key, kind string, item androidItem) {
+		i, exists := groups[kind+":"+key]
+		if !exists {
+			elements = append(elements, androidElement{Key: key, Kind: kind})
+			i = len(elements) - 1
+			groups[kind+":"+key] = i
+		}
+		elements[i].Items = append(elements[i].Items, item)
+	}
+
+	for _, resource := range resources {
+		if base, quantity, isPlural := splitPluralKey(resource.Key); isPlural {
+			addItem(base, "plurals", androidItem{Quantity: quantity, Text: resource.Text})
+		} else if base, index, isArrayItem := splitArrayKey(resource.Key); isArrayItem {
+			position, _ := strconv.Atoi(index)
+			addItem(base, "string-array", androidItem{Index: position, Text: resource.Text})
+		} else {
+			elements = append(elements, androidElement{Key: resource.Key, Kind: "string", Text: resource.Text})
+		}
+	}
+
+	for _, element := range elements {
+		sort.SliceStable(element.Items, func(i, j int) bool {
+			if element.Kind == "plurals" {
+				return pluralOrder(element.Items[i].Quantity) < pluralOrder(element.Items[j].Quantity)
+			}
+			return element.Items[i].Index < element.Items[j].Index
+		})
+	}
+	sort.SliceStable(elements, func(i, j int) bool { return elements[i].Key < elements[j].Key })
+
+	return elements
+}
+
+func pluralOrder(quantity string) int {
+	for i, q := range pluralQuantities {
+		if q == quantity {
+			return i
+		}
+	}
+	return len(pluralQuantities)
+}
+
+func androidName(key string) string {
+	name := invalidAndroidNameCharacters.ReplaceAllString(key, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+var androidEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`'`, `\'`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\t", `\t`,
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+)
+
+func escapeAndroid(text string) string {
+	escaped := androidEscaper.Replace(text)
+	if strings.HasPrefix(escaped, "@") || strings.HasPrefix(escaped, "?") {
+		escaped = `\` + escaped
+	}
+	return escaped
+}
+
+// escapeAndroidKey URL-escapes the key for the "key:" comment, '-' is escaped too because comments can't contain "--".
+func escapeAndroidKey(key string) string {
+	return strings.ReplaceAll(url.PathEscape(key), "-", "%2D")
+}
+
+// unescapeAndroidKey restores the key of a "key:" comment, keys that aren't escaped are returned as they are.
+func unescapeAndroidKey(key string) string {
+	if unescaped, err := url.PathUnescape(key); err == nil {
+		return unescaped
+	}
+	return key
+}
+
+// DecodeAndroidStrings reads the strings, plurals and string arrays of a strings.xml file.
+// Keys that were renamed on export are restored from the "key:" comment before the element.
+// Strings marked translatable="false" are returned with a SkipReason.
+func DecodeAndroidStrings(data []byte) ([]Entry, error) {
+	var entries []Entry
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	originalKey := ""
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+
+		switch token := token.(type) {
+		case xml.Comment:
+			if key, found := strings.CutPrefix(strings.TrimSpace(string(token)), "key:"); found {
+				originalKey = unescapeAndroidKey(strings.TrimSpace(key))
+			}
+		case xml.StartElement:
+			if token.Name.Local == "resources" {
+				continue
+			}
+
+			key := xmlAttribute(token, "name")
+			if originalKey != "" {
+				key, originalKey = originalKey, ""
+			}
+
+			switch token.Name.Local {
+			case "string":
+				var text xmlText
+				if err := text.UnmarshalXML(decoder, token); err != nil {
+					return nil, err
+				}
+				entry := Entry{Key: key, Text: unescapeAndroid(string(text))}
+				if xmlAttribute(token, "translatable") == "false" {
+					entry.SkipReason = "not translatable"
+				}
+				entries = append(entries, entry)
+			case "plurals", "string-array":
+				items, err := decodeAndroidItems(decoder, token, key)
+				if err != nil {
+					return nil, err
+				}
+				entries = append(entries, items...)
+			default:
+				if err := decoder.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+func decodeAndroidItems(decoder *xml.Decoder, parent xml.StartElement, key string) ([]Entry, error) {
+	var entries []Entry
+	for index := 0; ; {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch token := token.(type) {
+		case xml.EndElement:
+			return entries, nil
+		case xml.StartElement:
+			var text xmlText
+			if err := text.UnmarshalXML(decoder, token); err != nil {
+				return nil, err
+			}
+
+			if parent.Name.Local == "plurals" {
+				entries = append(entries, Entry{Key: PluralKey(key, xmlAttribute(token, "quantity")), Text: unescapeAndroid(string(text))})
+			} else {
+				entries = append(entries, Entry{Key: PluralKey(key, strconv.Itoa(index)), Text: unescapeAndroid(string(text))})
+				index++
+			}
+		}
+	}
+}
+
+func xmlAttribute(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func unescapeAndroid(text string) string {
+	text = strings.TrimSpace(text)
+	if len(text) >= 2 && strings.HasPrefix(text, `"`) && strings.HasSuffix(text, `"`) && !strings.HasSuffix(text, `\"`) {
+		text = text[1 : len(text)-1] // quoted strings keep their whitespace as is
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i+1 == len(text) {
+			sb.WriteByte(text[i])
+			continue
+		}
+
+		i++
+		switch text[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'u':
+			if i+4 < len(text) {
+				if r, err := strconv.ParseUint(text[i+1:i+5], 16, 32); err == nil {
+					sb.WriteRune(rune(r))
+					i += 4
+					continue
+				}
+			}
+			sb.WriteString(`\u`)
+		default:
+			sb.WriteByte(text[i])
+		}
+	}
+
+	return sb.String()
+}
+
+type AndroidFormatter struct{}
+
+var _ contracts.ResourceFormatter = (*AndroidFormatter)(nil)
+
+func (f *AndroidFormatter) Encode(resources []models.Resource, options models.FormatOptions) (models.EncodedFile, error) {
+	data, renamedKeys := EncodeAndroidStrings(resourcesOf(resources, options.MainLanguage()))
+	return models.EncodedFile{
+		FileName:    "strings.xml",
+		ContentType: "application/xml; charset=utf-8",
+		Data:        data,
+		RenamedKeys: renamedKeys,
+	}, nil
+}
+
+func (f *AndroidFormatter) Decode(data []byte, options models.FormatOptions) ([]models.DecodedResource, error) {
+	entries, err := DecodeAndroidStrings(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid strings.xml file: %v", err.Error())
+	}
+	return toDecodedResources(entries, options.MainLanguage()), nil
+}
@@ -0,0 +1,94 @@
+package formats
+
+import (
+	"gotranslate/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeAndroidStrings_ShouldGroupPluralsAndArraysAndRenameInvalidKeys(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "home.title", Text: "Home"},
+		{Key: "home_title", Text: "Other home"},
+		{Key: "cart.items#other", Text: "%d items"},
+		{Key: "cart.items#one", Text: "%d item"},
+		{Key: "weekdays#1", Text: "Tuesday"},
+		{Key: "weekdays#0", Text: "Monday"},
+		{Key: "quote", Text: `it's "quoted" & <b>`},
+	}
+
+	data, renamedKeys := EncodeAndroidStrings(resources)
+	result := string(data)
+
+	assert.Equal(t, map[string]string{"cart.items": "cart_items", "home.title": "home_title_2"}, renamedKeys)
+	assert.Contains(t, result, "<!-- key: home.title -->\n    <string name=\"home_title_2\">Home</string>")
+	assert.Contains(t, result, "<plurals name=\"cart_items\">\n        <item quantity=\"one\">%d item</item>\n        <item quantity=\"other\">%d items</item>\n    </plurals>")
+	assert.Contains(t, result, "<string-array name=\"weekdays\">\n        <item>Monday</item>\n        <item>Tuesday</item>\n    </string-array>")
+	assert.Contains(t, result, `<string name="quote">it\'s \"quoted\" &amp; &lt;b&gt;</string>`)
+}
+
+func TestDecodeAndroidStrings_WhenEncodedByEncodeAndroidStrings_ShouldRestoreKeysAndTexts(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "cart.items#one", Text: "%d item"},
+		{Key: "cart.items#other", Text: "%d items"},
+		{Key: "home.title", Text: "@home\nline 2"},
+		{Key: "quote", Text: `it's "quoted" & <b>`},
+		{Key: "weekdays#0", Text: "Monday"},
+		{Key: "weekdays#1", Text: "Tuesday"},
+	}
+	data, _ := EncodeAndroidStrings(resources)
+
+	entries, err := DecodeAndroidStrings(data)
+
+	assert.NoError(t, err)
+	assert.Len(t, entries, len(resources))
+	for i, resource := range resources {
+		assert.Equal(t, resource.Key, entries[i].Key)
+		assert.Equal(t, resource.Text, entries[i].Text)
+	}
+}
+
+func TestDecodeAndroidStrings_WhenKeysHaveCommentCharacters_ShouldRestoreThem(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "a--b", Text: "dashes"},
+		{Key: "a- -b", Text: "spaced dashes"},
+		{Key: "ends-", Text: "trailing dash"},
+		{Key: "100% +sure ", Text: "escapes"},
+	}
+	data, _ := EncodeAndroidStrings(resources)
+
+	entries, err := DecodeAndroidStrings(data)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "a--b")
+	keys := []string{}
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+	assert.ElementsMatch(t, []string{"a--b", "a- -b", "ends-", "100% +sure "}, keys)
+}
+
+func TestDecodeAndroidStrings_WhenKeyCommentHasPlus_ShouldKeepIt(t *testing.T) {
+	data := []byte("<resources>\n    <!-- key: a+b -->\n    <string name=\"a_b\">plus</string>\n</resources>")
+
+	entries, err := DecodeAndroidStrings(data)
+
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "a+b", entries[0].Key)
+}
+
+func TestDecodeAndroidStrings_WhenNotTranslatable_ShouldSetSkipReason(t *testing.T) {
+	data := `<resources>
+    <string name="app_name" translatable="false">GoTranslate</string>
+    <string name="title">"  spaced  "</string>
+</resources>`
+
+	entries, err := DecodeAndroidStrings([]byte(data))
+
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.NotEmpty(t, entries[0].SkipReason)
+	assert.Equal(t, "  spaced  ", entries[1].Text)
+}
@@ -0,0 +1,382 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	xunicode "golang.org/x/text/encoding/unicode"
+)
+
+// EncodeAppleStrings renders resources as a Localizable.strings file. Plural forms belong in the .stringsdict file
+// and are left out, see EncodeAppleStringsDict.
+func EncodeAppleStrings(resources []models.Resource) []byte {
+	var buf bytes.Buffer
+	for _, resource := range sortedByKey(resources) {
+		if _, _, isPlural := splitPluralKey(resource.Key); isPlural {
+			continue
+		}
+		fmt.Fprintf(&buf, "\"%s\" = \"%s\";\n", escapeAppleString(resource.Key), escapeAppleString(resource.Text))
+	}
+	return buf.Bytes()
+}
+
+var appleStringEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+)
+
+func escapeAppleString(text string) string {
+	return appleStringEscaper.Replace(text)
+}
+
+// DecodeAppleStrings parses a .strings file in UTF-8 or UTF-16 (with BOM), keys can be quoted or bare words.
+func DecodeAppleStrings(data []byte) ([]Entry, error) {
+	if bytes.HasPrefix(data, []byte{0xFF, 0xFE}) || bytes.HasPrefix(data, []byte{0xFE, 0xFF}) {
+		decoded, err := xunicode.UTF16(xunicode.BigEndian, xunicode.UseBOM).NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, err
+		}
+		data = decoded
+	}
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+
+	scanner := appleStringsScanner{input: []rune(string(data)), line: 1}
+	var entries []Entry
+	for {
+		key, err := scanner.next()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		if err := scanner.expect('='); err != nil {
+			return nil, err
+		}
+
+		text, err := scanner.next()
+		if err != nil {
+			return nil, scanner.unexpectedEnd(err)
+		}
+
+		if err := scanner.expect(';'); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, Entry{Key: key, Text: text})
+	}
+}
+
+type appleStringsScanner struct {
+	input    []rune
+	position int
+	line     int
+}
+
+// next returns the next quoted string or bare word, skipping whitespace and comments.
+func (s *appleStringsScanner) next() (string, error) {
+	if err := s.skipWhitespaceAndComments(); err != nil {
+		return "", err
+	}
+
+	if s.input[s.position] != '"' {
+		start := s.position
+		for s.position < len(s.input) && (unicode.IsLetter(s.input[s.position]) || unicode.IsDigit(s.input[s.position]) || strings.ContainsRune("_.-$:/", s.input[s.position])) {
+			s.position++
+		}
+		if start == s.position {
+			return "", fmt.Errorf("line %d: unexpected character '%c'", s.line, s.input[s.position])
+		}
+		return string(s.input[start:s.position]), nil
+	}
+
+	var sb strings.Builder
+	for s.position++; s.position < len(s.input); s.position++ {
+		c := s.input[s.position]
+		switch c {
+		case '"':
+			s.position++
+			return sb.String(), nil
+		case '\n':
+			s.line++
+			sb.WriteRune(c)
+		case '\\':
+			s.position++
+			if s.position == len(s.input) {
+				break
+			}
+			switch escaped := s.input[s.position]; escaped {
+			case 'n':
+				sb.WriteRune('\n')
+			case 'r':
+				sb.WriteRune('\r')
+			case 't':
+				sb.WriteRune('\t')
+			case 'U', 'u':
+				if s.position+4 < len(s.input) {
+					var r rune
+					if _, err := fmt.Sscanf(string(s.input[s.position+1:s.position+5]), "%04x", &r); err == nil {
+						sb.WriteRune(r)
+						s.position += 4
+						continue
+					}
+				}
+				sb.WriteRune(escaped)
+			default:
+				sb.WriteRune(escaped)
+			}
+		default:
+			sb.WriteRune(c)
+		}
+	}
+
+	return "", fmt.Errorf("line %d: unterminated string", s.line)
+}
+
+func (s *appleStringsScanner) expect(expected rune) error {
+	if err := s.skipWhitespaceAndComments(); err != nil {
+		return s.unexpectedEnd(err)
+	}
+	if s.input[s.position] != expected {
+		return fmt.Errorf("line %d: expected '%c' but found '%c'", s.line, expected, s.input[s.position])
+	}
+	s.position++
+	return nil
+}
+
+func (s *appleStringsScanner) unexpectedEnd(err error) error {
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("line %d: unexpected end of file", s.line)
+	}
+	return err
+}
+
+func (s *appleStringsScanner) skipWhitespaceAndComments() error {
+	for s.position < len(s.input) {
+		c := s.input[s.position]
+		rest := string(s.input[s.position:min(s.position+2, len(s.input))])
+		switch {
+		case c == '\n':
+			s.line++
+			s.position++
+		case unicode.IsSpace(c):
+			s.position++
+		case rest == "//":
+			for s.position < len(s.input) && s.input[s.position] != '\n' {
+				s.position++
+			}
+		case rest == "/*":
+			for s.position += 2; string(s.input[s.position:min(s.position+2, len(s.input))]) != "*/"; s.position++ {
+				if s.position >= len(s.input) {
+					return fmt.Errorf("line %d: unterminated comment", s.line)
+				}
+				if s.input[s.position] == '\n' {
+					s.line++
+				}
+			}
+			s.position += 2
+		default:
+			return nil
+		}
+	}
+	return io.EOF
+}
+
+// EncodeAppleStringsDict renders the plural forms (see PluralKey) of the resources as a .stringsdict property list,
+// with a single %d variable per key.
+func EncodeAppleStringsDict(resources []models.Resource) []byte {
+	plurals := map[string]map[string]string{}
+	var keys []string
+	for _, resource := range resources {
+		base, quantity, isPlural := splitPluralKey(resource.Key)
+		if !isPlural {
+			continue
+		}
+		if _, exists := plurals[base]; !exists {
+			plurals[base] = map[string]string{}
+			keys = append(keys, base)
+		}
+		plurals[base][quantity] = resource.Text
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	buf.WriteString("<plist version=\"1.0\">\n<dict>\n")
+	for _, key := range keys {
+		fmt.Fprintf(&buf, "    <key>%s</key>\n    <dict>\n", escapeXML(key))
+		buf.WriteString("        <key>NSStringLocalizedFormatKey</key>\n        <string>%#@value@</string>\n")
+		buf.WriteString("        <key>value</key>\n        <dict>\n")
+		buf.WriteString("            <key>NSStringFormatSpecTypeKey</key>\n            <string>NSStringPluralRuleType</string>\n")
+		buf.WriteString("            <key>NSStringFormatValueTypeKey</key>\n            <string>d</string>\n")
+		for _, quantity := range pluralQuantities {
+			if text, exists := plurals[key][quantity]; exists {
+				fmt.Fprintf(&buf, "            <key>%s</key>\n            <string>%s</string>\n", quantity, escapeXML(text))
+			}
+		}
+		buf.WriteString("        </dict>\n    </dict>\n")
+	}
+	buf.WriteString("</dict>\n</plist>\n")
+
+	return buf.Bytes()
+}
+
+func escapeXML(text string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
+
+// DecodeAppleStringsDict reads the plural forms of a .stringsdict file. When a key has more than one plural variable
+// the variable name is added to the key, e.g. "files#count#one".
+func DecodeAppleStringsDict(data []byte) ([]Entry, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	root, err := decodePlistRoot(decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, item := range root.Dict {
+		var variables []plistEntry
+		for _, value := range item.Value.Dict {
+			if value.Key != "NSStringLocalizedFormatKey" && value.Value.Dict != nil {
+				variables = append(variables, value)
+			}
+		}
+
+		for _, variable := range variables {
+			key := item.Key
+			if len(variables) > 1 {
+				key = PluralKey(key, variable.Key)
+			}
+			for _, rule := range variable.Value.Dict {
+				if strings.HasPrefix(rule.Key, "NSStringFormat") {
+					continue
+				}
+				entries = append(entries, Entry{Key: PluralKey(key, rule.Key), Text: rule.Value.String})
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// plistValue is the subset of property list values used by .stringsdict files: strings and dictionaries.
+type plistValue struct {
+	String string
+	Dict   []plistEntry
+}
+
+type plistEntry struct {
+	Key   string
+	Value plistValue
+}
+
+func decodePlistRoot(decoder *xml.Decoder) (plistValue, error) {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return plistValue{}, fmt.Errorf("no dictionary found in property list: %v", err)
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "dict" {
+			return decodePlistDict(decoder)
+		}
+	}
+}
+
+func decodePlistDict(decoder *xml.Decoder) (plistValue, error) {
+	result := plistValue{Dict: []plistEntry{}}
+	key := ""
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return result, err
+		}
+
+		switch token := token.(type) {
+		case xml.EndElement:
+			return result, nil
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "key":
+				var text xmlText
+				if err := text.UnmarshalXML(decoder, token); err != nil {
+					return result, err
+				}
+				key = string(text)
+			case "dict":
+				value, err := decodePlistDict(decoder)
+				if err != nil {
+					return result, err
+				}
+				result.Dict = append(result.Dict, plistEntry{Key: key, Value: value})
+			default:
+				var text xmlText
+				if err := text.UnmarshalXML(decoder, token); err != nil {
+					return result, err
+				}
+				result.Dict = append(result.Dict, plistEntry{Key: key, Value: plistValue{String: string(text)}})
+			}
+		}
+	}
+}
+
+func sortedByKey(resources []models.Resource) []models.Resource {
+	sorted := make([]models.Resource, len(resources))
+	copy(sorted, resources)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	return sorted
+}
+
+type AppleStringsFormatter struct{}
+
+var _ contracts.ResourceFormatter = (*AppleStringsFormatter)(nil)
+
+func (f *AppleStringsFormatter) Encode(resources []models.Resource, options models.FormatOptions) (models.EncodedFile, error) {
+	return models.EncodedFile{
+		FileName:    "Localizable.strings",
+		ContentType: "text/plain; charset=utf-8",
+		Data:        EncodeAppleStrings(resourcesOf(resources, options.MainLanguage())),
+	}, nil
+}
+
+func (f *AppleStringsFormatter) Decode(data []byte, options models.FormatOptions) ([]models.DecodedResource, error) {
+	entries, err := DecodeAppleStrings(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid strings file: %v", err.Error())
+	}
+	return toDecodedResources(entries, options.MainLanguage()), nil
+}
+
+type AppleStringsDictFormatter struct{}
+
+var _ contracts.ResourceFormatter = (*AppleStringsDictFormatter)(nil)
+
+func (f *AppleStringsDictFormatter) Encode(resources []models.Resource, options models.FormatOptions) (models.EncodedFile, error) {
+	return models.EncodedFile{
+		FileName:    "Localizable.stringsdict",
+		ContentType: "application/xml; charset=utf-8",
+		Data:        EncodeAppleStringsDict(resourcesOf(resources, options.MainLanguage())),
+	}, nil
+}
+
+func (f *AppleStringsDictFormatter) Decode(data []byte, options models.FormatOptions) ([]models.DecodedResource, error) {
+	entries, err := DecodeAppleStringsDict(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stringsdict file: %v", err.Error())
+	}
+	return toDecodedResources(entries, options.MainLanguage()), nil
+}
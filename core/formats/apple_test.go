@@ -0,0 +1,60 @@
+package formats
+
+import (
+	"gotranslate/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestEncodeAppleStrings_ShouldRoundTripWithDecodeAppleStrings(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "home.title", Text: "Home"},
+		{Key: "quote", Text: "say \"hi\"\nback\\slash"},
+		{Key: "cart.items#one", Text: "left out, it's a plural"},
+	}
+
+	entries, err := DecodeAppleStrings(EncodeAppleStrings(resources))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{{Key: "home.title", Text: "Home"}, {Key: "quote", Text: "say \"hi\"\nback\\slash"}}, entries)
+}
+
+func TestDecodeAppleStrings_ShouldSkipCommentsAndSupportBareKeysAndUTF16(t *testing.T) {
+	data := `/* header comment
+   on two lines */
+"greeting" = "Hello"; // trailing comment
+bare_key = "Caf\U00e9";
+`
+	utf16, err := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().Bytes([]byte(data))
+	assert.NoError(t, err)
+
+	for _, input := range [][]byte{[]byte(data), utf16} {
+		entries, err := DecodeAppleStrings(input)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []Entry{{Key: "greeting", Text: "Hello"}, {Key: "bare_key", Text: "Café"}}, entries)
+	}
+}
+
+func TestDecodeAppleStrings_WhenSemicolonIsMissing_ShouldReturnErrorWithLine(t *testing.T) {
+	_, err := DecodeAppleStrings([]byte("\"a\" = \"b\";\n\"c\" = \"d\"\n"))
+
+	assert.ErrorContains(t, err, "line 3")
+}
+
+func TestEncodeAppleStringsDict_ShouldRoundTripPluralsWithDecodeAppleStringsDict(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "home.title", Text: "not a plural"},
+		{Key: "cart.items#other", Text: "%d items"},
+		{Key: "cart.items#one", Text: "%d item & more"},
+	}
+
+	data := EncodeAppleStringsDict(resources)
+	entries, err := DecodeAppleStringsDict(data)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "home.title")
+	assert.Equal(t, []Entry{{Key: "cart.items#one", Text: "%d item & more"}, {Key: "cart.items#other", Text: "%d items"}}, entries)
+}
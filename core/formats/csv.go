@@ -0,0 +1,104 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"sort"
+	"strings"
+)
+
+// EncodeCSV renders a row per key with the key in the first column and the text of each language in the next ones.
+func EncodeCSV(resources []models.Resource, languageCodes []string) ([]byte, error) {
+	texts := map[string]map[string]string{}
+	var keys []string
+	for _, resource := range resources {
+		if _, exists := texts[resource.Key]; !exists {
+			texts[resource.Key] = map[string]string{}
+			keys = append(keys, resource.Key)
+		}
+		texts[resource.Key][resource.LanguageCode] = resource.Text
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(append([]string{"key"}, languageCodes...)); err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		row := []string{key}
+		for _, languageCode := range languageCodes {
+			row = append(row, texts[key][languageCode])
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+
+	return buf.Bytes(), writer.Error()
+}
+
+// DecodeCSV reads a file with a header row of "key" followed by the language codes, and returns the entries per language.
+// Empty cells are returned as untranslated entries.
+func DecodeCSV(data []byte) (languageCodes []string, entries map[string][]Entry, err error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))))
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(rows) == 0 || len(rows[0]) < 2 || !strings.EqualFold(strings.TrimSpace(rows[0][0]), "key") {
+		return nil, nil, errors.New("the first row should be 'key' followed by a column per language code")
+	}
+
+	for _, column := range rows[0][1:] {
+		languageCodes = append(languageCodes, NormalizeLanguageCode(strings.TrimSpace(column)))
+	}
+
+	entries = map[string][]Entry{}
+	for _, row := range rows[1:] {
+		for i, languageCode := range languageCodes {
+			entries[languageCode] = append(entries[languageCode], Entry{Key: row[0], Text: row[i+1]})
+		}
+	}
+
+	return languageCodes, entries, nil
+}
+
+// CSVFormatter exports all the languages of the options as columns.
+type CSVFormatter struct{}
+
+var _ contracts.ResourceFormatter = (*CSVFormatter)(nil)
+
+func (f *CSVFormatter) Encode(resources []models.Resource, options models.FormatOptions) (models.EncodedFile, error) {
+	data, err := EncodeCSV(resources, options.LanguageCodes)
+	if err != nil {
+		return models.EncodedFile{}, err
+	}
+
+	return models.EncodedFile{FileName: "resources.csv", ContentType: "text/csv; charset=utf-8", Data: data}, nil
+}
+
+// Decode imports every language column of the file, unless languages are set in the options.
+func (f *CSVFormatter) Decode(data []byte, options models.FormatOptions) ([]models.DecodedResource, error) {
+	languageCodes, entries, err := DecodeCSV(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv file: %v", err.Error())
+	}
+
+	results := []models.DecodedResource{}
+	for _, languageCode := range languageCodes {
+		if len(options.LanguageCodes) > 0 && !containsString(options.LanguageCodes, languageCode) {
+			continue
+		}
+		results = append(results, toDecodedResources(entries[languageCode], languageCode)...)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,38 @@
+package formats
+
+import (
+	"gotranslate/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeCSV_ShouldWriteAColumnPerLanguage(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "title", LanguageCode: "en", Text: "Home"},
+		{Key: "title", LanguageCode: "de", Text: "Startseite"},
+		{Key: "quote", LanguageCode: "en", Text: `say "hi", bye`},
+	}
+
+	data, err := EncodeCSV(resources, []string{"en", "de"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "key,en,de\nquote,\"say \"\"hi\"\", bye\",\ntitle,Home,Startseite\n", string(data))
+}
+
+func TestDecodeCSV_ShouldReturnEntriesPerLanguage(t *testing.T) {
+	data := "key,en,de_DE\ntitle,Home,Startseite\nquote,\"say \"\"hi\"\"\",\n"
+
+	languageCodes, entries, err := DecodeCSV([]byte(data))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"en", "de"}, languageCodes)
+	assert.Equal(t, []Entry{{Key: "title", Text: "Home"}, {Key: "quote", Text: `say "hi"`}}, entries["en"])
+	assert.Equal(t, []Entry{{Key: "title", Text: "Startseite"}, {Key: "quote"}}, entries["de"])
+}
+
+func TestDecodeCSV_WhenHeaderIsMissing_ShouldReturnError(t *testing.T) {
+	_, _, err := DecodeCSV([]byte("title,Home\n"))
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/xml"
+	"gotranslate/models"
+	"regexp"
+	"strings"
+)
+
+// Entry is a key/text pair decoded from a file, SkipReason is set when the entry exists but shouldn't be imported.
+// Status is only set by formats that store the state of the translation.
+type Entry struct {
+	Key         string
+	Text        string
+	Description string
+	Status      string
+	SkipReason  string
+}
+
+// Plural forms and array items are stored as separate resources, with the quantity or the index appended to the Key
+// after PluralSeparator, e.g. "cart.items#one" or "weekdays#0".
+const PluralSeparator = "#"
+
+var pluralQuantities = []string{"zero", "one", "two", "few", "many", "other"}
+
+var arrayIndex = regexp.MustCompile(`^[0-9]+$`)
+
+// PluralKey creates the Key of a plural form or array item.
+func PluralKey(key, quantity string) string {
+	return key + PluralSeparator + quantity
+}
+
+// splitPluralKey returns the base key and the quantity when the key is a plural form.
+func splitPluralKey(key string) (base, quantity string, isPlural bool) {
+	base, quantity, found := cutLast(key, PluralSeparator)
+	if !found || base == "" {
+		return key, "", false
+	}
+	for _, q := range pluralQuantities {
+		if quantity == q {
+			return base, quantity, true
+		}
+	}
+	return key, "", false
+}
+
+// splitArrayKey returns the base key and the index when the key is an array item.
+func splitArrayKey(key string) (base, index string, isArrayItem bool) {
+	base, index, found := cutLast(key, PluralSeparator)
+	if !found || base == "" || !arrayIndex.MatchString(index) {
+		return key, "", false
+	}
+	return base, index, true
+}
+
+func cutLast(s, separator string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, separator); i >= 0 {
+		return s[:i], s[i+len(separator):], true
+	}
+	return s, "", false
+}
+
+// xmlText marshals as plain character data and unmarshals any element content, including inline markup, as its text.
+type xmlText string
+
+func (t *xmlText) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var buf bytes.Buffer
+	for depth := 1; depth > 0; {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			buf.Write(token)
+		}
+	}
+	*t = xmlText(buf.String())
+	return nil
+}
+
+// toDecodedResources sets the language of the entries, entries without a text are skipped as not translated.
+func toDecodedResources(entries []Entry, languageCode string) []models.DecodedResource {
+	results := []models.DecodedResource{}
+	for _, entry := range entries {
+		result := models.DecodedResource{
+			Resource:   models.Resource{Key: entry.Key, LanguageCode: languageCode, Text: entry.Text, Description: entry.Description, Status: entry.Status},
+			SkipReason: entry.SkipReason,
+		}
+		if result.SkipReason == "" && entry.Text == "" {
+			result.SkipReason = "not translated"
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// resourcesOf returns the resources of a language.
+func resourcesOf(resources []models.Resource, languageCode string) []models.Resource {
+	results := []models.Resource{}
+	for _, resource := range resources {
+		if resource.LanguageCode == languageCode {
+			results = append(results, resource)
+		}
+	}
+	return results
+}
+
+// languageOf returns the language set in the options, or the language found in the file.
+func languageOf(options models.FormatOptions, fileLanguage string) string {
+	if language := options.MainLanguage(); language != "" {
+		return language
+	}
+	return NormalizeLanguageCode(fileLanguage)
+}
+
+// NormalizeLanguageCode reduces locales like "de_DE" or "pt-BR" to their language part.
+func NormalizeLanguageCode(locale string) string {
+	language, _, _ := strings.Cut(strings.ReplaceAll(locale, "-", "_"), "_")
+	return strings.ToLower(language)
+}
@@ -0,0 +1,200 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EncodeJSONBundle renders resources as a JSON object of key/text pairs, as loaded by i18next or vue-i18n.
+// When nested is true keys are split on separator into nested objects, e.g. "home.title" becomes {"home":{"title":...}}.
+func EncodeJSONBundle(resources []models.Resource, nested bool, separator string) ([]byte, error) {
+	bundle := map[string]any{}
+	for _, resource := range resources {
+		if !nested || separator == "" {
+			bundle[resource.Key] = resource.Text
+			continue
+		}
+
+		if err := setNested(bundle, strings.Split(resource.Key, separator), resource.Text); err != nil {
+			return nil, fmt.Errorf("key '%v' can't be nested: %v", resource.Key, err)
+		}
+	}
+
+	return marshalJSON(bundle)
+}
+
+func setNested(bundle map[string]any, path []string, text string) error {
+	for i, part := range path[:len(path)-1] {
+		child, exists := bundle[part]
+		if !exists {
+			child = map[string]any{}
+			bundle[part] = child
+		}
+
+		childBundle, isObject := child.(map[string]any)
+		if !isObject {
+			return fmt.Errorf("'%v' already has a text", strings.Join(path[:i+1], "."))
+		}
+		bundle = childBundle
+	}
+
+	last := path[len(path)-1]
+	if _, exists := bundle[last]; exists {
+		return fmt.Errorf("'%v' already has nested keys", strings.Join(path, "."))
+	}
+	bundle[last] = text
+
+	return nil
+}
+
+// DecodeJSONBundle reads a flat or nested JSON bundle, nested keys are joined with separator.
+// Array items are keyed by their index (see PluralKey), values that aren't strings are returned with a SkipReason.
+func DecodeJSONBundle(data []byte, separator string) ([]Entry, error) {
+	var bundle map[string]any
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	flatten("", bundle, separator, &entries)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+
+	return entries, nil
+}
+
+func flatten(prefix string, value any, separator string, entries *[]Entry) {
+	switch value := value.(type) {
+	case string:
+		*entries = append(*entries, Entry{Key: prefix, Text: value})
+	case map[string]any:
+		for key, child := range value {
+			if prefix != "" {
+				key = prefix + separator + key
+			}
+			flatten(key, child, separator, entries)
+		}
+	case []any:
+		for i, child := range value {
+			flatten(PluralKey(prefix, strconv.Itoa(i)), child, separator, entries)
+		}
+	default:
+		*entries = append(*entries, Entry{Key: prefix, SkipReason: fmt.Sprintf("value '%v' is not a text", value)})
+	}
+}
+
+// EncodeARB renders resources as a Flutter Application Resource Bundle, descriptions are added as "@key" metadata.
+func EncodeARB(resources []models.Resource, languageCode string) ([]byte, error) {
+	bundle := map[string]any{"@@locale": languageCode}
+	for _, resource := range resources {
+		bundle[resource.Key] = resource.Text
+		if resource.Description != "" {
+			bundle["@"+resource.Key] = map[string]string{"description": resource.Description}
+		}
+	}
+
+	return marshalJSON(bundle)
+}
+
+// DecodeARB reads the messages of an ARB file with their descriptions, and the "@@locale" of the file.
+func DecodeARB(data []byte) (entries []Entry, locale string, err error) {
+	var bundle map[string]json.RawMessage
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, "", err
+	}
+
+	for key, raw := range bundle {
+		if key == "@@locale" {
+			json.Unmarshal(raw, &locale)
+		}
+		if strings.HasPrefix(key, "@") {
+			continue
+		}
+
+		entry := Entry{Key: key}
+		if err := json.Unmarshal(raw, &entry.Text); err != nil {
+			entry.SkipReason = "value is not a text"
+		}
+
+		var metadata struct {
+			Description string `json:"description"`
+		}
+		if rawMetadata, exists := bundle["@"+key]; exists && json.Unmarshal(rawMetadata, &metadata) == nil {
+			entry.Description = metadata.Description
+		}
+
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+
+	return entries, locale, nil
+}
+
+// marshalJSON indents the output and keeps characters like < and & as they are, since the texts are not embedded in HTML.
+func marshalJSON(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// JSONBundleFormatter uses Nested and Separator (default ".") from the options.
+type JSONBundleFormatter struct{}
+
+var _ contracts.ResourceFormatter = (*JSONBundleFormatter)(nil)
+
+func (f *JSONBundleFormatter) Encode(resources []models.Resource, options models.FormatOptions) (models.EncodedFile, error) {
+	languageCode := options.MainLanguage()
+	data, err := EncodeJSONBundle(resourcesOf(resources, languageCode), options.Nested, separatorOrDefault(options))
+	if err != nil {
+		return models.EncodedFile{}, fmt.Errorf("%v, export without 'nested' or use a different 'separator'", err.Error())
+	}
+
+	return models.EncodedFile{FileName: languageCode + ".json", ContentType: "application/json; charset=utf-8", Data: data}, nil
+}
+
+func (f *JSONBundleFormatter) Decode(data []byte, options models.FormatOptions) ([]models.DecodedResource, error) {
+	entries, err := DecodeJSONBundle(data, separatorOrDefault(options))
+	if err != nil {
+		return nil, fmt.Errorf("invalid json file: %v", err.Error())
+	}
+	return toDecodedResources(entries, options.MainLanguage()), nil
+}
+
+func separatorOrDefault(options models.FormatOptions) string {
+	if options.Separator == "" {
+		return "."
+	}
+	return options.Separator
+}
+
+type ARBFormatter struct{}
+
+var _ contracts.ResourceFormatter = (*ARBFormatter)(nil)
+
+func (f *ARBFormatter) Encode(resources []models.Resource, options models.FormatOptions) (models.EncodedFile, error) {
+	languageCode := options.MainLanguage()
+	data, err := EncodeARB(resourcesOf(resources, languageCode), languageCode)
+	if err != nil {
+		return models.EncodedFile{}, err
+	}
+
+	return models.EncodedFile{FileName: fmt.Sprintf("app_%s.arb", languageCode), ContentType: "application/json; charset=utf-8", Data: data}, nil
+}
+
+func (f *ARBFormatter) Decode(data []byte, options models.FormatOptions) ([]models.DecodedResource, error) {
+	entries, locale, err := DecodeARB(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid arb file: %v", err.Error())
+	}
+	return toDecodedResources(entries, languageOf(options, locale)), nil
+}
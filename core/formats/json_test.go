@@ -0,0 +1,65 @@
+package formats
+
+import (
+	"gotranslate/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var bundleResources = []models.Resource{
+	{Key: "home.title", Text: "Home"},
+	{Key: "home.subtitle", Text: "Welcome <b>back</b>", Description: "shown under the title"},
+	{Key: "about", Text: "About"},
+}
+
+func TestEncodeJSONBundle_WhenNested_ShouldSplitKeysOnSeparator(t *testing.T) {
+	data, err := EncodeJSONBundle(bundleResources, true, ".")
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"about":"About","home":{"subtitle":"Welcome <b>back</b>","title":"Home"}}`, string(data))
+}
+
+func TestEncodeJSONBundle_WhenNotNested_ShouldKeepKeysFlat(t *testing.T) {
+	data, err := EncodeJSONBundle(bundleResources, false, ".")
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"about":"About","home.subtitle":"Welcome <b>back</b>","home.title":"Home"}`, string(data))
+}
+
+func TestEncodeJSONBundle_WhenKeyIsAlsoAParent_ShouldReturnError(t *testing.T) {
+	resources := append([]models.Resource{{Key: "home", Text: "Home"}}, bundleResources...)
+
+	_, err := EncodeJSONBundle(resources, true, ".")
+
+	assert.ErrorContains(t, err, "home")
+}
+
+func TestDecodeJSONBundle_ShouldFlattenNestedObjectsAndArrays(t *testing.T) {
+	data := `{"home":{"title":"Home","tabs":["One","Two"]},"count":3}`
+
+	entries, err := DecodeJSONBundle([]byte(data), "_")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Key: "count", SkipReason: "value '3' is not a text"},
+		{Key: "home_tabs#0", Text: "One"},
+		{Key: "home_tabs#1", Text: "Two"},
+		{Key: "home_title", Text: "Home"},
+	}, entries)
+}
+
+func TestEncodeARB_ShouldRoundTripDescriptionsAndLocaleWithDecodeARB(t *testing.T) {
+	data, err := EncodeARB(bundleResources, "de")
+	assert.NoError(t, err)
+
+	entries, locale, err := DecodeARB(data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "de", locale)
+	assert.Equal(t, []Entry{
+		{Key: "about", Text: "About"},
+		{Key: "home.subtitle", Text: "Welcome <b>back</b>", Description: "shown under the title"},
+		{Key: "home.title", Text: "Home"},
+	}, entries)
+}
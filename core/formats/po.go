@@ -0,0 +1,313 @@
+package formats
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"strings"
+)
+
+// EncodePO renders the resources of one language as a gettext catalog, using Resource.Key as msgid.
+// When template is true the output is a .pot file: msgstr is left empty and the text is kept as a comment for translators.
+func EncodePO(resources []models.Resource, languageCode string, template bool) []byte {
+	var buf bytes.Buffer
+
+	writePOHeader(&buf, languageCode, template)
+
+	for _, resource := range sortedByKey(resources) {
+		buf.WriteString("\n")
+		if template {
+			for _, line := range strings.Split(resource.Text, "\n") {
+				fmt.Fprintf(&buf, "#. %s\n", line)
+			}
+		}
+		writePOString(&buf, "msgid", resource.Key)
+		if template {
+			writePOString(&buf, "msgstr", "")
+		} else {
+			writePOString(&buf, "msgstr", resource.Text)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func writePOHeader(buf *bytes.Buffer, languageCode string, template bool) {
+	if template {
+		buf.WriteString("# Translation template generated by gotranslate.\n")
+		buf.WriteString("#, fuzzy\n")
+	} else {
+		buf.WriteString("# Translations generated by gotranslate.\n")
+	}
+
+	headers := []string{
+		"Content-Type: text/plain; charset=UTF-8\n",
+		"Content-Transfer-Encoding: 8bit\n",
+	}
+	if !template {
+		headers = append(headers, fmt.Sprintf("Language: %s\n", languageCode))
+	}
+	headers = append(headers, "X-Generator: gotranslate\n")
+
+	buf.WriteString("msgid \"\"\n")
+	buf.WriteString("msgstr \"\"\n")
+	for _, header := range headers {
+		fmt.Fprintf(buf, "\"%s\"\n", escapePO(header))
+	}
+}
+
+// writePOString writes a keyword and its value, splitting text with line breaks into multiple quoted lines like gettext does.
+func writePOString(buf *bytes.Buffer, keyword, text string) {
+	lines := splitKeepingNewlines(text)
+	if len(lines) <= 1 {
+		fmt.Fprintf(buf, "%s \"%s\"\n", keyword, escapePO(text))
+		return
+	}
+
+	fmt.Fprintf(buf, "%s \"\"\n", keyword)
+	for _, line := range lines {
+		fmt.Fprintf(buf, "\"%s\"\n", escapePO(line))
+	}
+}
+
+func splitKeepingNewlines(text string) []string {
+	var lines []string
+	for len(text) > 0 {
+		i := strings.Index(text, "\n")
+		if i < 0 {
+			lines = append(lines, text)
+			break
+		}
+		lines = append(lines, text[:i+1])
+		text = text[i+1:]
+	}
+	return lines
+}
+
+var poEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+)
+
+func escapePO(text string) string {
+	return poEscaper.Replace(text)
+}
+
+// POEntry is a single message parsed from a gettext catalog.
+type POEntry struct {
+	Context  string
+	ID       string
+	IDPlural string
+	Str      string
+	Fuzzy    bool
+	Comments []string
+	Line     int
+}
+
+// IsHeader reports whether the entry is the catalog header (the entry with an empty msgid).
+func (e POEntry) IsHeader() bool {
+	return e.ID == "" && e.Context == ""
+}
+
+// Key is the resource Key the entry maps to, msgctxt is used as a prefix so the same msgid in different contexts doesn't collide.
+func (e POEntry) Key() string {
+	if e.Context == "" {
+		return e.ID
+	}
+	return e.Context + "." + e.ID
+}
+
+// POFile is a parsed gettext catalog.
+type POFile struct {
+	Language string
+	Entries  []POEntry
+}
+
+// DecodePO parses a .po/.pot file. Obsolete (#~) entries are dropped, and for plural entries only msgstr[0] is kept in Str.
+func DecodePO(data []byte) (POFile, error) {
+	var file POFile
+	var entry POEntry
+	var current *string
+	started, hasStr := false, false
+
+	flush := func() {
+		if started {
+			file.Entries = append(file.Entries, entry)
+		}
+		entry, current, started, hasStr = POEntry{}, nil, false, false
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for i, rawLine := range lines {
+		lineNumber := i + 1
+		line := strings.TrimSpace(rawLine)
+
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "#~"):
+			continue
+		case strings.HasPrefix(line, "#"):
+			if hasStr {
+				flush() // a comment after msgstr starts the next entry
+			}
+			if strings.HasPrefix(line, "#,") {
+				for _, flag := range strings.Split(line[2:], ",") {
+					if strings.TrimSpace(flag) == "fuzzy" {
+						entry.Fuzzy = true
+					}
+				}
+			} else {
+				entry.Comments = append(entry.Comments, strings.TrimSpace(strings.TrimLeft(line[1:], ".:|")))
+			}
+		case strings.HasPrefix(line, `"`):
+			if current == nil {
+				return file, fmt.Errorf("line %d: string continuation without a keyword", lineNumber)
+			}
+			text, err := unquotePO(line)
+			if err != nil {
+				return file, fmt.Errorf("line %d: %v", lineNumber, err)
+			}
+			*current += text
+		default:
+			keyword, value, found := strings.Cut(line, " ")
+			if !found {
+				return file, fmt.Errorf("line %d: unexpected content '%v'", lineNumber, line)
+			}
+
+			if hasStr && (keyword == "msgctxt" || keyword == "msgid") {
+				flush()
+			}
+			if !started {
+				entry.Line = lineNumber
+				started = true
+			}
+
+			switch {
+			case keyword == "msgctxt":
+				current = &entry.Context
+			case keyword == "msgid":
+				current = &entry.ID
+			case keyword == "msgid_plural":
+				current = &entry.IDPlural
+			case keyword == "msgstr" || keyword == "msgstr[0]":
+				current, hasStr = &entry.Str, true
+			case strings.HasPrefix(keyword, "msgstr["):
+				current, hasStr = new(string), true // other plural forms are parsed but not kept
+			default:
+				return file, fmt.Errorf("line %d: unknown keyword '%v'", lineNumber, keyword)
+			}
+
+			text, err := unquotePO(strings.TrimSpace(value))
+			if err != nil {
+				return file, fmt.Errorf("line %d: %v", lineNumber, err)
+			}
+			*current = text
+		}
+	}
+	flush()
+
+	for _, e := range file.Entries {
+		if e.IsHeader() {
+			file.Language = parsePOHeaderLanguage(e.Str)
+			break
+		}
+	}
+
+	return file, nil
+}
+
+func unquotePO(quoted string) (string, error) {
+	if len(quoted) < 2 || !strings.HasPrefix(quoted, `"`) || !strings.HasSuffix(quoted, `"`) {
+		return "", fmt.Errorf("expected quoted string but found '%v'", quoted)
+	}
+
+	var sb strings.Builder
+	inner := quoted[1 : len(quoted)-1]
+	for i := 0; i < len(inner); i++ {
+		c := inner[i]
+		if c != '\\' {
+			sb.WriteByte(c)
+			continue
+		}
+
+		i++
+		if i == len(inner) {
+			return "", errors.New("string ends with an unfinished escape sequence")
+		}
+		switch inner[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case '"', '\\':
+			sb.WriteByte(inner[i])
+		default:
+			return "", fmt.Errorf("unsupported escape sequence '\\%c'", inner[i])
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func parsePOHeaderLanguage(header string) string {
+	for _, line := range strings.Split(header, "\n") {
+		name, value, found := strings.Cut(line, ":")
+		if found && strings.TrimSpace(name) == "Language" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// POFormatter exports .po catalogs, or .pot templates when Template is set.
+type POFormatter struct {
+	Template bool
+}
+
+var _ contracts.ResourceFormatter = (*POFormatter)(nil)
+
+func (f *POFormatter) Encode(resources []models.Resource, options models.FormatOptions) (models.EncodedFile, error) {
+	languageCode := options.MainLanguage()
+	file := models.EncodedFile{
+		FileName:    languageCode + ".po",
+		ContentType: "text/x-gettext-translation; charset=utf-8",
+		Data:        EncodePO(resourcesOf(resources, languageCode), languageCode, f.Template),
+	}
+	if f.Template {
+		file.FileName = "messages.pot"
+	}
+	return file, nil
+}
+
+// Decode skips fuzzy, untranslated and plural entries. The language is read from the Language header unless it's set in the options.
+func (f *POFormatter) Decode(data []byte, options models.FormatOptions) ([]models.DecodedResource, error) {
+	file, err := DecodePO(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid po file: %v", err.Error())
+	}
+
+	var entries []Entry
+	for _, poEntry := range file.Entries {
+		if poEntry.IsHeader() {
+			continue
+		}
+
+		entry := Entry{Key: poEntry.Key(), Text: poEntry.Str}
+		if poEntry.IDPlural != "" {
+			entry.SkipReason = "plural entries are not supported"
+		} else if poEntry.Fuzzy {
+			entry.SkipReason = "fuzzy translation"
+		}
+		entries = append(entries, entry)
+	}
+
+	return toDecodedResources(entries, languageOf(options, file.Language)), nil
+}
@@ -0,0 +1,113 @@
+package formats
+
+import (
+	"gotranslate/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodePO_ShouldWriteMsgidAndMsgstrPerResource(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "home.title", LanguageCode: "de", Text: "Startseite"},
+		{Key: "about.title", LanguageCode: "de", Text: "Über uns"},
+	}
+
+	result := string(EncodePO(resources, "de", false))
+
+	assert.Contains(t, result, "\"Language: de\\n\"\n")
+	assert.Contains(t, result, "msgid \"home.title\"\nmsgstr \"Startseite\"\n")
+	assert.Contains(t, result, "msgid \"about.title\"\nmsgstr \"Über uns\"\n")
+	assert.Less(t, strings.Index(result, "about.title"), strings.Index(result, "home.title"), "entries should be sorted by key")
+}
+
+func TestEncodePO_WhenTextHasQuotesAndNewlines_ShouldEscapeAndSplitLines(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "quote", LanguageCode: "en", Text: `say "hi" \o/`},
+		{Key: "multiline", LanguageCode: "en", Text: "line 1\nline 2"},
+	}
+
+	result := string(EncodePO(resources, "en", false))
+
+	assert.Contains(t, result, `msgstr "say \"hi\" \\o/"`)
+	assert.Contains(t, result, "msgstr \"\"\n\"line 1\\n\"\n\"line 2\"\n")
+}
+
+func TestEncodePO_WhenTemplate_ShouldLeaveMsgstrEmptyAndCommentSourceText(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "home.title", LanguageCode: "en", Text: "Home"},
+	}
+
+	result := string(EncodePO(resources, "en", true))
+
+	assert.Contains(t, result, "#. Home\nmsgid \"home.title\"\nmsgstr \"\"\n")
+	assert.NotContains(t, result, "Language: en")
+}
+
+func TestDecodePO_ShouldParseEntriesContextFlagsAndMultilineStrings(t *testing.T) {
+	data := `# translator comment
+msgid ""
+msgstr ""
+"Language: de_DE\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#. extracted comment
+#: src/home.go:12
+msgid "home.title"
+msgstr "Startseite"
+
+#, fuzzy
+msgid "home.subtitle"
+msgstr "Willkommen"
+
+msgctxt "menu"
+msgid "open"
+msgstr ""
+"Zeile 1\n"
+"Zeile \"2\""
+
+#~ msgid "obsolete"
+#~ msgstr "veraltet"
+`
+
+	file, err := DecodePO([]byte(data))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "de_DE", file.Language)
+	assert.Len(t, file.Entries, 4)
+	assert.True(t, file.Entries[0].IsHeader())
+
+	assert.Equal(t, "home.title", file.Entries[1].Key())
+	assert.Equal(t, "Startseite", file.Entries[1].Str)
+	assert.Equal(t, []string{"extracted comment", "src/home.go:12"}, file.Entries[1].Comments)
+	assert.False(t, file.Entries[1].Fuzzy)
+
+	assert.True(t, file.Entries[2].Fuzzy)
+
+	assert.Equal(t, "menu.open", file.Entries[3].Key())
+	assert.Equal(t, "Zeile 1\nZeile \"2\"", file.Entries[3].Str)
+}
+
+func TestDecodePO_WhenEncodedByEncodePO_ShouldRoundTrip(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "a", LanguageCode: "fi", Text: "tab\there \"quoted\" back\\slash"},
+		{Key: "b", LanguageCode: "fi", Text: "first\nsecond\n"},
+	}
+
+	file, err := DecodePO(EncodePO(resources, "fi", false))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "fi", file.Language)
+	assert.Len(t, file.Entries, 3)
+	for i, resource := range resources {
+		assert.Equal(t, resource.Key, file.Entries[i+1].Key())
+		assert.Equal(t, resource.Text, file.Entries[i+1].Str)
+	}
+}
+
+func TestDecodePO_WhenStringIsNotQuoted_ShouldReturnErrorWithLine(t *testing.T) {
+	_, err := DecodePO([]byte("msgid \"a\"\nmsgstr b\n"))
+
+	assert.ErrorContains(t, err, "line 2")
+}
@@ -0,0 +1,189 @@
+package formats
+
+import (
+	"bytes"
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// EncodeProperties renders resources as a Java .properties file. Everything outside printable ASCII is written
+// as \uXXXX escapes so the file is valid in the ISO-8859-1 encoding older Java versions expect.
+func EncodeProperties(resources []models.Resource) []byte {
+	var buf bytes.Buffer
+	for _, resource := range sortedByKey(resources) {
+		if resource.Description != "" {
+			fmt.Fprintf(&buf, "# %s\n", escapeProperties(strings.ReplaceAll(resource.Description, "\n", " "), false))
+		}
+		fmt.Fprintf(&buf, "%s=%s\n", escapeProperties(resource.Key, true), escapeProperties(resource.Text, false))
+	}
+	return buf.Bytes()
+}
+
+func escapeProperties(text string, isKey bool) string {
+	var sb strings.Builder
+	for i, r := range text {
+		switch {
+		case r == '\\':
+			sb.WriteString(`\\`)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r == '\f':
+			sb.WriteString(`\f`)
+		case strings.ContainsRune("=:#!", r) && (isKey || i == 0):
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case r == ' ' && (isKey || i == 0):
+			sb.WriteString(`\ `)
+		case r < 0x20 || r > 0x7E:
+			for _, unit := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(&sb, `\u%04X`, unit)
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// DecodeProperties parses a .properties file, including line continuations, the '=', ':' and whitespace separators
+// and \uXXXX escapes. A comment right before an entry is read as its description.
+func DecodeProperties(data []byte) ([]Entry, error) {
+	var entries []Entry
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	description := ""
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" {
+			description = ""
+			continue
+		}
+		if line[0] == '#' || line[0] == '!' {
+			description = strings.TrimSpace(line[1:])
+			continue
+		}
+
+		for endsWithContinuation(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+
+		rawKey, rawValue := splitPropertiesLine(line)
+		key, err := unescapeProperties(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		text, err := unescapeProperties(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+
+		entries = append(entries, Entry{Key: key, Text: text, Description: description})
+		description = ""
+	}
+
+	return entries, nil
+}
+
+// endsWithContinuation reports whether the line ends with an odd number of backslashes.
+func endsWithContinuation(line string) bool {
+	count := len(line) - len(strings.TrimRight(line, `\`))
+	return count%2 == 1
+}
+
+func splitPropertiesLine(line string) (key, value string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':':
+			return line[:i], strings.TrimLeft(line[i+1:], " \t\f")
+		case ' ', '\t', '\f':
+			rest := strings.TrimLeft(line[i:], " \t\f")
+			if strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, ":") {
+				rest = strings.TrimLeft(rest[1:], " \t\f")
+			}
+			return line[:i], rest
+		}
+	}
+	return line, ""
+}
+
+func unescapeProperties(text string) (string, error) {
+	var sb strings.Builder
+	var pending []uint16 // utf-16 code units, to join surrogate pairs
+	flush := func() {
+		if len(pending) > 0 {
+			sb.WriteString(string(utf16.Decode(pending)))
+			pending = nil
+		}
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i+1 == len(text) {
+			flush()
+			sb.WriteByte(text[i])
+			continue
+		}
+
+		i++
+		if text[i] == 'u' {
+			if i+4 >= len(text) {
+				return "", fmt.Errorf("invalid unicode escape '%v'", text[i-1:])
+			}
+			unit, err := strconv.ParseUint(text[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape '%v'", text[i-1:i+5])
+			}
+			pending = append(pending, uint16(unit))
+			i += 4
+			continue
+		}
+
+		flush()
+		switch text[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'f':
+			sb.WriteByte('\f')
+		default:
+			sb.WriteByte(text[i])
+		}
+	}
+	flush()
+
+	return sb.String(), nil
+}
+
+type PropertiesFormatter struct{}
+
+var _ contracts.ResourceFormatter = (*PropertiesFormatter)(nil)
+
+func (f *PropertiesFormatter) Encode(resources []models.Resource, options models.FormatOptions) (models.EncodedFile, error) {
+	languageCode := options.MainLanguage()
+	return models.EncodedFile{
+		FileName:    fmt.Sprintf("messages_%s.properties", languageCode),
+		ContentType: "text/x-java-properties; charset=iso-8859-1",
+		Data:        EncodeProperties(resourcesOf(resources, languageCode)),
+	}, nil
+}
+
+func (f *PropertiesFormatter) Decode(data []byte, options models.FormatOptions) ([]models.DecodedResource, error) {
+	entries, err := DecodeProperties(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid properties file: %v", err.Error())
+	}
+	return toDecodedResources(entries, options.MainLanguage()), nil
+}
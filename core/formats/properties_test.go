@@ -0,0 +1,51 @@
+package formats
+
+import (
+	"gotranslate/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeProperties_ShouldEscapeNonASCIIAndSpecialCharacters(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "greeting key", Text: "Grüße 😀", Description: "shown on login"},
+		{Key: "a=b", Text: " leading space\nnew line"},
+	}
+
+	result := string(EncodeProperties(resources))
+
+	assert.Equal(t, "a\\=b=\\ leading space\\nnew line\n# shown on login\ngreeting\\ key=Gr\\u00FC\\u00DFe \\uD83D\\uDE00\n", result)
+}
+
+func TestDecodeProperties_ShouldRoundTripWithEncodeProperties(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "a=b", Text: " leading space\nnew line"},
+		{Key: "greeting key", Text: "Grüße 😀", Description: "shown on login"},
+	}
+
+	entries, err := DecodeProperties(EncodeProperties(resources))
+
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	for i, resource := range resources {
+		assert.Equal(t, Entry{Key: resource.Key, Text: resource.Text, Description: resource.Description}, entries[i])
+	}
+}
+
+func TestDecodeProperties_ShouldSupportSeparatorsAndContinuationLines(t *testing.T) {
+	data := "! comment\n" +
+		"colon:value 1\n" +
+		"space   value 2\n" +
+		"multi = first \\\n" +
+		"        second\n"
+
+	entries, err := DecodeProperties([]byte(data))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Key: "colon", Text: "value 1", Description: "comment"},
+		{Key: "space", Text: "value 2"},
+		{Key: "multi", Text: "first second"},
+	}, entries)
+}
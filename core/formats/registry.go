@@ -0,0 +1,52 @@
+package formats
+
+import (
+	"gotranslate/core/contracts"
+	"sort"
+)
+
+// Registry holds the formatters available to export and import resources, by the name used in the 'format' parameter.
+type Registry struct {
+	formatters map[string]contracts.ResourceFormatter
+}
+
+func NewRegistry() *Registry {
+	return &Registry{formatters: map[string]contracts.ResourceFormatter{}}
+}
+
+// NewDefaultRegistry creates a registry with all the formats implemented in this package.
+func NewDefaultRegistry() *Registry {
+	registry := NewRegistry()
+	registry.Register("po", &POFormatter{})
+	registry.Register("pot", &POFormatter{Template: true})
+	registry.Register("xliff", &XLIFFFormatter{})
+	registry.Register("android", &AndroidFormatter{})
+	registry.Register("strings", &AppleStringsFormatter{})
+	registry.Register("stringsdict", &AppleStringsDictFormatter{})
+	registry.Register("json", &JSONBundleFormatter{})
+	registry.Register("arb", &ARBFormatter{})
+	registry.Register("properties", &PropertiesFormatter{})
+	registry.Register("resx", &RESXFormatter{})
+	registry.Register("yaml", &RailsYAMLFormatter{})
+	registry.Register("csv", &CSVFormatter{})
+	return registry
+}
+
+// Register adds a formatter, replacing any formatter registered with the same name.
+func (r *Registry) Register(name string, formatter contracts.ResourceFormatter) {
+	r.formatters[name] = formatter
+}
+
+func (r *Registry) Get(name string) (formatter contracts.ResourceFormatter, found bool) {
+	formatter, found = r.formatters[name]
+	return formatter, found
+}
+
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.formatters))
+	for name := range r.formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
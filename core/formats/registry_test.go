@@ -0,0 +1,72 @@
+package formats
+
+import (
+	"gotranslate/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type formatterStub struct{}
+
+func (f *formatterStub) Encode(resources []models.Resource, options models.FormatOptions) (models.EncodedFile, error) {
+	return models.EncodedFile{FileName: "stub.txt"}, nil
+}
+
+func (f *formatterStub) Decode(data []byte, options models.FormatOptions) ([]models.DecodedResource, error) {
+	return nil, nil
+}
+
+func TestRegistry_WhenFormatterIsRegistered_ShouldBeFoundByName(t *testing.T) {
+	registry := NewDefaultRegistry()
+
+	registry.Register("stub", &formatterStub{})
+	formatter, found := registry.Get("stub")
+	_, notFound := registry.Get("unknown")
+
+	assert.True(t, found)
+	assert.IsType(t, &formatterStub{}, formatter)
+	assert.False(t, notFound)
+	assert.Contains(t, registry.Names(), "stub")
+	assert.Contains(t, registry.Names(), "po")
+}
+
+func TestDefaultFormatters_ShouldDecodeWhatTheyEncode(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "home.title", LanguageCode: "en", Text: "Home"},
+		{Key: "home.subtitle", LanguageCode: "en", Text: "Welcome \"back\"\nfriend"},
+		{Key: "home.title", LanguageCode: "de", Text: "Startseite"},
+		{Key: "home.subtitle", LanguageCode: "de", Text: "Willkommen zurück"},
+	}
+	registry := NewDefaultRegistry()
+
+	for _, name := range registry.Names() {
+		if name == "pot" || name == "stringsdict" {
+			continue // templates have no translations and stringsdict only has plurals
+		}
+
+		t.Run(name, func(t *testing.T) {
+			formatter, _ := registry.Get(name)
+			options := models.FormatOptions{LanguageCodes: []string{"de"}}
+			if name == "xliff" {
+				options.LanguageCodes = []string{"en", "de"}
+			}
+
+			file, err := formatter.Encode(resources, options)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, file.FileName)
+			assert.NotEmpty(t, file.ContentType)
+
+			decoded, err := formatter.Decode(file.Data, models.FormatOptions{LanguageCodes: []string{"de"}})
+			assert.NoError(t, err)
+
+			var german []models.Resource
+			for _, item := range decoded {
+				if item.SkipReason == "" && item.Resource.LanguageCode == "de" {
+					german = append(german, item.Resource)
+				}
+			}
+			assert.ElementsMatch(t, resources[2:], german)
+		})
+	}
+}
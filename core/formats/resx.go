@@ -0,0 +1,95 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+)
+
+type resxDocument struct {
+	XMLName xml.Name     `xml:"root"`
+	Headers []resxHeader `xml:"resheader"`
+	Data    []resxData   `xml:"data"`
+}
+
+type resxHeader struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type resxData struct {
+	Name    string `xml:"name,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Space   string `xml:"http://www.w3.org/XML/1998/namespace space,attr,omitempty"`
+	Value   string `xml:"value"`
+	Comment string `xml:"comment,omitempty"`
+}
+
+// EncodeRESX renders resources as a .NET .resx file, descriptions are written as the comment of each entry.
+func EncodeRESX(resources []models.Resource) ([]byte, error) {
+	document := resxDocument{
+		Headers: []resxHeader{
+			{Name: "resmimetype", Value: "text/microsoft-resx"},
+			{Name: "version", Value: "2.0"},
+			{Name: "reader", Value: "System.Resources.ResXResourceReader, System.Windows.Forms, Version=4.0.0.0, Culture=neutral, PublicKeyToken=b77a5c561934e089"},
+			{Name: "writer", Value: "System.Resources.ResXResourceWriter, System.Windows.Forms, Version=4.0.0.0, Culture=neutral, PublicKeyToken=b77a5c561934e089"},
+		},
+	}
+	for _, resource := range sortedByKey(resources) {
+		document.Data = append(document.Data, resxData{Name: resource.Key, Space: "preserve", Value: resource.Text, Comment: resource.Description})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+
+	return buf.Bytes(), nil
+}
+
+// DecodeRESX reads the string entries of a .resx file, entries with a type (images, files, etc) are skipped.
+func DecodeRESX(data []byte) ([]Entry, error) {
+	var document resxDocument
+	if err := xml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, item := range document.Data {
+		entry := Entry{Key: item.Name, Text: item.Value, Description: item.Comment}
+		if item.Type != "" {
+			entry.SkipReason = fmt.Sprintf("type '%v' is not a text", item.Type)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+type RESXFormatter struct{}
+
+var _ contracts.ResourceFormatter = (*RESXFormatter)(nil)
+
+func (f *RESXFormatter) Encode(resources []models.Resource, options models.FormatOptions) (models.EncodedFile, error) {
+	languageCode := options.MainLanguage()
+	data, err := EncodeRESX(resourcesOf(resources, languageCode))
+	if err != nil {
+		return models.EncodedFile{}, err
+	}
+
+	return models.EncodedFile{FileName: fmt.Sprintf("Resources.%s.resx", languageCode), ContentType: "application/xml; charset=utf-8", Data: data}, nil
+}
+
+func (f *RESXFormatter) Decode(data []byte, options models.FormatOptions) ([]models.DecodedResource, error) {
+	entries, err := DecodeRESX(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resx file: %v", err.Error())
+	}
+	return toDecodedResources(entries, options.MainLanguage()), nil
+}
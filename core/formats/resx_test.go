@@ -0,0 +1,39 @@
+package formats
+
+import (
+	"gotranslate/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeRESX_ShouldRoundTripWithDecodeRESX(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "Greeting", Text: "  Hello & <welcome>  ", Description: "login page"},
+		{Key: "Title", Text: "Home"},
+	}
+
+	data, err := EncodeRESX(resources)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `<data name="Greeting" xml:space="preserve">`)
+
+	entries, err := DecodeRESX(data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Key: "Greeting", Text: "  Hello & <welcome>  ", Description: "login page"},
+		{Key: "Title", Text: "Home"},
+	}, entries)
+}
+
+func TestDecodeRESX_WhenDataHasType_ShouldSkipIt(t *testing.T) {
+	data := `<root>
+  <data name="Logo" type="System.Resources.ResXFileRef, System.Windows.Forms"><value>logo.png</value></data>
+</root>`
+
+	entries, err := DecodeRESX([]byte(data))
+
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.NotEmpty(t, entries[0].SkipReason)
+}
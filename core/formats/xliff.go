@@ -0,0 +1,319 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+)
+
+const (
+	XLIFF12 = "1.2"
+	XLIFF20 = "2.0"
+)
+
+// XLIFF states that mean the unit has a usable translation, for both versions.
+var xliffTranslatedStates = map[string]bool{
+	"translated": true,
+	"reviewed":   true,
+	"final":      true,
+	"signed-off": true,
+}
+
+// xliffStateOf returns the state of a translated target from the status of the resource. XLIFF 1.2 marks machine
+// translations with the 'mt-suggestion' state qualifier, XLIFF 2.0 has no equivalent.
+func xliffStateOf(version, status string) (state, qualifier string) {
+	switch {
+	case status == models.StatusApproved:
+		return "final", ""
+	case status == models.StatusReviewed && version == XLIFF12:
+		return "signed-off", ""
+	case status == models.StatusReviewed:
+		return "reviewed", ""
+	case status == models.StatusMachine && version == XLIFF12:
+		return "translated", "mt-suggestion"
+	default:
+		return "translated", ""
+	}
+}
+
+// Status returns the resource status of the unit, or "" when its state has no matching status.
+func (u XLIFFUnit) Status() string {
+	switch {
+	case u.State == "final":
+		return models.StatusApproved
+	case u.State == "signed-off" || u.State == "reviewed":
+		return models.StatusReviewed
+	case u.StateQualifier == "mt-suggestion":
+		return models.StatusMachine
+	default:
+		return ""
+	}
+}
+
+// XLIFFUnit is a source/target pair keyed by the resource Key.
+type XLIFFUnit struct {
+	ID             string
+	Source         string
+	Target         string
+	State          string
+	StateQualifier string
+	Note           string
+}
+
+// IsTranslated reports whether the unit has a target in a state that counts as translated.
+// Units without a state are considered translated when they have a target.
+func (u XLIFFUnit) IsTranslated() bool {
+	if u.Target == "" {
+		return false
+	}
+	if u.State == "" {
+		return true
+	}
+	return xliffTranslatedStates[u.State]
+}
+
+type XLIFFDocument struct {
+	Version        string
+	SourceLanguage string
+	TargetLanguage string
+	Units          []XLIFFUnit
+}
+
+// EncodeXLIFF creates a document with a unit per source resource and the target text of the resource with the same Key, if any.
+func EncodeXLIFF(version, sourceLanguage, targetLanguage string, sources, targets []models.Resource) ([]byte, error) {
+	targetTexts, targetStatuses := map[string]string{}, map[string]string{}
+	for _, target := range targets {
+		targetTexts[target.Key] = target.Text
+		targetStatuses[target.Key] = target.Status
+	}
+
+	sorted := sortedByKey(sources)
+
+	var document any
+	switch version {
+	case XLIFF12:
+		file := xliff12File{Original: "gotranslate", DataType: "plaintext", SourceLanguage: sourceLanguage, TargetLanguage: targetLanguage}
+		for _, source := range sorted {
+			target, translated := targetTexts[source.Key]
+			unit := xliff12Unit{ID: source.Key, ResName: source.Key, Source: xmlText(source.Text), Target: &xliff12Target{Text: xmlText(target), State: "needs-translation"}}
+			if translated {
+				unit.Target.State, unit.Target.StateQualifier = xliffStateOf(XLIFF12, targetStatuses[source.Key])
+			}
+			file.Body.Units = append(file.Body.Units, unit)
+		}
+		document = xliff12Document{Version: XLIFF12, Namespace: "urn:oasis:names:tc:xliff:document:1.2", Files: []xliff12File{file}}
+	case XLIFF20:
+		file := xliff20File{ID: "gotranslate"}
+		for _, source := range sorted {
+			target, translated := targetTexts[source.Key]
+			segment := xliff20Segment{Source: xmlText(source.Text), State: "initial"}
+			if translated {
+				segment.Target = (*xmlText)(&target)
+				segment.State, _ = xliffStateOf(XLIFF20, targetStatuses[source.Key])
+			}
+			file.Units = append(file.Units, xliff20Unit{ID: source.Key, Name: source.Key, Segments: []xliff20Segment{segment}})
+		}
+		document = xliff20Document{Version: XLIFF20, Namespace: "urn:oasis:names:tc:xliff:document:2.0", SourceLanguage: sourceLanguage, TargetLanguage: targetLanguage, Files: []xliff20File{file}}
+	default:
+		return nil, fmt.Errorf("unsupported xliff version '%v'", version)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+
+	return buf.Bytes(), nil
+}
+
+// DecodeXLIFF parses XLIFF 1.2 or 2.0 documents, the version is taken from the root element.
+// Inline markup inside source and target is flattened to its text.
+func DecodeXLIFF(data []byte) (XLIFFDocument, error) {
+	var root struct {
+		Version string `xml:"version,attr"`
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return XLIFFDocument{}, err
+	}
+
+	result := XLIFFDocument{Version: root.Version}
+	switch root.Version {
+	case XLIFF12:
+		var document xliff12Document
+		if err := xml.Unmarshal(data, &document); err != nil {
+			return result, err
+		}
+		for _, file := range document.Files {
+			result.SourceLanguage, result.TargetLanguage = file.SourceLanguage, file.TargetLanguage
+			for _, unit := range file.Body.Units {
+				item := XLIFFUnit{ID: unit.ID, Source: string(unit.Source), Note: unit.Note}
+				if unit.ResName != "" {
+					item.ID = unit.ResName
+				}
+				if unit.Target != nil {
+					item.Target, item.State, item.StateQualifier = string(unit.Target.Text), unit.Target.State, unit.Target.StateQualifier
+				}
+				result.Units = append(result.Units, item)
+			}
+		}
+	case XLIFF20:
+		var document xliff20Document
+		if err := xml.Unmarshal(data, &document); err != nil {
+			return result, err
+		}
+		result.SourceLanguage, result.TargetLanguage = document.SourceLanguage, document.TargetLanguage
+		for _, file := range document.Files {
+			for _, unit := range file.Units {
+				item := XLIFFUnit{ID: unit.ID}
+				if unit.Name != "" {
+					item.ID = unit.Name
+				}
+				for i, segment := range unit.Segments {
+					item.Source += string(segment.Source)
+					if segment.Target != nil {
+						item.Target += string(*segment.Target)
+					}
+					if i == 0 || !xliffTranslatedStates[segment.State] {
+						item.State = segment.State // a unit is only as translated as its least translated segment
+					}
+				}
+				if unit.Notes != nil && len(unit.Notes.Notes) > 0 {
+					item.Note = unit.Notes.Notes[0]
+				}
+				result.Units = append(result.Units, item)
+			}
+		}
+	default:
+		return result, fmt.Errorf("unsupported xliff version '%v'", root.Version)
+	}
+
+	return result, nil
+}
+
+type xliff12Document struct {
+	XMLName   xml.Name      `xml:"xliff"`
+	Namespace string        `xml:"xmlns,attr"`
+	Version   string        `xml:"version,attr"`
+	Files     []xliff12File `xml:"file"`
+}
+
+type xliff12File struct {
+	Original       string `xml:"original,attr"`
+	DataType       string `xml:"datatype,attr"`
+	SourceLanguage string `xml:"source-language,attr"`
+	TargetLanguage string `xml:"target-language,attr,omitempty"`
+	Body           struct {
+		Units []xliff12Unit `xml:"trans-unit"`
+	} `xml:"body"`
+}
+
+type xliff12Unit struct {
+	ID      string         `xml:"id,attr"`
+	ResName string         `xml:"resname,attr,omitempty"`
+	Source  xmlText        `xml:"source"`
+	Target  *xliff12Target `xml:"target"`
+	Note    string         `xml:"note,omitempty"`
+}
+
+type xliff12Target struct {
+	Text           xmlText `xml:",chardata"`
+	State          string  `xml:"state,attr,omitempty"`
+	StateQualifier string  `xml:"state-qualifier,attr,omitempty"`
+}
+
+func (t *xliff12Target) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "state":
+			t.State = attr.Value
+		case "state-qualifier":
+			t.StateQualifier = attr.Value
+		}
+	}
+	return t.Text.UnmarshalXML(d, start)
+}
+
+type xliff20Document struct {
+	XMLName        xml.Name      `xml:"xliff"`
+	Namespace      string        `xml:"xmlns,attr"`
+	Version        string        `xml:"version,attr"`
+	SourceLanguage string        `xml:"srcLang,attr"`
+	TargetLanguage string        `xml:"trgLang,attr,omitempty"`
+	Files          []xliff20File `xml:"file"`
+}
+
+type xliff20File struct {
+	ID    string        `xml:"id,attr"`
+	Units []xliff20Unit `xml:"unit"`
+}
+
+type xliff20Unit struct {
+	ID       string           `xml:"id,attr"`
+	Name     string           `xml:"name,attr,omitempty"`
+	Notes    *xliff20Notes    `xml:"notes"`
+	Segments []xliff20Segment `xml:"segment"`
+}
+
+type xliff20Segment struct {
+	State  string   `xml:"state,attr,omitempty"`
+	Source xmlText  `xml:"source"`
+	Target *xmlText `xml:"target"`
+}
+
+type xliff20Notes struct {
+	Notes []string `xml:"note"`
+}
+
+// XLIFFFormatter exports the first language of the options as source and the second as target.
+type XLIFFFormatter struct{}
+
+var _ contracts.ResourceFormatter = (*XLIFFFormatter)(nil)
+
+func (f *XLIFFFormatter) Encode(resources []models.Resource, options models.FormatOptions) (models.EncodedFile, error) {
+	if len(options.LanguageCodes) != 2 {
+		return models.EncodedFile{}, errors.New("xliff needs a source and a target language")
+	}
+
+	version := options.Version
+	if version == "" {
+		version = XLIFF12
+	}
+
+	sourceLanguage, targetLanguage := options.LanguageCodes[0], options.LanguageCodes[1]
+	data, err := EncodeXLIFF(version, sourceLanguage, targetLanguage, resourcesOf(resources, sourceLanguage), resourcesOf(resources, targetLanguage))
+	if err != nil {
+		return models.EncodedFile{}, err
+	}
+
+	return models.EncodedFile{
+		FileName:    fmt.Sprintf("%s-%s.xlf", sourceLanguage, targetLanguage),
+		ContentType: "application/xliff+xml; charset=utf-8",
+		Data:        data,
+	}, nil
+}
+
+// Decode returns the target texts of the document, units that aren't in a translated state are skipped.
+func (f *XLIFFFormatter) Decode(data []byte, options models.FormatOptions) ([]models.DecodedResource, error) {
+	document, err := DecodeXLIFF(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid xliff file: %v", err.Error())
+	}
+
+	var entries []Entry
+	for _, unit := range document.Units {
+		entry := Entry{Key: unit.ID, Text: unit.Target, Status: unit.Status()}
+		if !unit.IsTranslated() {
+			entry.SkipReason = fmt.Sprintf("not translated, state '%v'", unit.State)
+		}
+		entries = append(entries, entry)
+	}
+
+	return toDecodedResources(entries, languageOf(options, document.TargetLanguage)), nil
+}
@@ -0,0 +1,131 @@
+package formats
+
+import (
+	"gotranslate/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var xliffSources = []models.Resource{
+	{Key: "home.title", LanguageCode: "en", Text: "Home"},
+	{Key: "home.greeting", LanguageCode: "en", Text: "Hello <b>you</b> & welcome"},
+}
+
+var xliffTargets = []models.Resource{
+	{Key: "home.title", LanguageCode: "de", Text: "Startseite"},
+}
+
+func TestEncodeXLIFF_WhenVersionIsNotSupported_ShouldReturnError(t *testing.T) {
+	_, err := EncodeXLIFF("3.0", "en", "de", xliffSources, xliffTargets)
+
+	assert.Error(t, err)
+}
+
+func TestEncodeXLIFF_ShouldRoundTripWithDecodeXLIFF(t *testing.T) {
+	tests := []struct {
+		version         string
+		expectedContent string
+	}{
+		{XLIFF12, `<trans-unit id="home.title" resname="home.title">`},
+		{XLIFF20, `<unit id="home.title" name="home.title">`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			data, err := EncodeXLIFF(tt.version, "en", "de", xliffSources, xliffTargets)
+			assert.NoError(t, err)
+			assert.Contains(t, string(data), tt.expectedContent)
+
+			document, err := DecodeXLIFF(data)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.version, document.Version)
+			assert.Equal(t, "en", document.SourceLanguage)
+			assert.Equal(t, "de", document.TargetLanguage)
+			assert.Len(t, document.Units, 2)
+
+			greeting, title := document.Units[0], document.Units[1]
+			assert.Equal(t, "home.greeting", greeting.ID)
+			assert.Equal(t, "Hello <b>you</b> & welcome", greeting.Source)
+			assert.False(t, greeting.IsTranslated())
+			assert.Equal(t, "home.title", title.ID)
+			assert.Equal(t, "Startseite", title.Target)
+			assert.True(t, title.IsTranslated())
+		})
+	}
+}
+
+func TestDecodeXLIFF_WhenTargetHasInlineMarkup_ShouldFlattenToText(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file original="app" source-language="en" target-language="fi" datatype="plaintext">
+    <body>
+      <trans-unit id="1" resname="welcome">
+        <source>Welcome <g id="1">home</g></source>
+        <target state="final">Tervetuloa <g id="1">kotiin</g></target>
+        <note>shown on the landing page</note>
+      </trans-unit>
+      <trans-unit id="2">
+        <source>Bye</source>
+        <target state="needs-review-translation">Hei hei</target>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>`
+
+	document, err := DecodeXLIFF([]byte(data))
+
+	assert.NoError(t, err)
+	assert.Len(t, document.Units, 2)
+	assert.Equal(t, "welcome", document.Units[0].ID)
+	assert.Equal(t, "Tervetuloa kotiin", document.Units[0].Target)
+	assert.Equal(t, "shown on the landing page", document.Units[0].Note)
+	assert.True(t, document.Units[0].IsTranslated())
+	assert.Equal(t, "2", document.Units[1].ID)
+	assert.False(t, document.Units[1].IsTranslated())
+}
+
+func TestXLIFFFormatter_ShouldRoundTripStatus(t *testing.T) {
+	sources := []models.Resource{
+		{Key: "approved", LanguageCode: "en", Text: "Approved"},
+		{Key: "machine", LanguageCode: "en", Text: "Machine"},
+		{Key: "reviewed", LanguageCode: "en", Text: "Reviewed"},
+		{Key: "untranslated", LanguageCode: "en", Text: "Untranslated"},
+	}
+	targets := []models.Resource{
+		{Key: "approved", LanguageCode: "de", Text: "Genehmigt", Status: models.StatusApproved},
+		{Key: "machine", LanguageCode: "de", Text: "Maschine", Status: models.StatusMachine},
+		{Key: "reviewed", LanguageCode: "de", Text: "Geprüft", Status: models.StatusReviewed},
+		{Key: "untranslated", LanguageCode: "de", Text: "Unübersetzt", Status: models.StatusUntranslated},
+	}
+	tests := []struct {
+		version         string
+		expectedMachine string
+		expectedStatus  map[string]string
+	}{
+		{XLIFF12, `state="translated" state-qualifier="mt-suggestion"`,
+			map[string]string{"approved": models.StatusApproved, "machine": models.StatusMachine, "reviewed": models.StatusReviewed, "untranslated": ""}},
+		{XLIFF20, `state="translated"`,
+			map[string]string{"approved": models.StatusApproved, "machine": "", "reviewed": models.StatusReviewed, "untranslated": ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			formatter := XLIFFFormatter{}
+			options := models.FormatOptions{LanguageCodes: []string{"en", "de"}, Version: tt.version}
+			file, err := formatter.Encode(append(sources, targets...), options)
+			assert.NoError(t, err)
+			assert.Contains(t, string(file.Data), tt.expectedMachine)
+
+			decoded, err := formatter.Decode(file.Data, options)
+
+			assert.NoError(t, err)
+			assert.Len(t, decoded, 4)
+			for _, item := range decoded {
+				assert.Empty(t, item.SkipReason)
+				assert.Equal(t, tt.expectedStatus[item.Resource.Key], item.Resource.Status, item.Resource.Key)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package formats
+
+import (
+	"errors"
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EncodeRailsYAML renders resources as a Rails locale file, with the language as root key and the keys nested on dots:
+// "home.title" in German becomes de: { home: { title: ... } }. Resources of many languages are written under each language.
+func EncodeRailsYAML(resources []models.Resource) ([]byte, error) {
+	root := map[string]any{}
+	for _, resource := range resources {
+		language, exists := root[resource.LanguageCode]
+		if !exists {
+			language = map[string]any{}
+			root[resource.LanguageCode] = language
+		}
+
+		if err := setNested(language.(map[string]any), strings.Split(resource.Key, "."), resource.Text); err != nil {
+			return nil, fmt.Errorf("key '%v' can't be nested: %v", resource.Key, err)
+		}
+	}
+
+	return yaml.Marshal(root)
+}
+
+// DecodeRailsYAML reads a Rails locale file, every root key is a language and nested keys are joined with dots.
+// Sequences are keyed by index (see PluralKey).
+func DecodeRailsYAML(data []byte) (map[string][]Entry, error) {
+	var root map[string]any
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if len(root) == 0 {
+		return nil, errors.New("no languages found")
+	}
+
+	results := map[string][]Entry{}
+	for language, value := range root {
+		var entries []Entry
+		flattenYAML("", value, &entries)
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+		results[language] = entries
+	}
+
+	return results, nil
+}
+
+func flattenYAML(prefix string, value any, entries *[]Entry) {
+	switch value := value.(type) {
+	case string:
+		*entries = append(*entries, Entry{Key: prefix, Text: value})
+	case map[string]any:
+		for key, child := range value {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenYAML(key, child, entries)
+		}
+	case []any:
+		for i, child := range value {
+			flattenYAML(PluralKey(prefix, strconv.Itoa(i)), child, entries)
+		}
+	case nil:
+		*entries = append(*entries, Entry{Key: prefix})
+	default:
+		*entries = append(*entries, Entry{Key: prefix, SkipReason: fmt.Sprintf("value '%v' is not a text", value)})
+	}
+}
+
+type RailsYAMLFormatter struct{}
+
+var _ contracts.ResourceFormatter = (*RailsYAMLFormatter)(nil)
+
+func (f *RailsYAMLFormatter) Encode(resources []models.Resource, options models.FormatOptions) (models.EncodedFile, error) {
+	var selected []models.Resource
+	for _, languageCode := range options.LanguageCodes {
+		selected = append(selected, resourcesOf(resources, languageCode)...)
+	}
+
+	data, err := EncodeRailsYAML(selected)
+	if err != nil {
+		return models.EncodedFile{}, err
+	}
+
+	return models.EncodedFile{FileName: strings.Join(options.LanguageCodes, "_") + ".yml", ContentType: "application/yaml; charset=utf-8", Data: data}, nil
+}
+
+// Decode imports every language of the file, unless languages are set in the options.
+func (f *RailsYAMLFormatter) Decode(data []byte, options models.FormatOptions) ([]models.DecodedResource, error) {
+	languages, err := DecodeRailsYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid yaml file: %v", err.Error())
+	}
+
+	locales := make([]string, 0, len(languages))
+	for locale := range languages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	results := []models.DecodedResource{}
+	for _, locale := range locales {
+		languageCode := NormalizeLanguageCode(locale)
+		if len(options.LanguageCodes) > 0 && !containsString(options.LanguageCodes, languageCode) {
+			continue
+		}
+		results = append(results, toDecodedResources(languages[locale], languageCode)...)
+	}
+
+	return results, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package formats
+
+import (
+	"gotranslate/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeRailsYAML_ShouldNestKeysUnderLanguage(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "home.title", LanguageCode: "de", Text: "Startseite"},
+		{Key: "home.title", LanguageCode: "en", Text: "Home"},
+	}
+
+	data, err := EncodeRailsYAML(resources)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "de:\n    home:\n        title: Startseite\nen:\n    home:\n        title: Home\n", string(data))
+}
+
+func TestDecodeRailsYAML_ShouldFlattenKeysPerLanguage(t *testing.T) {
+	data := `de:
+  home:
+    title: Startseite
+    tabs:
+      - Eins
+      - Zwei
+  count: 3
+`
+
+	languages, err := DecodeRailsYAML([]byte(data))
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]Entry{"de": {
+		{Key: "count", SkipReason: "value '3' is not a text"},
+		{Key: "home.tabs#0", Text: "Eins"},
+		{Key: "home.tabs#1", Text: "Zwei"},
+		{Key: "home.title", Text: "Startseite"},
+	}}, languages)
+}
@@ -0,0 +1,49 @@
+package messages
+
+import (
+	"context"
+	"gotranslate/core/contracts"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// batchGroup translates and saves batches at the same time, up to the concurrency of the translator.
+// Once a batch fails the batches that didn't start yet are skipped, the failed job translates them when it's resumed.
+type batchGroup struct {
+	group *errgroup.Group
+	ctx   context.Context
+}
+
+func newBatchGroup(translator contracts.Translator) *batchGroup {
+	group, ctx := errgroup.WithContext(context.Background())
+	group.SetLimit(concurrencyOf(translator))
+	return &batchGroup{group: group, ctx: ctx}
+}
+
+// concurrencyOf returns how many batches the translator can translate at the same time, one unless it's set.
+func concurrencyOf(translator contracts.Translator) int {
+	if concurrent, ok := translator.(contracts.ConcurrentTranslator); ok && concurrent.Concurrency() > 0 {
+		return concurrent.Concurrency()
+	}
+	return 1
+}
+
+// run starts the batch when there's a free slot, blocking until then. It returns false when a batch already failed.
+func (b *batchGroup) run(translateBatch func() error) bool {
+	if b.ctx.Err() != nil {
+		return false
+	}
+
+	b.group.Go(func() error {
+		if b.ctx.Err() != nil {
+			return nil
+		}
+		return translateBatch()
+	})
+	return true
+}
+
+// wait returns the error of the first batch that failed after every started batch finished.
+func (b *batchGroup) wait() error {
+	return b.group.Wait()
+}
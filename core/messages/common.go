@@ -6,5 +6,7 @@ import "gotranslate/core/contracts"
 func GetMessageHandlers(repo contracts.ResoureRepository, translator contracts.Translator) map[string]contracts.MessageHandler {
 	return map[string]contracts.MessageHandler{
 		(&TranslateLanguageMessage{}).GetType(): &TranslateLanguageHandler{Repo: repo, Translator: translator},
+		(&FillGapsMessage{}).GetType():          &FillGapsHandler{Repo: repo, Translator: translator},
+		(&RetranslateMessage{}).GetType():       &RetranslateHandler{Repo: repo, Translator: translator},
 	}
 }
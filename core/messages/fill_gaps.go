@@ -0,0 +1,59 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"gotranslate/core/contracts"
+)
+
+// FillGapsMessage translates only the keys of the source language that are missing in the target language.
+type FillGapsMessage struct {
+	Type           string
+	JobID          int64
+	Project        string
+	Username       string
+	SourceLanguage string
+	TargetLanguage string
+}
+
+var _ contracts.BaseMessage = (*FillGapsMessage)(nil)
+
+func (m *FillGapsMessage) GetType() string {
+	return "FillGaps"
+}
+
+func (m *FillGapsMessage) SetType() {
+	m.Type = m.GetType()
+}
+
+type FillGapsHandler struct {
+	Repo       contracts.ResoureRepository
+	Translator contracts.Translator
+}
+
+func (h *FillGapsHandler) HandleMessage(messageBody map[string]interface{}) error {
+	data, err := json.Marshal(messageBody)
+	if err != nil {
+		return errors.New("invalid message body")
+	}
+
+	var msg FillGapsMessage
+	err = json.Unmarshal(data, &msg)
+	if err != nil {
+		return errors.New("invalid message type")
+	}
+
+	return ConsumeFillGaps(&msg, h.Repo, h.Translator)
+}
+
+// ConsumeFillGaps translates the missing keys of the target language the same way as a TranslateLanguageMessage,
+// which already keeps the existing translations.
+func ConsumeFillGaps(msg *FillGapsMessage, repo contracts.ResoureRepository, translator contracts.Translator) error {
+	return ConsumeTranslation(&TranslateLanguageMessage{
+		JobID:          msg.JobID,
+		Project:        msg.Project,
+		Username:       msg.Username,
+		SourceLanguage: msg.SourceLanguage,
+		TargetLanguage: msg.TargetLanguage,
+	}, repo, translator)
+}
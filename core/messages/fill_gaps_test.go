@@ -0,0 +1,148 @@
+package messages
+
+import (
+	"gotranslate/core/translators"
+	"gotranslate/models"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// resourceRepositoryStub keeps resources in memory and records the upserts, batches can be saved concurrently
+type resourceRepositoryStub struct {
+	jobRepositoryStub
+	mu        sync.Mutex
+	resources []models.Resource
+	upserts   [][]models.Resource
+}
+
+func (r *resourceRepositoryStub) GetResourcesByLanguageCode(project, languageCode string) ([]models.Resource, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.resourcesOf(project, languageCode), nil
+}
+
+func (r *resourceRepositoryStub) resourcesOf(project, languageCode string) []models.Resource {
+	results := []models.Resource{}
+	for _, resource := range r.resources {
+		if resource.Project == project && resource.LanguageCode == languageCode {
+			results = append(results, resource)
+		}
+	}
+	return results
+}
+
+func (r *resourceRepositoryStub) GetResourcesPage(project, languageCode, afterKey string, limit int) ([]models.Resource, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	resources := r.resourcesOf(project, languageCode)
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Key < resources[j].Key })
+
+	results := []models.Resource{}
+	for _, resource := range resources {
+		if resource.Key > afterKey && len(results) < limit {
+			results = append(results, resource)
+		}
+	}
+	return results, nil
+}
+
+func (r *resourceRepositoryStub) ExistingLanguageCodes(project string) ([]models.LanguageResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := map[string]int{}
+	for _, resource := range r.resources {
+		if resource.Project == project {
+			counts[resource.LanguageCode]++
+		}
+	}
+
+	results := []models.LanguageResult{}
+	for languageCode, count := range counts {
+		results = append(results, models.LanguageResult{LanguageCode: languageCode, Count: count})
+	}
+	return results, nil
+}
+
+func (r *resourceRepositoryStub) UpsertResources(policy models.ConflictPolicy, username string, resources ...models.Resource) (models.UpsertResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.upserts = append(r.upserts, resources)
+	result := models.UpsertResult{}
+	for _, resource := range resources {
+		existing := -1
+		for i, current := range r.resources {
+			if current.Project == resource.Project && current.Key == resource.Key && current.LanguageCode == resource.LanguageCode {
+				existing = i
+			}
+		}
+		if existing < 0 {
+			r.resources = append(r.resources, resource)
+			result.Created++
+		} else if policy == models.ConflictOverwrite || policy == models.ConflictStale {
+			r.resources[existing] = resource
+			result.Updated++
+		} else {
+			result.Skipped++
+		}
+	}
+	return result, nil
+}
+
+func TestConsumeFillGaps_ShouldOnlyTranslateMissingKeys(t *testing.T) {
+	// arrange
+	repo := &resourceRepositoryStub{resources: []models.Resource{
+		{Project: models.DefaultProject, Key: "key1", LanguageCode: "en", Text: "text 1"},
+		{Project: models.DefaultProject, Key: "key2", LanguageCode: "en", Text: "text 2"},
+		{Project: models.DefaultProject, Key: "key2", LanguageCode: "es", Text: "texto 2"},
+		{Project: "other", Key: "key3", LanguageCode: "en", Text: "text 3"},
+	}}
+	msg := FillGapsMessage{SourceLanguage: "en", TargetLanguage: "es", Username: "tester"}
+
+	// act
+	err := ConsumeFillGaps(&msg, repo, &translators.Fake{})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, repo.upserts, 1)
+	assert.Len(t, repo.upserts[0], 1)
+	assert.Equal(t, "key1", repo.upserts[0][0].Key)
+	assert.Equal(t, "es", repo.upserts[0][0].LanguageCode)
+	assert.Equal(t, models.DefaultProject, repo.upserts[0][0].Project)
+}
+
+func TestConsumeFillGaps_WhenManyBatches_ShouldSaveAllOfThem(t *testing.T) {
+	// arrange
+	repo := &resourceRepositoryStub{}
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		repo.resources = append(repo.resources, models.Resource{Project: models.DefaultProject, Key: key, LanguageCode: "en", Text: key})
+	}
+	translator := &translators.Fake{}
+	msg := FillGapsMessage{SourceLanguage: "en", TargetLanguage: "es"}
+
+	// act
+	err := ConsumeFillGaps(&msg, repo, translator)
+
+	// assert
+	assert.NoError(t, err)
+	saved, _ := repo.GetResourcesByLanguageCode(models.DefaultProject, "es")
+	assert.Len(t, saved, 7)
+}
+
+func TestConsumeFillGaps_WhenNothingIsMissing_ShouldNotTranslate(t *testing.T) {
+	// arrange
+	repo := &resourceRepositoryStub{resources: []models.Resource{
+		{Project: models.DefaultProject, Key: "key1", LanguageCode: "en", Text: "text 1"},
+		{Project: models.DefaultProject, Key: "key1", LanguageCode: "es", Text: "texto 1"},
+	}}
+	msg := FillGapsMessage{SourceLanguage: "en", TargetLanguage: "es"}
+
+	// act
+	err := ConsumeFillGaps(&msg, repo, &translators.Fake{})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Empty(t, repo.upserts)
+}
@@ -0,0 +1,97 @@
+package messages
+
+import (
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"log"
+	"sync"
+)
+
+// jobProgress saves the progress of the job of a message, messages without a job aren't tracked.
+// A job that was already running, or that failed and is retried, continues counting from where it stopped when the
+// message is handled again.
+// Failing to save progress is logged but doesn't stop the work. Batches translated concurrently share the progress.
+type jobProgress struct {
+	mu      sync.Mutex
+	repo    contracts.ResoureRepository
+	job     models.Job
+	tracked bool
+	resumed bool
+}
+
+func newJobProgress(repo contracts.ResoureRepository, jobID int64) *jobProgress {
+	progress := &jobProgress{repo: repo}
+	if jobID == 0 {
+		return progress
+	}
+
+	job, found, err := repo.GetJob(jobID)
+	if err != nil {
+		log.Printf("unable to load job %d: %v", jobID, err)
+	} else if !found {
+		log.Printf("job %d not found, its progress won't be saved", jobID)
+	}
+	progress.job, progress.tracked = job, err == nil && found
+	progress.resumed = progress.tracked && (job.Status == models.JobRunning || job.Status == models.JobFailed)
+	return progress
+}
+
+// start sets the batches left to translate, they're added to the batches done when the job is resumed.
+func (p *jobProgress) start(batchesLeft int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.resumed {
+		p.job.BatchesTotal = p.job.BatchesDone + batchesLeft
+	} else {
+		p.job.BatchesTotal, p.job.BatchesDone, p.job.Created, p.job.Skipped = batchesLeft, 0, 0, 0
+	}
+	p.job.Status, p.job.Error = models.JobRunning, ""
+	p.save()
+}
+
+// batchDone counts a saved batch, the total grows when it was estimated too low.
+func (p *jobProgress) batchDone(result models.UpsertResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.job.BatchesDone++
+	if p.job.BatchesDone > p.job.BatchesTotal {
+		p.job.BatchesTotal = p.job.BatchesDone
+	}
+	p.count(result)
+	p.save()
+}
+
+// add counts the saved translations as created, including the ones that replaced stale translations.
+func (p *jobProgress) add(result models.UpsertResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.count(result)
+}
+
+func (p *jobProgress) count(result models.UpsertResult) {
+	p.job.Created += result.Created + result.Updated
+	p.job.Skipped += result.Skipped
+}
+
+func (p *jobProgress) finish(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err != nil {
+		p.job.Status, p.job.Error = models.JobFailed, err.Error()
+	} else {
+		p.job.Status = models.JobCompleted
+	}
+	p.save()
+}
+
+func (p *jobProgress) save() {
+	if !p.tracked {
+		return
+	}
+	if err := p.repo.UpdateJob(p.job); err != nil {
+		log.Printf("unable to save progress of job %d: %v", p.job.ID, err)
+	}
+}
@@ -0,0 +1,109 @@
+package messages
+
+import (
+	"errors"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// jobRepositoryStub keeps jobs in memory, the other repository methods aren't used
+type jobRepositoryStub struct {
+	contracts.ResoureRepository
+	jobs    map[int64]models.Job
+	updates []models.Job
+}
+
+func (r *jobRepositoryStub) GetJob(id int64) (models.Job, bool, error) {
+	job, found := r.jobs[id]
+	return job, found, nil
+}
+
+func (r *jobRepositoryStub) UpdateJob(job models.Job) error {
+	r.jobs[job.ID] = job
+	r.updates = append(r.updates, job)
+	return nil
+}
+
+func TestJobProgress_WhenCompleted_ShouldSaveEveryStep(t *testing.T) {
+	repo := &jobRepositoryStub{jobs: map[int64]models.Job{7: {ID: 7, Status: models.JobQueued}}}
+	progress := newJobProgress(repo, 7)
+
+	progress.start(2)
+	progress.batchDone(models.UpsertResult{Created: 3})
+	progress.batchDone(models.UpsertResult{Created: 2, Skipped: 1})
+	progress.finish(nil)
+
+	assert.Len(t, repo.updates, 4)
+	assert.Equal(t, models.JobRunning, repo.updates[1].Status)
+	assert.Equal(t, 1, repo.updates[1].BatchesDone)
+	job := repo.jobs[7]
+	assert.Equal(t, models.JobCompleted, job.Status)
+	assert.Equal(t, 2, job.BatchesDone)
+	assert.Equal(t, 2, job.BatchesTotal)
+	assert.Equal(t, int64(5), job.Created)
+	assert.Equal(t, int64(1), job.Skipped)
+}
+
+func TestJobProgress_WhenFailed_ShouldSaveError(t *testing.T) {
+	repo := &jobRepositoryStub{jobs: map[int64]models.Job{7: {ID: 7, Status: models.JobQueued}}}
+	progress := newJobProgress(repo, 7)
+
+	progress.finish(errors.New("translator is down"))
+
+	assert.Equal(t, models.JobFailed, repo.jobs[7].Status)
+	assert.Equal(t, "translator is down", repo.jobs[7].Error)
+}
+
+func TestJobProgress_WhenMessageHasNoJob_ShouldNotSave(t *testing.T) {
+	repo := &jobRepositoryStub{jobs: map[int64]models.Job{}}
+	progress := newJobProgress(repo, 0)
+
+	progress.start(1)
+	progress.finish(nil)
+
+	assert.Empty(t, repo.updates)
+}
+
+func TestJobProgress_WhenJobWasRunning_ShouldContinueCounting(t *testing.T) {
+	repo := &jobRepositoryStub{jobs: map[int64]models.Job{7: {ID: 7, Status: models.JobRunning, BatchesDone: 3, BatchesTotal: 5, Created: 15}}}
+	progress := newJobProgress(repo, 7)
+
+	progress.start(2)
+	progress.batchDone(models.UpsertResult{Created: 5})
+
+	job := repo.jobs[7]
+	assert.Equal(t, 4, job.BatchesDone)
+	assert.Equal(t, 5, job.BatchesTotal)
+	assert.Equal(t, int64(20), job.Created)
+}
+
+func TestJobProgress_WhenFailedJobIsRetried_ShouldContinueCounting(t *testing.T) {
+	repo := &jobRepositoryStub{jobs: map[int64]models.Job{1: {ID: 1, Status: models.JobFailed, Error: "translator unavailable", BatchesDone: 2, BatchesTotal: 4, Created: 10, Skipped: 1}}}
+
+	progress := newJobProgress(repo, 1)
+	progress.start(2)
+	progress.batchDone(models.UpsertResult{Created: 5})
+	progress.finish(nil)
+
+	job := repo.jobs[1]
+	assert.Equal(t, models.JobCompleted, job.Status)
+	assert.Empty(t, job.Error)
+	assert.Equal(t, 3, job.BatchesDone)
+	assert.Equal(t, 4, job.BatchesTotal)
+	assert.Equal(t, int64(15), job.Created)
+	assert.Equal(t, int64(1), job.Skipped)
+}
+
+func TestJobProgress_WhenMoreBatchesThanEstimated_ShouldGrowTotal(t *testing.T) {
+	repo := &jobRepositoryStub{jobs: map[int64]models.Job{7: {ID: 7, Status: models.JobQueued}}}
+	progress := newJobProgress(repo, 7)
+
+	progress.start(1)
+	progress.batchDone(models.UpsertResult{Created: 5})
+	progress.batchDone(models.UpsertResult{Created: 1})
+
+	assert.Equal(t, 2, repo.jobs[7].BatchesTotal)
+}
@@ -0,0 +1,151 @@
+package messages
+
+import (
+	"encoding/json"
+	"errors"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"log"
+)
+
+// RetranslateMessage translates again the target resources whose source text changed, only for Keys when they're set.
+type RetranslateMessage struct {
+	Type           string
+	JobID          int64
+	Project        string
+	Username       string
+	SourceLanguage string
+	TargetLanguage string
+	Keys           []string
+}
+
+var _ contracts.BaseMessage = (*RetranslateMessage)(nil)
+
+func (m *RetranslateMessage) GetType() string {
+	return "Retranslate"
+}
+
+func (m *RetranslateMessage) SetType() {
+	m.Type = m.GetType()
+}
+
+type RetranslateHandler struct {
+	Repo       contracts.ResoureRepository
+	Translator contracts.Translator
+}
+
+func (h *RetranslateHandler) HandleMessage(messageBody map[string]interface{}) error {
+	data, err := json.Marshal(messageBody)
+	if err != nil {
+		return errors.New("invalid message body")
+	}
+
+	var msg RetranslateMessage
+	err = json.Unmarshal(data, &msg)
+	if err != nil {
+		return errors.New("invalid message type")
+	}
+
+	return ConsumeRetranslate(&msg, h.Repo, h.Translator)
+}
+
+// ConsumeRetranslate overwrites the stale machine translations, reviewed and approved ones are skipped and keep
+// showing as stale until someone reviews them. The progress is saved to the job of the message.
+func ConsumeRetranslate(msg *RetranslateMessage, repo contracts.ResoureRepository, translator contracts.Translator) error {
+	progress := newJobProgress(repo, msg.JobID)
+	err := retranslate(msg, repo, translator, progress)
+	progress.finish(err)
+	return err
+}
+
+// retranslate reads both languages a page at a time and translates the stale translations in batches. They're saved
+// with models.ConflictStale, so translations reviewed or translated again while the job runs aren't overwritten.
+func retranslate(msg *RetranslateMessage, repo contracts.ResoureRepository, translator contracts.Translator, progress *jobProgress) error {
+	if msg.SourceLanguage == "" || msg.TargetLanguage == "" {
+		return errors.New("languages not set correctly")
+	}
+
+	project := msg.Project
+	if project == "" {
+		project = models.DefaultProject
+	}
+
+	// the stale translations are only known once both languages are read, the total grows with every batch
+	progress.start(0)
+	batches := newBatchGroup(translator)
+	translate := func(batch []models.Resource) bool {
+		return batches.run(func() error {
+			return translateBatch(repo, translator, progress, models.ConflictStale, project, msg.TargetLanguage, msg.Username, batch)
+		})
+	}
+
+	needsReview, err := staleBatches(repo, project, msg.SourceLanguage, msg.TargetLanguage, msg.Keys, translator.GetBatchLimit(), translate)
+	if needsReview > 0 {
+		log.Printf("%d stale translations of %v in project %v need review, they weren't re-translated", needsReview, msg.TargetLanguage, project)
+		progress.add(models.UpsertResult{Skipped: needsReview})
+	}
+	if err != nil {
+		batches.wait()
+		return err
+	}
+	return batches.wait()
+}
+
+// staleBatches calls translate with batches of the source resources whose translation is stale and can be
+// re-translated, only for keys when they're set, until there are none left or translate returns false.
+// It returns the number of stale translations that need review instead.
+func staleBatches(repo contracts.ResoureRepository, project, sourceLanguage, targetLanguage string, keys []string, batchLimit int, translate func([]models.Resource) bool) (needsReview int64, err error) {
+	selectedKeys := map[string]bool{}
+	for _, key := range keys {
+		selectedKeys[key] = true
+	}
+
+	targets := &resourceCursor{repo: repo, project: project, languageCode: targetLanguage, pageSize: batchLimit}
+	var batch []models.Resource
+	afterKey := ""
+	for {
+		page, err := repo.GetResourcesPage(project, sourceLanguage, afterKey, batchLimit)
+		if err != nil {
+			return needsReview, err
+		}
+		if len(page) == 0 {
+			break
+		}
+		afterKey = page[len(page)-1].Key
+
+		var sources, translations []models.Resource
+		for _, resource := range page {
+			if len(selectedKeys) > 0 && !selectedKeys[resource.Key] {
+				continue
+			}
+			target, found, err := targets.find(resource.Key)
+			if err != nil {
+				return needsReview, err
+			}
+			if found {
+				sources = append(sources, resource)
+				translations = append(translations, target)
+			}
+		}
+
+		for _, stale := range models.FindStaleTranslations(sources, translations) {
+			if stale.NeedsReview {
+				needsReview++
+				continue
+			}
+
+			batch = append(batch, models.Resource{Project: project, Key: stale.Key, LanguageCode: sourceLanguage, Text: stale.SourceText})
+			if len(batch) == batchLimit {
+				if !translate(batch) {
+					return needsReview, nil
+				}
+				batch = nil
+			}
+		}
+	}
+
+	if len(batch) > 0 {
+		translate(batch)
+	}
+	return needsReview, nil
+}
@@ -0,0 +1,102 @@
+package messages
+
+import (
+	"gotranslate/core/translators"
+	"gotranslate/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumeRetranslate_ShouldOverwriteStaleMachineTranslations(t *testing.T) {
+	// arrange
+	project := models.DefaultProject
+	repo := &resourceRepositoryStub{resources: []models.Resource{
+		{Project: project, Key: "changed", LanguageCode: "en", Text: "new text"},
+		{Project: project, Key: "changed", LanguageCode: "es", Text: "texto viejo", Status: models.StatusMachine, SourceHash: models.SourceHash("old text")},
+		{Project: project, Key: "reviewed", LanguageCode: "en", Text: "new text"},
+		{Project: project, Key: "reviewed", LanguageCode: "es", Text: "texto revisado", Status: models.StatusReviewed, SourceHash: models.SourceHash("old text")},
+		{Project: project, Key: "same", LanguageCode: "en", Text: "same text"},
+		{Project: project, Key: "same", LanguageCode: "es", Text: "mismo texto", Status: models.StatusMachine, SourceHash: models.SourceHash("same text")},
+	}}
+	msg := RetranslateMessage{SourceLanguage: "en", TargetLanguage: "es"}
+
+	// act
+	err := ConsumeRetranslate(&msg, repo, &translators.Fake{})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, repo.upserts, 1)
+	assert.Len(t, repo.upserts[0], 1)
+	updated := repo.upserts[0][0]
+	assert.Equal(t, "changed", updated.Key)
+	assert.Equal(t, models.SourceHash("new text"), updated.SourceHash)
+	assert.Equal(t, models.StatusMachine, updated.Status)
+
+	targets, _ := repo.GetResourcesByLanguageCode(project, "es")
+	sources, _ := repo.GetResourcesByLanguageCode(project, "en")
+	stale := models.FindStaleTranslations(sources, targets)
+	assert.Len(t, stale, 1)
+	assert.Equal(t, "reviewed", stale[0].Key)
+	assert.Equal(t, "texto revisado", stale[0].Text)
+}
+
+func TestConsumeRetranslate_WhenKeysAreSet_ShouldOnlyRetranslateThem(t *testing.T) {
+	// arrange
+	project := models.DefaultProject
+	repo := &resourceRepositoryStub{resources: []models.Resource{
+		{Project: project, Key: "key1", LanguageCode: "en", Text: "new text"},
+		{Project: project, Key: "key1", LanguageCode: "es", Status: models.StatusMachine, SourceHash: models.SourceHash("old text")},
+		{Project: project, Key: "key2", LanguageCode: "en", Text: "new text"},
+		{Project: project, Key: "key2", LanguageCode: "es", Status: models.StatusMachine, SourceHash: models.SourceHash("old text")},
+	}}
+	msg := RetranslateMessage{SourceLanguage: "en", TargetLanguage: "es", Keys: []string{"key2"}}
+
+	// act
+	err := ConsumeRetranslate(&msg, repo, &translators.Fake{})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, repo.upserts, 1)
+	assert.Len(t, repo.upserts[0], 1)
+	assert.Equal(t, "key2", repo.upserts[0][0].Key)
+}
+
+func TestConsumeRetranslate_WhenManyPages_ShouldRetranslateEveryStaleTranslation(t *testing.T) {
+	// arrange
+	repo := &resourceRepositoryStub{}
+	for _, source := range sourceResources(12) {
+		repo.resources = append(repo.resources, source)
+		status := models.StatusMachine
+		if source.Key == "key07" {
+			status = models.StatusApproved
+		}
+		repo.resources = append(repo.resources, models.Resource{Project: source.Project, Key: source.Key, LanguageCode: "es", Status: status, SourceHash: models.SourceHash("old text")})
+	}
+	msg := RetranslateMessage{SourceLanguage: "en", TargetLanguage: "es"}
+
+	// act
+	err := ConsumeRetranslate(&msg, repo, &translators.Fake{})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, repo.upserts, 3)
+	var keys []string
+	for _, upsert := range repo.upserts {
+		for _, resource := range upsert {
+			keys = append(keys, resource.Key)
+		}
+	}
+	assert.Len(t, keys, 11)
+	assert.NotContains(t, keys, "key07")
+}
+
+func TestWithSourceHash_ShouldSetHashOfSourceWithSameKey(t *testing.T) {
+	sources := []models.Resource{{Key: "key1", LanguageCode: "en", Text: "text 1"}, {Key: "key2", LanguageCode: "en", Text: "text 2"}}
+	translated := []models.Resource{{Key: "key2", LanguageCode: "es"}, {Key: "key1", LanguageCode: "es"}}
+
+	results := withSourceHash(translated, sources)
+
+	assert.Equal(t, models.SourceHash("text 2"), results[0].SourceHash)
+	assert.Equal(t, models.SourceHash("text 1"), results[1].SourceHash)
+}
@@ -3,14 +3,15 @@ package messages
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
-	"gotranslate/batching"
 	"gotranslate/core/contracts"
 	"gotranslate/models"
 )
 
 type TranslateLanguageMessage struct {
 	Type           string
+	JobID          int64
+	Project        string
+	Username       string
 	SourceLanguage string
 	TargetLanguage string
 }
@@ -45,30 +46,26 @@ func (h *TranslateLanguageHandler) HandleMessage(messageBody map[string]interfac
 	return ConsumeTranslation(&msg, h.Repo, h.Translator)
 }
 
+// ConsumeTranslation translates the resources of the source language, saving the progress to the job of the message.
+// Texts with an approved translation in the translation memory reuse it instead of being machine translated.
 func ConsumeTranslation(msg *TranslateLanguageMessage, repo contracts.ResoureRepository, translator contracts.Translator) error {
+	progress := newJobProgress(repo, msg.JobID)
+	err := translateLanguage(msg, repo, translator, progress)
+	progress.finish(err)
+	return err
+}
+
+// translateLanguage keeps the translations that already exist, so running the same translation again doesn't
+// duplicate or overwrite them, and a translation that stopped halfway continues with the missing keys.
+func translateLanguage(msg *TranslateLanguageMessage, repo contracts.ResoureRepository, translator contracts.Translator, progress *jobProgress) error {
 	if msg.SourceLanguage == "" || msg.TargetLanguage == "" {
 		return errors.New("languages not set correctly")
 	}
 
-	existingResources, err := repo.GetResourcesByLanguageCode(msg.SourceLanguage)
-	if err != nil {
-		return err
-	}
-
-	if len(existingResources) == 0 {
-		return fmt.Errorf("no resources found for language %v", msg.SourceLanguage)
-	}
-
-	var newResources []models.Resource
-	batches := batching.SplitToBatches(existingResources, translator.GetBatchLimit())
-	for _, batch := range batches {
-		newResources, err = translator.TranslateResources(msg.TargetLanguage, batch)
-		if err != nil {
-			return err
-		}
+	project := msg.Project
+	if project == "" {
+		project = models.DefaultProject
 	}
 
-	repo.AddResources(newResources...)
-
-	return nil
+	return translateMissing(repo, translator, progress, project, msg.SourceLanguage, msg.TargetLanguage, msg.Username)
 }
@@ -31,9 +31,35 @@ func TestConsumeTranslation_ShouldSaveTranslatedResourcesToDb(t *testing.T) {
 
 	ConsumeTranslation(&msg, repo, &translator)
 
-	results, err := repo.GetResourcesByLanguageCode("es")
+	results, err := repo.GetResourcesByLanguageCode(models.DefaultProject, "es")
 	assert.NoError(t, err)
 	assert.Len(t, results, 3)
 	allResultsAreEs := slices.All(results, func(x models.Resource) bool { return x.LanguageCode == expectedLanguage })
 	assert.Truef(t, allResultsAreEs, "expected all results to have target language %v", expectedLanguage)
+	allResultsAreMachine := slices.All(results, func(x models.Resource) bool { return x.Status == models.StatusMachine })
+	assert.True(t, allResultsAreMachine, "expected translated resources to have the machine status")
+}
+
+func TestConsumeTranslation_WhenMessageHasJob_ShouldCompleteJob(t *testing.T) {
+	data := []models.Resource{
+		{Key: "key1", LanguageCode: "en", Text: "text 1"},
+		{Key: "key2", LanguageCode: "en", Text: "text 2"},
+	}
+	translator := translators.Fake{}
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := repository.NewResourceGorm(db)
+	repo.Init()
+	db.Create(data)
+	job, _ := repo.CreateJob(models.Job{Type: "TranslateLanguage", Project: models.DefaultProject, Status: models.JobQueued})
+	msg := TranslateLanguageMessage{JobID: job.ID, SourceLanguage: "en", TargetLanguage: "es"}
+
+	err := ConsumeTranslation(&msg, repo, &translator)
+
+	assert.NoError(t, err)
+	result, found, _ := repo.GetJob(job.ID)
+	assert.True(t, found)
+	assert.Equal(t, models.JobCompleted, result.Status)
+	assert.Equal(t, result.BatchesTotal, result.BatchesDone)
+	assert.Equal(t, int64(2), result.Created)
 }
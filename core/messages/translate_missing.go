@@ -0,0 +1,206 @@
+package messages
+
+import (
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+)
+
+// translateMissing translates the source resources that have no resource in the target language, reading both
+// languages a page at a time. Every batch is saved as soon as it's translated, so when the message is handled again
+// after a crash only the keys that are still missing are translated. Batches are translated concurrently when the
+// translator allows it.
+func translateMissing(repo contracts.ResoureRepository, translator contracts.Translator, progress *jobProgress, project, sourceLanguage, targetLanguage, username string) error {
+	languages, err := repo.ExistingLanguageCodes(project)
+	if err != nil {
+		return err
+	}
+
+	var sourceCount, targetCount int
+	for _, language := range languages {
+		if language.LanguageCode == sourceLanguage {
+			sourceCount = language.Count
+		} else if language.LanguageCode == targetLanguage {
+			targetCount = language.Count
+		}
+	}
+
+	if sourceCount == 0 {
+		return fmt.Errorf("no resources found for language %v", sourceLanguage)
+	}
+
+	batchLimit := translator.GetBatchLimit()
+	progress.start((max(sourceCount-targetCount, 0) + batchLimit - 1) / batchLimit)
+
+	batches := newBatchGroup(translator)
+	translate := func(batch []models.Resource) bool {
+		return batches.run(func() error {
+			return translateBatch(repo, translator, progress, models.ConflictKeep, project, targetLanguage, username, batch)
+		})
+	}
+
+	if err := missingBatches(repo, project, sourceLanguage, targetLanguage, batchLimit, translate); err != nil {
+		batches.wait()
+		return err
+	}
+	return batches.wait()
+}
+
+// missingBatches calls translate with batches of the source resources missing in the target language,
+// until there are none left or translate returns false.
+func missingBatches(repo contracts.ResoureRepository, project, sourceLanguage, targetLanguage string, batchLimit int, translate func([]models.Resource) bool) error {
+	targets := &resourceCursor{repo: repo, project: project, languageCode: targetLanguage, pageSize: batchLimit}
+	var batch []models.Resource
+	afterKey := ""
+	for {
+		page, err := repo.GetResourcesPage(project, sourceLanguage, afterKey, batchLimit)
+		if err != nil {
+			return err
+		}
+		if len(page) == 0 {
+			break
+		}
+		afterKey = page[len(page)-1].Key
+
+		for _, resource := range page {
+			exists, err := targets.contains(resource.Key)
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+
+			batch = append(batch, resource)
+			if len(batch) == batchLimit {
+				if !translate(batch) {
+					return nil
+				}
+				batch = nil
+			}
+		}
+	}
+
+	if len(batch) > 0 {
+		translate(batch)
+	}
+	return nil
+}
+
+// translateBatch translates the source resources and saves them with the policy in one transaction.
+func translateBatch(repo contracts.ResoureRepository, translator contracts.Translator, progress *jobProgress, policy models.ConflictPolicy, project, targetLanguage, username string, batch []models.Resource) error {
+	translated, err := translateWithMemory(repo, translator, targetLanguage, batch)
+	if err != nil {
+		return err
+	}
+
+	translated = withSourceHash(translated, batch)
+	for i := range translated {
+		translated[i].Project = project
+	}
+
+	result, err := repo.UpsertResources(policy, username, translated...)
+	if err != nil {
+		return err
+	}
+
+	progress.batchDone(result)
+	return nil
+}
+
+// translateWithMemory reuses the approved translations of the same source texts when the repository is a translation
+// memory, and only sends the other resources to the translator.
+func translateWithMemory(repo contracts.ResoureRepository, translator contracts.Translator, targetLanguage string, batch []models.Resource) ([]models.Resource, error) {
+	memory, ok := repo.(contracts.TranslationMemory)
+	if !ok || len(batch) == 0 {
+		return translator.TranslateResources(targetLanguage, batch)
+	}
+
+	var texts []string
+	for _, resource := range batch {
+		texts = append(texts, resource.Text)
+	}
+
+	matches, err := memory.FindExactMatches(batch[0].LanguageCode, targetLanguage, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	var results, toTranslate []models.Resource
+	for _, resource := range batch {
+		if text, found := matches[resource.Text]; found && resource.Text != "" {
+			results = append(results, models.Resource{Key: resource.Key, LanguageCode: targetLanguage, Text: text, Status: models.StatusMachine, Provider: models.ProviderMemory})
+		} else {
+			toTranslate = append(toTranslate, resource)
+		}
+	}
+
+	if len(toTranslate) == 0 {
+		return results, nil
+	}
+
+	translated, err := translator.TranslateResources(targetLanguage, toTranslate)
+	if err != nil {
+		return nil, err
+	}
+	return append(results, translated...), nil
+}
+
+// withSourceHash sets the hash of the source text each translation was made from, to find them when the source changes.
+func withSourceHash(translated, sourceResources []models.Resource) []models.Resource {
+	sourceTexts := map[string]string{}
+	for _, resource := range sourceResources {
+		sourceTexts[resource.Key] = resource.Text
+	}
+
+	for i, resource := range translated {
+		if sourceText, exists := sourceTexts[resource.Key]; exists {
+			translated[i].SourceHash = models.SourceHash(sourceText)
+		}
+	}
+	return translated
+}
+
+// resourceCursor reads the keys of a language a page at a time.
+type resourceCursor struct {
+	repo         contracts.ResoureRepository
+	project      string
+	languageCode string
+	pageSize     int
+	page         []models.Resource
+	afterKey     string
+	done         bool
+}
+
+// contains reports whether the language has a resource with the key, keys must be checked in increasing order.
+func (c *resourceCursor) contains(key string) (bool, error) {
+	_, found, err := c.find(key)
+	return found, err
+}
+
+// find returns the resource of the language with the key, keys must be looked up in increasing order.
+func (c *resourceCursor) find(key string) (models.Resource, bool, error) {
+	for {
+		for len(c.page) > 0 && c.page[0].Key < key {
+			c.page = c.page[1:]
+		}
+		if len(c.page) > 0 {
+			return c.page[0], c.page[0].Key == key, nil
+		}
+		if c.done {
+			return models.Resource{}, false, nil
+		}
+
+		page, err := c.repo.GetResourcesPage(c.project, c.languageCode, c.afterKey, c.pageSize)
+		if err != nil {
+			return models.Resource{}, false, err
+		}
+		if len(page) < c.pageSize {
+			c.done = true
+		}
+		if len(page) > 0 {
+			c.afterKey = page[len(page)-1].Key
+		}
+		c.page = page
+	}
+}
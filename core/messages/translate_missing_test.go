@@ -0,0 +1,266 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/core/translators"
+	"gotranslate/models"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingTranslator fails after translating failAfter batches
+type failingTranslator struct {
+	translators.Fake
+	failAfter int
+	batches   int
+}
+
+func (f *failingTranslator) TranslateResources(targetLanguageCode string, resources []models.Resource) ([]models.Resource, error) {
+	if f.batches >= f.failAfter {
+		return nil, errors.New("translator is down")
+	}
+	f.batches++
+	return f.Fake.TranslateResources(targetLanguageCode, resources)
+}
+
+// concurrentTranslator records how many batches it translated at the same time, failing the batch of failKey
+type concurrentTranslator struct {
+	translators.Fake
+	concurrency int
+	failKey     string
+	mu          sync.Mutex
+	running     int
+	maxRunning  int
+	batches     int
+}
+
+func (c *concurrentTranslator) Concurrency() int {
+	return c.concurrency
+}
+
+func (c *concurrentTranslator) TranslateResources(targetLanguageCode string, resources []models.Resource) ([]models.Resource, error) {
+	c.mu.Lock()
+	c.running++
+	c.batches++
+	c.maxRunning = max(c.maxRunning, c.running)
+	c.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	c.mu.Lock()
+	c.running--
+	c.mu.Unlock()
+
+	for _, resource := range resources {
+		if resource.Key == c.failKey {
+			return nil, errors.New("translator is down")
+		}
+	}
+
+	results := []models.Resource{}
+	for _, resource := range resources {
+		results = append(results, models.Resource{Key: resource.Key, Text: "translated " + resource.Text, LanguageCode: targetLanguageCode, Status: models.StatusMachine})
+	}
+	return results, nil
+}
+
+func sourceResources(count int) []models.Resource {
+	var resources []models.Resource
+	for i := 0; i < count; i++ {
+		resources = append(resources, models.Resource{Project: models.DefaultProject, Key: fmt.Sprintf("key%02d", i), LanguageCode: "en", Text: fmt.Sprintf("text %d", i)})
+	}
+	return resources
+}
+
+func TestConsumeTranslation_WhenManyBatches_ShouldSaveEveryBatch(t *testing.T) {
+	// arrange
+	repo := &resourceRepositoryStub{resources: sourceResources(12)}
+	msg := TranslateLanguageMessage{SourceLanguage: "en", TargetLanguage: "es"}
+
+	// act
+	err := ConsumeTranslation(&msg, repo, &translators.Fake{})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, repo.upserts, 3)
+	saved, _ := repo.GetResourcesByLanguageCode(models.DefaultProject, "es")
+	assert.Len(t, saved, 12)
+	for _, resource := range saved {
+		assert.NotEmpty(t, resource.SourceHash)
+	}
+}
+
+func TestConsumeTranslation_WhenTranslatorFailsHalfway_ShouldKeepSavedBatchesAndResume(t *testing.T) {
+	// arrange
+	repo := &resourceRepositoryStub{
+		resources:         sourceResources(12),
+		jobRepositoryStub: jobRepositoryStub{jobs: map[int64]models.Job{7: {ID: 7, Status: models.JobQueued}}},
+	}
+	msg := TranslateLanguageMessage{JobID: 7, SourceLanguage: "en", TargetLanguage: "es"}
+
+	// act
+	err := ConsumeTranslation(&msg, repo, &failingTranslator{failAfter: 1})
+	saved, _ := repo.GetResourcesByLanguageCode(models.DefaultProject, "es")
+	savedBeforeRetry := len(saved)
+	crashed := repo.jobs[7] // as if the worker stopped before marking the job as failed
+	crashed.Status = models.JobRunning
+	repo.jobs[7] = crashed
+	retryTranslator := &failingTranslator{failAfter: 10}
+	retryErr := ConsumeTranslation(&msg, repo, retryTranslator)
+
+	// assert
+	assert.Error(t, err)
+	assert.Equal(t, 5, savedBeforeRetry)
+	assert.NoError(t, retryErr)
+	assert.Equal(t, 2, retryTranslator.batches, "only the missing keys should be translated again")
+	saved, _ = repo.GetResourcesByLanguageCode(models.DefaultProject, "es")
+	assert.Len(t, saved, 12)
+	job := repo.jobs[7]
+	assert.Equal(t, models.JobCompleted, job.Status)
+	assert.Equal(t, 3, job.BatchesDone)
+	assert.Equal(t, 3, job.BatchesTotal)
+	assert.Equal(t, int64(12), job.Created)
+}
+
+func TestResourceCursor_ShouldFindKeysAcrossPages(t *testing.T) {
+	repo := &resourceRepositoryStub{resources: []models.Resource{
+		{Project: models.DefaultProject, Key: "a", LanguageCode: "es"},
+		{Project: models.DefaultProject, Key: "c", LanguageCode: "es"},
+		{Project: models.DefaultProject, Key: "d", LanguageCode: "es"},
+		{Project: models.DefaultProject, Key: "f", LanguageCode: "es"},
+	}}
+	cursor := &resourceCursor{repo: repo, project: models.DefaultProject, languageCode: "es", pageSize: 2}
+
+	var found []string
+	for _, key := range []string{"a", "b", "c", "e", "f", "g"} {
+		exists, err := cursor.contains(key)
+		assert.NoError(t, err)
+		if exists {
+			found = append(found, key)
+		}
+	}
+
+	assert.Equal(t, []string{"a", "c", "f"}, found)
+}
+
+func TestConsumeTranslation_WhenTranslatorIsConcurrent_ShouldTranslateBatchesConcurrently(t *testing.T) {
+	// arrange
+	repo := &resourceRepositoryStub{
+		resources:         sourceResources(40),
+		jobRepositoryStub: jobRepositoryStub{jobs: map[int64]models.Job{7: {ID: 7, Status: models.JobQueued}}},
+	}
+	translator := &concurrentTranslator{concurrency: 3}
+	msg := TranslateLanguageMessage{JobID: 7, SourceLanguage: "en", TargetLanguage: "es"}
+
+	// act
+	err := ConsumeTranslation(&msg, repo, translator)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 8, translator.batches)
+	assert.Equal(t, 3, translator.maxRunning)
+	saved, _ := repo.GetResourcesByLanguageCode(models.DefaultProject, "es")
+	assert.Len(t, saved, 40)
+	job := repo.jobs[7]
+	assert.Equal(t, models.JobCompleted, job.Status)
+	assert.Equal(t, 8, job.BatchesDone)
+	assert.Equal(t, int64(40), job.Created)
+}
+
+func TestConsumeTranslation_WhenConcurrentBatchFails_ShouldStopStartingBatches(t *testing.T) {
+	// arrange
+	repo := &resourceRepositoryStub{resources: sourceResources(40)}
+	translator := &concurrentTranslator{concurrency: 2, failKey: "key00"}
+	msg := TranslateLanguageMessage{SourceLanguage: "en", TargetLanguage: "es"}
+
+	// act
+	err := ConsumeTranslation(&msg, repo, translator)
+
+	// assert
+	assert.Error(t, err)
+	assert.Less(t, translator.batches, 8)
+	saved, _ := repo.GetResourcesByLanguageCode(models.DefaultProject, "es")
+	assert.Len(t, saved, 5*(translator.batches-1), "the batches that finished should be saved")
+}
+
+// memoryRepositoryStub is a resourceRepositoryStub with a translation memory of entries
+type memoryRepositoryStub struct {
+	resourceRepositoryStub
+	entries []models.MemoryEntry
+}
+
+func (r *memoryRepositoryStub) FindExactMatches(sourceLanguage, targetLanguage string, texts []string) (map[string]string, error) {
+	matches := map[string]string{}
+	for _, text := range texts {
+		for _, entry := range r.entries {
+			if entry.SourceLanguage == sourceLanguage && entry.TargetLanguage == targetLanguage && entry.SourceText == text {
+				matches[text] = entry.TargetText
+			}
+		}
+	}
+	return matches, nil
+}
+
+func (r *memoryRepositoryStub) FindFuzzyMatches(sourceLanguage, targetLanguage, text string, minSimilarity float64, limit int) ([]models.MemoryMatch, error) {
+	return models.FindMemoryMatches(text, r.entries, minSimilarity, limit), nil
+}
+
+var _ contracts.TranslationMemory = (*memoryRepositoryStub)(nil)
+
+func TestConsumeTranslation_WhenTextsAreInMemory_ShouldOnlyTranslateTheOthers(t *testing.T) {
+	// arrange
+	repo := &memoryRepositoryStub{
+		resourceRepositoryStub: resourceRepositoryStub{resources: sourceResources(3)},
+		entries: []models.MemoryEntry{
+			{SourceLanguage: "en", SourceText: "text 0", TargetLanguage: "es", TargetText: "texto 0"},
+			{SourceLanguage: "en", SourceText: "text 2", TargetLanguage: "fi", TargetText: "teksti 2"},
+		},
+	}
+	translator := &concurrentTranslator{concurrency: 1}
+	msg := TranslateLanguageMessage{SourceLanguage: "en", TargetLanguage: "es"}
+
+	// act
+	err := ConsumeTranslation(&msg, repo, translator)
+
+	// assert
+	assert.NoError(t, err)
+	saved, _ := repo.GetResourcesByLanguageCode(models.DefaultProject, "es")
+	assert.Len(t, saved, 3)
+	texts := map[string]models.Resource{}
+	for _, resource := range saved {
+		texts[resource.Key] = resource
+	}
+	assert.Equal(t, "texto 0", texts["key00"].Text)
+	assert.Equal(t, models.ProviderMemory, texts["key00"].Provider)
+	assert.Equal(t, models.SourceHash("text 0"), texts["key00"].SourceHash)
+	assert.Equal(t, "translated text 1", texts["key01"].Text)
+	assert.Equal(t, "translated text 2", texts["key02"].Text, "memory of other languages shouldn't be used")
+	assert.Empty(t, texts["key02"].Provider)
+}
+
+func TestConsumeTranslation_WhenEveryTextIsInMemory_ShouldNotCallTranslator(t *testing.T) {
+	// arrange
+	repo := &memoryRepositoryStub{
+		resourceRepositoryStub: resourceRepositoryStub{resources: sourceResources(2)},
+		entries: []models.MemoryEntry{
+			{SourceLanguage: "en", SourceText: "text 0", TargetLanguage: "es", TargetText: "texto 0"},
+			{SourceLanguage: "en", SourceText: "text 1", TargetLanguage: "es", TargetText: "texto 1"},
+		},
+	}
+	translator := &concurrentTranslator{concurrency: 1}
+	msg := TranslateLanguageMessage{SourceLanguage: "en", TargetLanguage: "es"}
+
+	// act
+	err := ConsumeTranslation(&msg, repo, translator)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, translator.batches)
+	saved, _ := repo.GetResourcesByLanguageCode(models.DefaultProject, "es")
+	assert.Len(t, saved, 2)
+}
@@ -0,0 +1,74 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gotranslate/core/contracts"
+	"math"
+	"time"
+)
+
+// errPermanent marks errors that retrying won't fix, like a body that isn't json or a type without handler.
+var errPermanent = errors.New("message can't be handled")
+
+// dispatch finds the handler of the message by its Type and runs it. Errors wrapping errPermanent shouldn't be retried.
+func dispatch(handlersMap map[string]contracts.MessageHandler, body []byte) (messageType string, err error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "", fmt.Errorf("%w: unable to unmarshal body: %v", errPermanent, err.Error())
+	}
+
+	typeValue, exists := data["Type"]
+	if !exists {
+		return "", fmt.Errorf("%w: no Type found", errPermanent)
+	}
+
+	messageType, ok := typeValue.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: unable to read Type of message", errPermanent)
+	}
+
+	handler, found := handlersMap[messageType]
+	if !found {
+		return messageType, fmt.Errorf("%w: message of type %v can't be handled, make sure it's registered", errPermanent, messageType)
+	}
+
+	return messageType, handler.HandleMessage(data)
+}
+
+// retryDelay doubles the delay on every retry, up to maxDelay.
+func retryDelay(baseDelay, maxDelay time.Duration, retries int) time.Duration {
+	delay := float64(baseDelay) * math.Pow(2, float64(retries))
+	if delay > float64(maxDelay) {
+		return maxDelay
+	}
+	return time.Duration(delay)
+}
+
+// RetryOptions bound how many times and how often a failed message is retried before it's dead-lettered.
+type RetryOptions struct {
+	MaxRetries    int
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+}
+
+// DefaultRetryOptions retries 5 times, waiting 1s, 2s, 4s, 8s and 16s.
+func DefaultRetryOptions() RetryOptions {
+	return RetryOptions{MaxRetries: 5, RetryDelay: time.Second, MaxRetryDelay: time.Minute}
+}
+
+// WithDefaults returns the options with the defaults set on the values that are not set.
+func (o RetryOptions) WithDefaults() RetryOptions {
+	defaults := DefaultRetryOptions()
+	if o.MaxRetries <= 0 {
+		o.MaxRetries = defaults.MaxRetries
+	}
+	if o.RetryDelay <= 0 {
+		o.RetryDelay = defaults.RetryDelay
+	}
+	if o.MaxRetryDelay <= 0 {
+		o.MaxRetryDelay = defaults.MaxRetryDelay
+	}
+	return o
+}
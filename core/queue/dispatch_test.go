@@ -0,0 +1,79 @@
+package queue
+
+import (
+	"errors"
+	"gotranslate/core/contracts"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type handlerStub struct {
+	err      error
+	received map[string]interface{}
+}
+
+func (h *handlerStub) HandleMessage(messageBody map[string]interface{}) error {
+	h.received = messageBody
+	return h.err
+}
+
+func TestDispatch_WhenHandlerRegistered_ShouldCallIt(t *testing.T) {
+	handler := &handlerStub{}
+	handlers := map[string]contracts.MessageHandler{"Test": handler}
+
+	messageType, err := dispatch(handlers, []byte(`{"Type":"Test","Value":"abc"}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Test", messageType)
+	assert.Equal(t, "abc", handler.received["Value"])
+}
+
+func TestDispatch_WhenHandlerFails_ShouldReturnRetryableError(t *testing.T) {
+	handlers := map[string]contracts.MessageHandler{"Test": &handlerStub{err: errors.New("database is down")}}
+
+	_, err := dispatch(handlers, []byte(`{"Type":"Test"}`))
+
+	assert.ErrorContains(t, err, "database is down")
+	assert.NotErrorIs(t, err, errPermanent)
+}
+
+func TestDispatch_WhenMessageCantBeHandled_ShouldReturnPermanentError(t *testing.T) {
+	handlers := map[string]contracts.MessageHandler{"Test": &handlerStub{}}
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", `{"Type":`},
+		{"no type", `{"Value":"abc"}`},
+		{"type is not a string", `{"Type":5}`},
+		{"no handler", `{"Type":"Unknown"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := dispatch(handlers, []byte(tt.body))
+
+			assert.ErrorIs(t, err, errPermanent)
+		})
+	}
+}
+
+func TestRetryDelay_ShouldDoubleUpToMaxDelay(t *testing.T) {
+	baseDelay, maxDelay := time.Second, 5*time.Second
+
+	assert.Equal(t, time.Second, retryDelay(baseDelay, maxDelay, 0))
+	assert.Equal(t, 2*time.Second, retryDelay(baseDelay, maxDelay, 1))
+	assert.Equal(t, 4*time.Second, retryDelay(baseDelay, maxDelay, 2))
+	assert.Equal(t, maxDelay, retryDelay(baseDelay, maxDelay, 3))
+	assert.Equal(t, maxDelay, retryDelay(baseDelay, maxDelay, 100))
+}
+
+func TestRetryOptions_WithDefaults_ShouldKeepSetValues(t *testing.T) {
+	options := RetryOptions{MaxRetries: 2}.WithDefaults()
+
+	assert.Equal(t, 2, options.MaxRetries)
+	assert.Equal(t, DefaultRetryOptions().RetryDelay, options.RetryDelay)
+	assert.Equal(t, DefaultRetryOptions().MaxRetryDelay, options.MaxRetryDelay)
+}
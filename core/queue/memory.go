@@ -0,0 +1,182 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrQueueClosed is returned when publishing to a queue that was closed.
+var ErrQueueClosed = errors.New("queue is closed")
+
+type memoryMessage struct {
+	id      string
+	body    []byte
+	retries int
+}
+
+// Memory is a queue inside the process for development, tests and deployments without a broker. Messages are handled
+// by a pool of workers and retried like in RabbitMQ, but they're lost when the process stops.
+type Memory struct {
+	messages    chan memoryMessage
+	concurrency int
+	options     RetryOptions
+
+	mu     sync.RWMutex // publishing holds the read lock, so Close doesn't close the channel while sending to it
+	closed bool
+
+	workers sync.WaitGroup
+
+	deadMu      sync.Mutex
+	deadLetters []models.DeadLetter
+}
+
+// NewMemory creates a queue handled by concurrency workers, publishing blocks when bufferSize messages are waiting.
+func NewMemory(concurrency, bufferSize int, options RetryOptions) *Memory {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	if bufferSize < 0 {
+		bufferSize = 0
+	}
+
+	return &Memory{
+		messages:    make(chan memoryMessage, bufferSize),
+		concurrency: concurrency,
+		options:     options.WithDefaults(),
+	}
+}
+
+var _ contracts.QueueService = (*Memory)(nil)
+var _ contracts.DeadLetterQueue = (*Memory)(nil)
+
+func (m *Memory) Publish(data contracts.BaseMessage) error {
+	data.SetType()
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return m.enqueue(memoryMessage{id: newMessageID(), body: body})
+}
+
+func (m *Memory) enqueue(msg memoryMessage) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.closed {
+		return ErrQueueClosed
+	}
+	m.messages <- msg
+	return nil
+}
+
+func (m *Memory) Consume(handlersMap map[string]contracts.MessageHandler) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.closed {
+		return ErrQueueClosed
+	}
+
+	for i := 0; i < m.concurrency; i++ {
+		m.workers.Add(1)
+		go func() {
+			defer m.workers.Done()
+			for msg := range m.messages {
+				m.handle(handlersMap, msg)
+			}
+		}()
+	}
+
+	return nil
+}
+
+func (m *Memory) handle(handlersMap map[string]contracts.MessageHandler, msg memoryMessage) {
+	messageType, err := dispatch(handlersMap, msg.body)
+	if err == nil {
+		return
+	}
+
+	if errors.Is(err, errPermanent) || msg.retries >= m.options.MaxRetries {
+		log.Printf("message %v failed after %d retries, dead-lettering it. Error: %v", msg.id, msg.retries, err)
+		m.deadMu.Lock()
+		m.deadLetters = append(m.deadLetters, models.DeadLetter{
+			ID:      msg.id,
+			Type:    messageType,
+			Body:    msg.body,
+			Error:   err.Error(),
+			Retries: msg.retries,
+			DeadAt:  time.Now().UTC(),
+		})
+		m.deadMu.Unlock()
+		return
+	}
+
+	delay := retryDelay(m.options.RetryDelay, m.options.MaxRetryDelay, msg.retries)
+	log.Printf("message %v failed, retry %d in %v. Error: %v", msg.id, msg.retries+1, delay, err)
+	msg.retries++
+	time.AfterFunc(delay, func() {
+		if err := m.enqueue(msg); err != nil {
+			log.Printf("unable to retry message %v. Error: %v", msg.id, err)
+		}
+	})
+}
+
+func (m *Memory) DeadLetters(limit int) ([]models.DeadLetter, error) {
+	m.deadMu.Lock()
+	defer m.deadMu.Unlock()
+
+	results := []models.DeadLetter{}
+	for i := 0; i < len(m.deadLetters) && i < limit; i++ {
+		results = append(results, m.deadLetters[i])
+	}
+	return results, nil
+}
+
+func (m *Memory) ReplayDeadLetters(ids ...string) (replayed int, err error) {
+	selected := map[string]bool{}
+	for _, id := range ids {
+		selected[id] = true
+	}
+
+	m.deadMu.Lock()
+	var toReplay, remaining []models.DeadLetter
+	for _, deadLetter := range m.deadLetters {
+		if len(selected) == 0 || selected[deadLetter.ID] {
+			toReplay = append(toReplay, deadLetter)
+		} else {
+			remaining = append(remaining, deadLetter)
+		}
+	}
+	m.deadLetters = remaining
+	m.deadMu.Unlock()
+
+	for i, deadLetter := range toReplay {
+		if err := m.enqueue(memoryMessage{id: deadLetter.ID, body: deadLetter.Body}); err != nil {
+			m.deadMu.Lock()
+			m.deadLetters = append(m.deadLetters, toReplay[i:]...)
+			m.deadMu.Unlock()
+			return replayed, err
+		}
+		replayed++
+	}
+
+	return replayed, nil
+}
+
+// Close stops accepting messages and waits until the workers handled the ones already published.
+// Retries that are still waiting for their delay are dropped.
+func (m *Memory) Close() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	close(m.messages)
+	m.mu.Unlock()
+
+	m.workers.Wait()
+}
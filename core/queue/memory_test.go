@@ -0,0 +1,132 @@
+package queue
+
+import (
+	"errors"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testMessage struct {
+	Type  string
+	Value string
+}
+
+func (m *testMessage) GetType() string {
+	return "Test"
+}
+
+func (m *testMessage) SetType() {
+	m.Type = m.GetType()
+}
+
+// countingHandler fails the first failures messages and counts the ones it handled.
+type countingHandler struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	handled  []string
+}
+
+func (h *countingHandler) HandleMessage(messageBody map[string]interface{}) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls++
+	if h.calls <= h.failures {
+		return errors.New("failed")
+	}
+	h.handled = append(h.handled, messageBody["Value"].(string))
+	return nil
+}
+
+func (h *countingHandler) handledValues() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string{}, h.handled...)
+}
+
+func deadLettersOf(q *Memory) []models.DeadLetter {
+	deadLetters, _ := q.DeadLetters(10)
+	return deadLetters
+}
+
+var fastRetries = RetryOptions{MaxRetries: 2, RetryDelay: time.Millisecond, MaxRetryDelay: time.Millisecond}
+
+func TestMemory_WhenClosed_ShouldHandleAllPublishedMessages(t *testing.T) {
+	// arrange
+	handler := &countingHandler{}
+	q := NewMemory(3, 100, fastRetries)
+	assert.NoError(t, q.Consume(map[string]contracts.MessageHandler{"Test": handler}))
+
+	// act
+	for _, value := range []string{"a", "b", "c", "d", "e"} {
+		assert.NoError(t, q.Publish(&testMessage{Value: value}))
+	}
+	q.Close()
+
+	// assert
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d", "e"}, handler.handledValues())
+	assert.ErrorIs(t, q.Publish(&testMessage{Value: "f"}), ErrQueueClosed)
+}
+
+func TestMemory_WhenHandlerFailsOnce_ShouldRetry(t *testing.T) {
+	// arrange
+	handler := &countingHandler{failures: 1}
+	q := NewMemory(1, 10, fastRetries)
+	defer q.Close()
+	assert.NoError(t, q.Consume(map[string]contracts.MessageHandler{"Test": handler}))
+
+	// act
+	assert.NoError(t, q.Publish(&testMessage{Value: "a"}))
+
+	// assert
+	assert.Eventually(t, func() bool { return len(handler.handledValues()) == 1 }, time.Second, time.Millisecond)
+	deadLetters, _ := q.DeadLetters(10)
+	assert.Empty(t, deadLetters)
+}
+
+func TestMemory_WhenRetriesExhausted_ShouldDeadLetterAndReplay(t *testing.T) {
+	// arrange
+	handler := &countingHandler{failures: 3}
+	q := NewMemory(1, 10, fastRetries)
+	defer q.Close()
+	assert.NoError(t, q.Consume(map[string]contracts.MessageHandler{"Test": handler}))
+
+	// act
+	assert.NoError(t, q.Publish(&testMessage{Value: "a"}))
+
+	// assert
+	var deadLetters []models.DeadLetter
+	assert.Eventually(t, func() bool {
+		deadLetters = deadLettersOf(q)
+		return len(deadLetters) == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "Test", deadLetters[0].Type)
+	assert.Equal(t, 2, deadLetters[0].Retries)
+	assert.Equal(t, "failed", deadLetters[0].Error)
+
+	replayed, err := q.ReplayDeadLetters()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+	assert.Eventually(t, func() bool { return len(handler.handledValues()) == 1 }, time.Second, time.Millisecond)
+	assert.Empty(t, deadLettersOf(q))
+}
+
+func TestMemory_WhenNoHandler_ShouldDeadLetterWithoutRetrying(t *testing.T) {
+	// arrange
+	q := NewMemory(1, 10, fastRetries)
+	assert.NoError(t, q.Consume(map[string]contracts.MessageHandler{}))
+
+	// act
+	assert.NoError(t, q.Publish(&testMessage{Value: "a"}))
+	q.Close()
+
+	// assert
+	deadLetters := deadLettersOf(q)
+	assert.Len(t, deadLetters, 1)
+	assert.Equal(t, 0, deadLetters[0].Retries)
+}
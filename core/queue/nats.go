@@ -0,0 +1,320 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"log"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	messageIDHeader   = "Message-Id"
+	messageTypeHeader = "Message-Type"
+	notBeforeHeader   = "Not-Before"
+	natsTimeout       = 10 * time.Second
+)
+
+var invalidStreamName = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// NATS publishes the messages to a JetStream work queue stream, read by a durable consumer with explicit acks.
+// A failed message is published again with its retry count and the time it can be handled after its retry delay,
+// so redeliveries after an expired ack wait don't count as retries, and after the last retry it's published to the
+// dead-letter stream, which keeps it until it's replayed. The consumer pulls one message at a time.
+type NATS struct {
+	conn       *nats.Conn
+	js         jetstream.JetStream
+	stream     jetstream.Stream
+	deadStream jetstream.Stream
+	subject    string
+	ackWait    time.Duration
+	options    RetryOptions
+	consuming  jetstream.ConsumeContext
+}
+
+func NewNATS(url string, queueName string, options RetryOptions) (*NATS, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, err
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	n := &NATS{conn: conn, js: js, subject: queueName, ackWait: 30 * time.Second, options: options.WithDefaults()}
+	if err := n.declareStreams(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return n, nil
+}
+
+var _ contracts.QueueService = (*NATS)(nil)
+var _ contracts.DeadLetterQueue = (*NATS)(nil)
+
+func (n *NATS) deadLetterSubject() string { return n.subject + ".dead" }
+
+// streamName returns a valid stream name for the subject, they can't contain dots or wildcards.
+func streamName(subject string) string {
+	return invalidStreamName.ReplaceAllString(subject, "_")
+}
+
+func (n *NATS) declareStreams() error {
+	ctx, cancel := context.WithTimeout(context.Background(), natsTimeout)
+	defer cancel()
+
+	stream, err := n.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      streamName(n.subject),
+		Subjects:  []string{n.subject},
+		Retention: jetstream.WorkQueuePolicy,
+		Storage:   jetstream.FileStorage,
+	})
+	if err != nil {
+		return err
+	}
+
+	deadStream, err := n.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     streamName(n.deadLetterSubject()),
+		Subjects: []string{n.deadLetterSubject()},
+		Storage:  jetstream.FileStorage,
+	})
+	if err != nil {
+		return err
+	}
+
+	n.stream, n.deadStream = stream, deadStream
+	return nil
+}
+
+func (n *NATS) Publish(data contracts.BaseMessage) error {
+	data.SetType()
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(n.subject)
+	msg.Header.Set(messageIDHeader, newMessageID())
+	msg.Header.Set(messageTypeHeader, data.GetType())
+	msg.Data = body
+	return n.publish(msg)
+}
+
+func (n *NATS) publish(msg *nats.Msg) error {
+	ctx, cancel := context.WithTimeout(context.Background(), natsTimeout)
+	defer cancel()
+	_, err := n.js.PublishMsg(ctx, msg)
+	return err
+}
+
+func (n *NATS) Consume(handlersMap map[string]contracts.MessageHandler) error {
+	ctx, cancel := context.WithTimeout(context.Background(), natsTimeout)
+	defer cancel()
+
+	consumer, err := n.stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:   streamName(n.subject) + "_workers",
+		AckPolicy: jetstream.AckExplicitPolicy,
+		AckWait:   n.ackWait,
+	})
+	if err != nil {
+		return err
+	}
+
+	n.consuming, err = consumer.Consume(func(msg jetstream.Msg) {
+		n.handleMessage(handlersMap, msg)
+	}, jetstream.PullMaxMessages(1))
+	return err
+}
+
+// handleMessage runs the handler of the message and acks it once it was handled, retried or dead-lettered.
+// A retried message is nacked until its retry delay is over.
+func (n *NATS) handleMessage(handlersMap map[string]contracts.MessageHandler, msg jetstream.Msg) {
+	if wait := time.Until(notBefore(msg.Headers())); wait > 0 {
+		msg.NakWithDelay(wait)
+		return
+	}
+	retries, _ := strconv.Atoi(msg.Headers().Get(retryCountHeader))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go n.keepInProgress(ctx, msg)
+	_, err := dispatch(handlersMap, msg.Data())
+	cancel()
+
+	messageID := msg.Headers().Get(messageIDHeader)
+	if err == nil {
+		msg.Ack()
+		return
+	}
+
+	if errors.Is(err, errPermanent) || retries >= n.options.MaxRetries {
+		log.Printf("message %v on queue %v failed after %d retries, dead-lettering it. Error: %v", messageID, n.subject, retries, err)
+		deadLetter := republishedMsg(msg, n.deadLetterSubject(), retries, err)
+		deadLetter.Header.Del(notBeforeHeader)
+		deadLetter.Header.Set(deadAtHeader, time.Now().UTC().Format(time.RFC3339))
+		if err := n.publish(deadLetter); err != nil {
+			log.Printf("unable to dead-letter message %v, redelivering it. Error: %v", messageID, err)
+			msg.Nak()
+			return
+		}
+		msg.Ack()
+		return
+	}
+
+	delay := retryDelay(n.options.RetryDelay, n.options.MaxRetryDelay, retries)
+	log.Printf("message %v on queue %v failed, retry %d in %v. Error: %v", messageID, n.subject, retries+1, delay, err)
+	retry := republishedMsg(msg, n.subject, retries+1, err)
+	retry.Header.Set(notBeforeHeader, time.Now().Add(delay).UTC().Format(time.RFC3339Nano))
+	if err := n.publish(retry); err != nil {
+		log.Printf("unable to retry message %v, redelivering it. Error: %v", messageID, err)
+		msg.NakWithDelay(delay)
+		return
+	}
+	msg.Ack()
+}
+
+// republishedMsg copies the message to the subject with its retry count and last error.
+func republishedMsg(msg jetstream.Msg, subject string, retries int, err error) *nats.Msg {
+	republished := nats.NewMsg(subject)
+	for key, values := range msg.Headers() {
+		republished.Header[key] = append([]string(nil), values...)
+	}
+	republished.Header.Set(retryCountHeader, strconv.Itoa(retries))
+	republished.Header.Set(errorHeader, err.Error())
+	republished.Data = msg.Data()
+	return republished
+}
+
+// notBefore is the time a retried message can be handled again, or the zero time if it's not delayed.
+func notBefore(header nats.Header) time.Time {
+	at, _ := time.Parse(time.RFC3339Nano, header.Get(notBeforeHeader))
+	return at
+}
+
+// keepInProgress resets the ack wait of a message while it's handled, so long translations aren't delivered twice.
+func (n *NATS) keepInProgress(ctx context.Context, msg jetstream.Msg) {
+	ticker := time.NewTicker(n.ackWait / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := msg.InProgress(); err != nil {
+				log.Printf("unable to extend ack wait of message %v. Error: %v", msg.Headers().Get(messageIDHeader), err)
+			}
+		}
+	}
+}
+
+// deadMessages calls f with the messages of the dead-letter stream in order until it returns false.
+func (n *NATS) deadMessages(ctx context.Context, f func(*jetstream.RawStreamMsg) (bool, error)) error {
+	info, err := n.deadStream.Info(ctx)
+	if err != nil {
+		return err
+	}
+
+	if info.State.Msgs == 0 {
+		return nil
+	}
+
+	for seq := info.State.FirstSeq; seq <= info.State.LastSeq; seq++ {
+		msg, err := n.deadStream.GetMsg(ctx, seq)
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if next, err := f(msg); err != nil || !next {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *NATS) DeadLetters(limit int) ([]models.DeadLetter, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), natsTimeout)
+	defer cancel()
+
+	results := []models.DeadLetter{}
+	err := n.deadMessages(ctx, func(msg *jetstream.RawStreamMsg) (bool, error) {
+		if len(results) >= limit {
+			return false, nil
+		}
+		results = append(results, natsDeadLetterOf(msg))
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (n *NATS) ReplayDeadLetters(ids ...string) (replayed int, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), natsTimeout)
+	defer cancel()
+
+	selected := map[string]bool{}
+	for _, id := range ids {
+		selected[id] = true
+	}
+
+	err = n.deadMessages(ctx, func(deadLetter *jetstream.RawStreamMsg) (bool, error) {
+		messageID := deadLetter.Header.Get(messageIDHeader)
+		if len(selected) > 0 && !selected[messageID] {
+			return true, nil
+		}
+
+		msg := nats.NewMsg(n.subject)
+		msg.Header.Set(messageIDHeader, messageID)
+		msg.Header.Set(messageTypeHeader, deadLetter.Header.Get(messageTypeHeader))
+		msg.Data = deadLetter.Data
+		if _, err := n.js.PublishMsg(ctx, msg); err != nil {
+			return false, err
+		}
+		if err := n.deadStream.DeleteMsg(ctx, deadLetter.Sequence); err != nil {
+			return false, err
+		}
+		replayed++
+		return true, nil
+	})
+
+	return replayed, err
+}
+
+// Close lets the consumer finish the messages it already received before closing the connection.
+func (n *NATS) Close() {
+	if n.consuming != nil {
+		n.consuming.Drain()
+		<-n.consuming.Closed()
+	}
+	n.conn.Close()
+}
+
+func natsDeadLetterOf(msg *jetstream.RawStreamMsg) models.DeadLetter {
+	deadLetter := models.DeadLetter{
+		ID:    msg.Header.Get(messageIDHeader),
+		Type:  msg.Header.Get(messageTypeHeader),
+		Body:  msg.Data,
+		Error: msg.Header.Get(errorHeader),
+	}
+	deadLetter.Retries, _ = strconv.Atoi(msg.Header.Get(retryCountHeader))
+	deadLetter.DeadAt, _ = time.Parse(time.RFC3339, msg.Header.Get(deadAtHeader))
+	if !json.Valid(msg.Data) {
+		deadLetter.Body, _ = json.Marshal(string(msg.Data))
+	}
+	return deadLetter
+}
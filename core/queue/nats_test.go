@@ -0,0 +1,161 @@
+package queue
+
+import (
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestNATS(t *testing.T) (*NATS, func()) {
+	natsServer, err := server.NewServer(&server.Options{Port: server.RANDOM_PORT, JetStream: true, StoreDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go natsServer.Start()
+	if !natsServer.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server didn't start")
+	}
+
+	q, err := NewNATS(natsServer.ClientURL(), "test_queue", fastRetries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return q, func() {
+		q.Close()
+		natsServer.Shutdown()
+	}
+}
+
+func TestNATS_ShouldHandlePublishedMessages(t *testing.T) {
+	// arrange
+	q, teardown := newTestNATS(t)
+	defer teardown()
+	handler := &countingHandler{}
+	assert.NoError(t, q.Consume(map[string]contracts.MessageHandler{"Test": handler}))
+
+	// act
+	for _, value := range []string{"a", "b", "c"} {
+		assert.NoError(t, q.Publish(&testMessage{Value: value}))
+	}
+
+	// assert
+	assert.Eventually(t, func() bool { return len(handler.handledValues()) == 3 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"a", "b", "c"}, handler.handledValues())
+}
+
+func TestNATS_WhenHandlerFailsOnce_ShouldRedeliver(t *testing.T) {
+	// arrange
+	q, teardown := newTestNATS(t)
+	defer teardown()
+	handler := &countingHandler{failures: 1}
+	assert.NoError(t, q.Consume(map[string]contracts.MessageHandler{"Test": handler}))
+
+	// act
+	assert.NoError(t, q.Publish(&testMessage{Value: "a"}))
+
+	// assert
+	assert.Eventually(t, func() bool { return len(handler.handledValues()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	deadLetters, err := q.DeadLetters(10)
+	assert.NoError(t, err)
+	assert.Empty(t, deadLetters)
+}
+
+func TestNATS_WhenRetriesExhausted_ShouldDeadLetterAndReplay(t *testing.T) {
+	// arrange
+	q, teardown := newTestNATS(t)
+	defer teardown()
+	handler := &countingHandler{failures: 3}
+	assert.NoError(t, q.Consume(map[string]contracts.MessageHandler{"Test": handler}))
+
+	// act
+	assert.NoError(t, q.Publish(&testMessage{Value: "a"}))
+
+	// assert
+	var deadLetters []models.DeadLetter
+	assert.Eventually(t, func() bool {
+		deadLetters, _ = q.DeadLetters(10)
+		return len(deadLetters) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "Test", deadLetters[0].Type)
+	assert.Equal(t, 2, deadLetters[0].Retries)
+	assert.Equal(t, "failed", deadLetters[0].Error)
+	assert.JSONEq(t, `{"Type":"Test","Value":"a"}`, string(deadLetters[0].Body))
+
+	replayed, err := q.ReplayDeadLetters(deadLetters[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+	assert.Eventually(t, func() bool { return len(handler.handledValues()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	deadLetters, _ = q.DeadLetters(10)
+	assert.Empty(t, deadLetters)
+}
+
+// natsMsgStub is a message JetStream delivered several times, as if its ack wait expired
+type natsMsgStub struct {
+	jetstream.Msg
+	headers      nats.Header
+	data         []byte
+	numDelivered uint64
+	acked        bool
+}
+
+func (m *natsMsgStub) Headers() nats.Header { return m.headers }
+func (m *natsMsgStub) Data() []byte         { return m.data }
+func (m *natsMsgStub) Ack() error           { m.acked = true; return nil }
+func (m *natsMsgStub) InProgress() error    { return nil }
+func (m *natsMsgStub) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{NumDelivered: m.numDelivered}, nil
+}
+
+func TestNATS_WhenRedeliveredAfterAckWait_ShouldNotCountItAsRetry(t *testing.T) {
+	// arrange
+	q, teardown := newTestNATS(t)
+	defer teardown()
+	handler := &countingHandler{failures: 1}
+	handlers := map[string]contracts.MessageHandler{"Test": handler}
+	msg := &natsMsgStub{
+		headers:      nats.Header{messageIDHeader: []string{"1"}, messageTypeHeader: []string{"Test"}},
+		data:         []byte(`{"Type":"Test","Value":"a"}`),
+		numDelivered: 10,
+	}
+
+	// act
+	q.handleMessage(handlers, msg)
+
+	// assert
+	assert.True(t, msg.acked)
+	assert.NoError(t, q.Consume(handlers))
+	assert.Eventually(t, func() bool { return len(handler.handledValues()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	deadLetters, err := q.DeadLetters(10)
+	assert.NoError(t, err)
+	assert.Empty(t, deadLetters)
+}
+
+func TestNATS_WhenNoHandler_ShouldDeadLetterWithoutRetrying(t *testing.T) {
+	// arrange
+	q, teardown := newTestNATS(t)
+	defer teardown()
+	assert.NoError(t, q.Consume(map[string]contracts.MessageHandler{}))
+
+	// act
+	assert.NoError(t, q.Publish(&testMessage{Value: "a"}))
+
+	// assert
+	var deadLetters []models.DeadLetter
+	assert.Eventually(t, func() bool {
+		deadLetters, _ = q.DeadLetters(10)
+		return len(deadLetters) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, deadLetters[0].Retries)
+}
+
+func TestStreamName_ShouldReplaceInvalidCharacters(t *testing.T) {
+	assert.Equal(t, "translation_queue_dead", streamName("translation_queue.dead"))
+	assert.Equal(t, "a_b_c", streamName("a.b*c"))
+}
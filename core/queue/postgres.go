@@ -0,0 +1,250 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	messageReady = "ready"
+	messageDead  = "dead"
+)
+
+// Postgres keeps the messages in the queue_messages table of the database used for the resources. Workers claim a message
+// with SKIP LOCKED and hide it for the visibility timeout while handling it, so if the process dies it's handled again.
+// Failed messages become visible again after their retry delay, and after the last retry their status is set to dead.
+type Postgres struct {
+	db                *sql.DB
+	queue             string
+	concurrency       int
+	visibilityTimeout time.Duration
+	pollInterval      time.Duration
+	options           RetryOptions
+
+	stop    chan struct{}
+	stopped sync.Once
+	workers sync.WaitGroup
+}
+
+// NewPostgres creates a queue on the database, messages are handled by concurrency workers and hidden from the other
+// workers for visibilityTimeout, which is extended while the handler runs.
+func NewPostgres(db *sql.DB, queueName string, concurrency int, visibilityTimeout time.Duration, options RetryOptions) *Postgres {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	if visibilityTimeout <= 0 {
+		visibilityTimeout = 5 * time.Minute
+	}
+
+	return &Postgres{
+		db:                db,
+		queue:             queueName,
+		concurrency:       concurrency,
+		visibilityTimeout: visibilityTimeout,
+		pollInterval:      time.Second,
+		options:           options.WithDefaults(),
+		stop:              make(chan struct{}),
+	}
+}
+
+var _ contracts.QueueService = (*Postgres)(nil)
+var _ contracts.DeadLetterQueue = (*Postgres)(nil)
+
+func (q *Postgres) Init() error {
+	createTableQuery := `
+		CREATE TABLE IF NOT EXISTS queue_messages (
+			ID BIGSERIAL PRIMARY KEY,
+			Queue TEXT NOT NULL,
+			Type TEXT NOT NULL,
+			Body JSONB NOT NULL,
+			Status TEXT NOT NULL DEFAULT 'ready',
+			Retries INT NOT NULL DEFAULT 0,
+			LastError TEXT NOT NULL DEFAULT '',
+			VisibleAt TIMESTAMPTZ NOT NULL DEFAULT now(),
+			CreatedAt TIMESTAMPTZ NOT NULL DEFAULT now(),
+			DeadAt TIMESTAMPTZ
+		);
+		CREATE INDEX IF NOT EXISTS idx_queue_messages_ready ON queue_messages (Queue, Status, VisibleAt);
+	`
+
+	_, err := q.db.Exec(createTableQuery)
+	return err
+}
+
+func (q *Postgres) Publish(data contracts.BaseMessage) error {
+	data.SetType()
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.db.Exec("INSERT INTO queue_messages (Queue, Type, Body) VALUES ($1, $2, $3)", q.queue, data.GetType(), string(body))
+	return err
+}
+
+func (q *Postgres) Consume(handlersMap map[string]contracts.MessageHandler) error {
+	for i := 0; i < q.concurrency; i++ {
+		q.workers.Add(1)
+		go func() {
+			defer q.workers.Done()
+			q.work(handlersMap)
+		}()
+	}
+	return nil
+}
+
+// work handles messages until the queue is closed, waiting for the poll interval when there are none.
+func (q *Postgres) work(handlersMap map[string]contracts.MessageHandler) {
+	for {
+		select {
+		case <-q.stop:
+			return
+		default:
+		}
+
+		handled, err := q.handleNext(handlersMap)
+		if err != nil {
+			log.Printf("unable to read queue %v. Error: %v", q.queue, err)
+		}
+		if handled {
+			continue
+		}
+
+		select {
+		case <-q.stop:
+			return
+		case <-time.After(q.pollInterval):
+		}
+	}
+}
+
+// handleNext claims the oldest visible message and handles it, it returns false when there was no message.
+func (q *Postgres) handleNext(handlersMap map[string]contracts.MessageHandler) (bool, error) {
+	claimQuery := `
+		UPDATE queue_messages SET VisibleAt = now() + $2::bigint * interval '1 millisecond'
+		WHERE ID = (
+			SELECT ID FROM queue_messages
+			WHERE Queue = $1 AND Status = 'ready' AND VisibleAt <= now()
+			ORDER BY ID
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING ID, Body, Retries
+	`
+
+	var id int64
+	var body string
+	var retries int
+	err := q.db.QueryRow(claimQuery, q.queue, q.visibilityTimeout.Milliseconds()).Scan(&id, &body, &retries)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go q.keepInvisible(ctx, id)
+	_, err = dispatch(handlersMap, []byte(body))
+	cancel()
+
+	if err == nil {
+		_, err = q.db.Exec("DELETE FROM queue_messages WHERE ID = $1", id)
+		return true, err
+	}
+
+	if errors.Is(err, errPermanent) || retries >= q.options.MaxRetries {
+		log.Printf("message %v on queue %v failed after %d retries, dead-lettering it. Error: %v", id, q.queue, retries, err)
+		_, err = q.db.Exec("UPDATE queue_messages SET Status = $2, LastError = $3, DeadAt = now() WHERE ID = $1", id, messageDead, err.Error())
+		return true, err
+	}
+
+	delay := retryDelay(q.options.RetryDelay, q.options.MaxRetryDelay, retries)
+	log.Printf("message %v on queue %v failed, retry %d in %v. Error: %v", id, q.queue, retries+1, delay, err)
+	_, err = q.db.Exec(
+		"UPDATE queue_messages SET Retries = Retries + 1, LastError = $2, VisibleAt = now() + $3::bigint * interval '1 millisecond' WHERE ID = $1",
+		id, err.Error(), delay.Milliseconds())
+	return true, err
+}
+
+// keepInvisible extends the visibility timeout of a message while it's handled, so long translations aren't claimed twice.
+func (q *Postgres) keepInvisible(ctx context.Context, id int64) {
+	ticker := time.NewTicker(q.visibilityTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := q.db.ExecContext(ctx, "UPDATE queue_messages SET VisibleAt = now() + $2::bigint * interval '1 millisecond' WHERE ID = $1",
+				id, q.visibilityTimeout.Milliseconds())
+			if err != nil && ctx.Err() == nil {
+				log.Printf("unable to extend visibility of message %v. Error: %v", id, err)
+			}
+		}
+	}
+}
+
+func (q *Postgres) DeadLetters(limit int) ([]models.DeadLetter, error) {
+	rows, err := q.db.Query(
+		"SELECT ID, Type, Body, LastError, Retries, DeadAt FROM queue_messages WHERE Queue = $1 AND Status = $2 ORDER BY ID LIMIT $3",
+		q.queue, messageDead, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []models.DeadLetter{}
+	for rows.Next() {
+		var id int64
+		var body string
+		var deadAt sql.NullTime
+		var deadLetter models.DeadLetter
+		if err := rows.Scan(&id, &deadLetter.Type, &body, &deadLetter.Error, &deadLetter.Retries, &deadAt); err != nil {
+			return nil, err
+		}
+		deadLetter.ID = strconv.FormatInt(id, 10)
+		deadLetter.Body = json.RawMessage(body)
+		deadLetter.DeadAt = deadAt.Time
+		results = append(results, deadLetter)
+	}
+
+	return results, rows.Err()
+}
+
+func (q *Postgres) ReplayDeadLetters(ids ...string) (replayed int, err error) {
+	replayQuery := "UPDATE queue_messages SET Status = $2, Retries = 0, LastError = '', VisibleAt = now(), DeadAt = NULL WHERE Queue = $1 AND Status = $3"
+	args := []any{q.queue, messageReady, messageDead}
+	if len(ids) > 0 {
+		messageIDs := []int64{}
+		for _, id := range ids {
+			if messageID, err := strconv.ParseInt(id, 10, 64); err == nil {
+				messageIDs = append(messageIDs, messageID)
+			}
+		}
+		replayQuery += " AND ID = ANY($4)"
+		args = append(args, messageIDs)
+	}
+
+	result, err := q.db.Exec(replayQuery, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	return int(count), err
+}
+
+// Close waits for the workers to finish the messages they're handling, the database is closed by its owner.
+func (q *Postgres) Close() {
+	q.stopped.Do(func() { close(q.stop) })
+	q.workers.Wait()
+}
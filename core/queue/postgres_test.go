@@ -0,0 +1,93 @@
+package queue
+
+import (
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"gotranslate/testutils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPostgres(t *testing.T, concurrency int) (*Postgres, func()) {
+	db, teardown := testutils.SpinUpContainer(t)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := NewPostgres(sqlDB, "test_queue", concurrency, time.Minute, fastRetries)
+	q.pollInterval = 10 * time.Millisecond
+	if err := q.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	return q, teardown
+}
+
+func TestPostgres_ShouldHandleEachMessageOnce(t *testing.T) {
+	// arrange
+	q, teardown := newTestPostgres(t, 4)
+	defer teardown()
+	handler := &countingHandler{}
+	for _, value := range []string{"a", "b", "c", "d", "e", "f"} {
+		assert.NoError(t, q.Publish(&testMessage{Value: value}))
+	}
+
+	// act
+	assert.NoError(t, q.Consume(map[string]contracts.MessageHandler{"Test": handler}))
+
+	// assert
+	assert.Eventually(t, func() bool { return len(handler.handledValues()) == 6 }, 5*time.Second, 10*time.Millisecond)
+	q.Close()
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d", "e", "f"}, handler.handledValues())
+	var remaining int
+	q.db.QueryRow("SELECT count(*) FROM queue_messages").Scan(&remaining)
+	assert.Equal(t, 0, remaining)
+}
+
+func TestPostgres_WhenRetriesExhausted_ShouldDeadLetterAndReplay(t *testing.T) {
+	// arrange
+	q, teardown := newTestPostgres(t, 1)
+	defer teardown()
+	defer q.Close()
+	handler := &countingHandler{failures: 3}
+	assert.NoError(t, q.Publish(&testMessage{Value: "a"}))
+
+	// act
+	assert.NoError(t, q.Consume(map[string]contracts.MessageHandler{"Test": handler}))
+
+	// assert
+	var deadLetters []models.DeadLetter
+	assert.Eventually(t, func() bool {
+		deadLetters, _ = q.DeadLetters(10)
+		return len(deadLetters) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "Test", deadLetters[0].Type)
+	assert.Equal(t, 2, deadLetters[0].Retries)
+	assert.Equal(t, "failed", deadLetters[0].Error)
+	assert.False(t, deadLetters[0].DeadAt.IsZero())
+
+	replayed, err := q.ReplayDeadLetters(deadLetters[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, replayed)
+	assert.Eventually(t, func() bool { return len(handler.handledValues()) == 1 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestPostgres_WhenMessageIsNotVisible_ShouldNotClaimIt(t *testing.T) {
+	// arrange
+	q, teardown := newTestPostgres(t, 1)
+	defer teardown()
+	assert.NoError(t, q.Publish(&testMessage{Value: "a"}))
+	q.db.Exec("UPDATE queue_messages SET VisibleAt = now() + interval '1 minute'")
+	handler := &countingHandler{}
+
+	// act
+	handled, err := q.handleNext(map[string]contracts.MessageHandler{"Test": handler})
+
+	// assert
+	assert.NoError(t, err)
+	assert.False(t, handled)
+	assert.Empty(t, handler.handledValues())
+}
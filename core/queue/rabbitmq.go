@@ -1,20 +1,40 @@
 package queue
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"gotranslate/core/contracts"
+	"gotranslate/models"
 	"log"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/streadway/amqp"
 )
 
+const (
+	retryCountHeader = "x-retry-count"
+	errorHeader      = "x-last-error"
+	deadAtHeader     = "x-dead-at"
+)
+
+// RabbitMQ acknowledges messages manually. A message that fails is published to the retry queue of its delay, where it
+// waits for the TTL of the queue to expire and goes back to the main queue. After the last retry it's published to the
+// dead-letter exchange, whose queue keeps it until it's replayed. Queues are durable and messages persistent, so they
+// survive restarts of the broker and of the consumers.
 type RabbitMQ struct {
 	conn    *amqp.Connection
 	channel *amqp.Channel
 	queue   string
+	options RetryOptions
+	mu      sync.Mutex // publishing from the consumer and the api share the channel
 }
 
-func NewRabbitMQ(url string, queueName string) (*RabbitMQ, error) {
+func NewRabbitMQ(url string, queueName string, options RetryOptions) (*RabbitMQ, error) {
 	conn, err := amqp.Dial(url)
 	if err != nil {
 		return nil, err
@@ -26,17 +46,59 @@ func NewRabbitMQ(url string, queueName string) (*RabbitMQ, error) {
 		return nil, err
 	}
 
-	_, err = ch.QueueDeclare(queueName, false, true, false, false, nil)
-	if err != nil {
+	r := &RabbitMQ{conn: conn, channel: ch, queue: queueName, options: options.WithDefaults()}
+	if err := r.declareTopology(); err != nil {
 		ch.Close()
 		conn.Close()
 		return nil, err
 	}
 
-	return &RabbitMQ{conn: conn, channel: ch, queue: queueName}, nil
+	return r, nil
 }
 
 var _ contracts.QueueService = (*RabbitMQ)(nil)
+var _ contracts.DeadLetterQueue = (*RabbitMQ)(nil)
+
+func (r *RabbitMQ) retryQueue(delay time.Duration) string {
+	return fmt.Sprintf("%v.retry.%d", r.queue, delay.Milliseconds())
+}
+func (r *RabbitMQ) deadLetterExchange() string { return r.queue + ".dead-letter" }
+func (r *RabbitMQ) deadLetterQueue() string    { return r.queue + ".dead" }
+
+// retryDelays returns the different delays of the retries. Every delay has its own retry queue with a queue TTL,
+// a per-message expiration would make the messages with a short delay wait behind the ones with a long delay.
+func (r *RabbitMQ) retryDelays() []time.Duration {
+	var delays []time.Duration
+	for retries := 0; retries < r.options.MaxRetries; retries++ {
+		delay := retryDelay(r.options.RetryDelay, r.options.MaxRetryDelay, retries)
+		if len(delays) == 0 || delays[len(delays)-1] != delay {
+			delays = append(delays, delay)
+		}
+	}
+	return delays
+}
+
+func (r *RabbitMQ) declareTopology() error {
+	if _, err := r.channel.QueueDeclare(r.queue, true, false, false, false, nil); err != nil {
+		return err
+	}
+
+	for _, delay := range r.retryDelays() {
+		// expired messages go back to the main queue through the default exchange
+		retryArgs := amqp.Table{"x-dead-letter-exchange": "", "x-dead-letter-routing-key": r.queue, "x-message-ttl": delay.Milliseconds()}
+		if _, err := r.channel.QueueDeclare(r.retryQueue(delay), true, false, false, false, retryArgs); err != nil {
+			return err
+		}
+	}
+
+	if err := r.channel.ExchangeDeclare(r.deadLetterExchange(), amqp.ExchangeFanout, true, false, false, false, nil); err != nil {
+		return err
+	}
+	if _, err := r.channel.QueueDeclare(r.deadLetterQueue(), true, false, false, false, nil); err != nil {
+		return err
+	}
+	return r.channel.QueueBind(r.deadLetterQueue(), "", r.deadLetterExchange(), false, nil)
+}
 
 func (r *RabbitMQ) Publish(data contracts.BaseMessage) error {
 	data.SetType()
@@ -45,57 +107,183 @@ func (r *RabbitMQ) Publish(data contracts.BaseMessage) error {
 		return err
 	}
 	msg := amqp.Publishing{
-		ContentType: "application/json",
-		Body:        body,
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		MessageId:    newMessageID(),
+		Type:         data.GetType(),
+		Timestamp:    time.Now(),
+		Body:         body,
 	}
-	return r.channel.Publish("", r.queue, false, false, msg)
+	return r.publish("", r.queue, msg)
+}
+
+func (r *RabbitMQ) publish(exchange, key string, msg amqp.Publishing) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.channel.Publish(exchange, key, false, false, msg)
 }
 
 func (r *RabbitMQ) Consume(handlersMap map[string]contracts.MessageHandler) error {
-	msgs, err := r.channel.Consume(r.queue, "", true, false, false, false, nil)
+	if err := r.channel.Qos(10, 0, false); err != nil {
+		return err
+	}
+
+	msgs, err := r.channel.Consume(r.queue, "", false, false, false, false, nil)
 	if err != nil {
 		return err
 	}
 
 	go func() {
 		for d := range msgs {
-			var data map[string]interface{}
-			err := json.Unmarshal(d.Body, &data)
-			if err != nil {
-				log.Printf("unable to unmarshal body to consume message on queue %v. Error: %v\n", r.queue, err.Error())
-				return
-			}
-
-			typeValue, exists := data["Type"]
-			if !exists {
-				log.Println("error parsing message, no Type found")
-				return
-			}
-
-			messageType, ok := typeValue.(string)
-			if !ok {
-				log.Println("unable to read Type of message")
-				return
-			}
-
-			handler, found := handlersMap[messageType]
-			if !found {
-				log.Printf("message of type %v can't be handled, make sure it's registered", messageType)
-				return
-			}
-
-			err = handler.HandleMessage(data)
-			if err != nil {
-				log.Printf("error handling message: %v", err)
-				return
-			}
+			r.handleDelivery(handlersMap, d)
 		}
+		log.Printf("stopped consuming queue %v", r.queue)
 	}()
 
 	return nil
 }
 
+// handleDelivery runs the handler of the message and acks it once it was handled, retried or dead-lettered.
+// If the message can't be moved to the retry queue or the dead-letter exchange it's requeued.
+func (r *RabbitMQ) handleDelivery(handlersMap map[string]contracts.MessageHandler, d amqp.Delivery) {
+	_, err := dispatch(handlersMap, d.Body)
+	if err == nil {
+		d.Ack(false)
+		return
+	}
+
+	retries := retryCountOf(d.Headers)
+	if errors.Is(err, errPermanent) || retries >= r.options.MaxRetries {
+		log.Printf("message %v on queue %v failed after %d retries, dead-lettering it. Error: %v", d.MessageId, r.queue, retries, err)
+		err = r.publish(r.deadLetterExchange(), "", republished(d, retries, err, amqp.Table{deadAtHeader: time.Now().UTC().Format(time.RFC3339)}))
+	} else {
+		delay := retryDelay(r.options.RetryDelay, r.options.MaxRetryDelay, retries)
+		log.Printf("message %v on queue %v failed, retry %d in %v. Error: %v", d.MessageId, r.queue, retries+1, delay, err)
+		err = r.publish("", r.retryQueue(delay), republished(d, retries+1, err, nil))
+	}
+
+	if err != nil {
+		log.Printf("unable to move message %v, requeueing it. Error: %v", d.MessageId, err)
+		d.Nack(false, true)
+		return
+	}
+	d.Ack(false)
+}
+
+// DeadLetters returns the first dead letters without removing them, they go back to the queue when the channel closes.
+func (r *RabbitMQ) DeadLetters(limit int) ([]models.DeadLetter, error) {
+	ch, err := r.conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+	defer ch.Close()
+
+	results := []models.DeadLetter{}
+	for len(results) < limit {
+		d, ok, err := ch.Get(r.deadLetterQueue(), false)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			break
+		}
+		results = append(results, deadLetterOf(d))
+	}
+
+	return results, nil
+}
+
+func (r *RabbitMQ) ReplayDeadLetters(ids ...string) (replayed int, err error) {
+	ch, err := r.conn.Channel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close() // the dead letters that weren't replayed are requeued
+
+	selected := map[string]bool{}
+	for _, id := range ids {
+		selected[id] = true
+	}
+
+	for {
+		d, ok, err := ch.Get(r.deadLetterQueue(), false)
+		if err != nil {
+			return replayed, err
+		} else if !ok {
+			return replayed, nil
+		}
+
+		if len(selected) > 0 && !selected[d.MessageId] {
+			continue
+		}
+
+		msg := amqp.Publishing{ContentType: d.ContentType, DeliveryMode: amqp.Persistent, MessageId: d.MessageId, Type: d.Type, Timestamp: time.Now(), Body: d.Body}
+		if err := ch.Publish("", r.queue, false, false, msg); err != nil {
+			return replayed, err
+		}
+		if err := d.Ack(false); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+}
+
 func (r *RabbitMQ) Close() {
 	r.channel.Close()
 	r.conn.Close()
 }
+
+func republished(d amqp.Delivery, retries int, err error, extraHeaders amqp.Table) amqp.Publishing {
+	headers := amqp.Table{retryCountHeader: int32(retries), errorHeader: err.Error()}
+	for key, value := range extraHeaders {
+		headers[key] = value
+	}
+
+	return amqp.Publishing{
+		Headers:      headers,
+		ContentType:  d.ContentType,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    d.MessageId,
+		Type:         d.Type,
+		Timestamp:    d.Timestamp,
+		Body:         d.Body,
+	}
+}
+
+func deadLetterOf(d amqp.Delivery) models.DeadLetter {
+	deadLetter := models.DeadLetter{ID: d.MessageId, Type: d.Type, Body: d.Body, Retries: retryCountOf(d.Headers)}
+	if message, ok := d.Headers[errorHeader].(string); ok {
+		deadLetter.Error = message
+	}
+	if deadAt, ok := d.Headers[deadAtHeader].(string); ok {
+		deadLetter.DeadAt, _ = time.Parse(time.RFC3339, deadAt)
+	}
+	if !json.Valid(d.Body) {
+		deadLetter.Body, _ = json.Marshal(string(d.Body))
+	}
+	return deadLetter
+}
+
+// retryCountOf reads the retry count header, the type depends on who published the message.
+func retryCountOf(headers amqp.Table) int {
+	switch value := headers[retryCountHeader].(type) {
+	case int32:
+		return int(value)
+	case int64:
+		return int(value)
+	case int:
+		return value
+	case string:
+		count, _ := strconv.Atoi(value)
+		return count
+	default:
+		return 0
+	}
+}
+
+func newMessageID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprint(time.Now().UnixNano())
+	}
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,57 @@
+package queue
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryCountOf_ShouldReadHeaderOfAnyIntegerType(t *testing.T) {
+	assert.Equal(t, 0, retryCountOf(nil))
+	assert.Equal(t, 3, retryCountOf(amqp.Table{retryCountHeader: int32(3)}))
+	assert.Equal(t, 4, retryCountOf(amqp.Table{retryCountHeader: int64(4)}))
+	assert.Equal(t, 5, retryCountOf(amqp.Table{retryCountHeader: "5"}))
+}
+
+func TestRepublished_ShouldKeepMessageAndSetRetryHeaders(t *testing.T) {
+	delivery := amqp.Delivery{MessageId: "abc", Type: "Test", ContentType: "application/json", Body: []byte(`{"Type":"Test"}`)}
+
+	msg := republished(delivery, 2, errors.New("failed"), amqp.Table{deadAtHeader: "2024-01-02T03:04:05Z"})
+
+	assert.Equal(t, "abc", msg.MessageId)
+	assert.Equal(t, uint8(amqp.Persistent), msg.DeliveryMode)
+	assert.Equal(t, delivery.Body, msg.Body)
+	assert.Equal(t, int32(2), msg.Headers[retryCountHeader])
+	assert.Equal(t, "failed", msg.Headers[errorHeader])
+	assert.Equal(t, "2024-01-02T03:04:05Z", msg.Headers[deadAtHeader])
+}
+
+func TestRetryDelays_ShouldHaveAQueuePerDifferentDelay(t *testing.T) {
+	r := &RabbitMQ{queue: "translations", options: RetryOptions{MaxRetries: 5, RetryDelay: time.Second, MaxRetryDelay: 4 * time.Second}}
+
+	delays := r.retryDelays()
+
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}, delays)
+	assert.Equal(t, "translations.retry.2000", r.retryQueue(delays[1]))
+}
+
+func TestDeadLetterOf_ShouldReadHeaders(t *testing.T) {
+	delivery := amqp.Delivery{
+		MessageId: "abc",
+		Type:      "Test",
+		Body:      []byte("not json"),
+		Headers:   amqp.Table{retryCountHeader: int32(5), errorHeader: "failed", deadAtHeader: "2024-01-02T03:04:05Z"},
+	}
+
+	deadLetter := deadLetterOf(delivery)
+
+	assert.Equal(t, "abc", deadLetter.ID)
+	assert.Equal(t, "Test", deadLetter.Type)
+	assert.Equal(t, 5, deadLetter.Retries)
+	assert.Equal(t, "failed", deadLetter.Error)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), deadLetter.DeadAt)
+	assert.JSONEq(t, `"not json"`, string(deadLetter.Body))
+}
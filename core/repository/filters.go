@@ -1,6 +1,123 @@
 package repository
 
+import (
+	"errors"
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
 type resourceFilter struct {
+	Project      string
 	Key          string
 	LanguageCode string
 }
+
+// withProject returns the resources with the default project set on the ones that have none.
+func withProject(resources []models.Resource) []models.Resource {
+	results := make([]models.Resource, len(resources))
+	for i, resource := range resources {
+		if resource.Project == "" {
+			resource.Project = models.DefaultProject
+		}
+		results[i] = resource
+	}
+	return results
+}
+
+func identityOf(resource models.Resource) resourceFilter {
+	return resourceFilter{Project: resource.Project, Key: resource.Key, LanguageCode: resource.LanguageCode}
+}
+
+// isManualEdit reports whether an update changes the text without a status, translators always set the status.
+func isManualEdit(current, resource models.Resource) bool {
+	return resource.Status == "" && current.Text != resource.Text
+}
+
+// isStillStale reports whether the current resource is still a machine translation of another source text than the
+// one the resource was translated from.
+func isStillStale(current, resource models.Resource) bool {
+	return current.SourceHash != "" && current.SourceHash != resource.SourceHash && models.CanRetranslate(current.Status)
+}
+
+// planUpsert splits the resources into the ones to insert and the ones to update according to the policy.
+// Resources repeated in the input are merged, the last one wins. Updates that change the text without a status are
+// edits by hand and get models.StatusEdited, the repositories clear their provider and refresh their source hash.
+// With models.ConflictStale nothing is inserted, the repositories check again that updated rows are still stale.
+func planUpsert(policy models.ConflictPolicy, existing, resources []models.Resource) (toInsert, toUpdate []models.Resource, result models.UpsertResult, err error) {
+	existingByIdentity := map[resourceFilter]models.Resource{}
+	for _, resource := range existing {
+		existingByIdentity[identityOf(resource)] = resource
+	}
+
+	var order []resourceFilter
+	latest := map[resourceFilter]models.Resource{}
+	for _, resource := range withProject(resources) {
+		identity := identityOf(resource)
+		if _, seen := latest[identity]; !seen {
+			order = append(order, identity)
+		}
+		latest[identity] = resource
+	}
+
+	var conflicts []string
+	for _, identity := range order {
+		resource := latest[identity]
+		current, exists := existingByIdentity[identity]
+		switch {
+		case policy == models.ConflictStale && (!exists || !isStillStale(current, resource)):
+			result.Skipped++
+		case !exists:
+			toInsert = append(toInsert, resource)
+			result.Created++
+		case policy == models.ConflictFail:
+			conflicts = append(conflicts, fmt.Sprintf("%v (%v)", resource.Key, resource.LanguageCode))
+		case policy == models.ConflictKeep:
+			result.Skipped++
+		case current.Text == resource.Text &&
+			(resource.Description == "" || current.Description == resource.Description) &&
+			(resource.Status == "" || current.Status == resource.Status) &&
+			(resource.SourceHash == "" || current.SourceHash == resource.SourceHash) &&
+			(resource.Provider == "" || current.Provider == resource.Provider):
+			result.Skipped++
+		default:
+			if isManualEdit(current, resource) {
+				resource.Status = models.StatusEdited
+			}
+			toUpdate = append(toUpdate, resource)
+			result.Updated++
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, nil, models.UpsertResult{}, fmt.Errorf("%w: %v", contracts.ErrResourceConflict, strings.Join(conflicts, ", "))
+	}
+
+	return toInsert, toUpdate, result, nil
+}
+
+// duplicatesLimit is the number of duplicated resources listed when the migration stops
+const duplicatesLimit = 20
+
+// duplicatesError stops the migration when resources are duplicated, the operator decides which row to keep because
+// the duplicates can hold different translations.
+func duplicatesError(duplicates []resourceFilter) error {
+	if len(duplicates) == 0 {
+		return nil
+	}
+
+	var identities []string
+	for _, duplicate := range duplicates {
+		identities = append(identities, fmt.Sprintf("%v/%v (%v)", duplicate.Project, duplicate.Key, duplicate.LanguageCode))
+	}
+	return fmt.Errorf("resources must be unique by project, key and language before the unique index is created, remove the duplicated rows of: %v", strings.Join(identities, ", "))
+}
+
+// isUniqueViolation reports whether the database rejected a row because of a unique index.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var upsertExisting = []models.Resource{
+	{Project: models.DefaultProject, Key: "title", LanguageCode: "en", Text: "Title", Description: "page title"},
+}
+
+func TestPlanUpsert_WhenOverwrite_ShouldInsertNewAndUpdateChanged(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "title", LanguageCode: "en", Text: "New title"},
+		{Key: "subtitle", LanguageCode: "en", Text: "Subtitle"},
+	}
+
+	toInsert, toUpdate, result, err := planUpsert(models.ConflictOverwrite, upsertExisting, resources)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.UpsertResult{Created: 1, Updated: 1}, result)
+	assert.Equal(t, "subtitle", toInsert[0].Key)
+	assert.Equal(t, models.DefaultProject, toInsert[0].Project)
+	assert.Equal(t, "New title", toUpdate[0].Text)
+}
+
+func TestPlanUpsert_WhenTextChangedWithoutStatus_ShouldMarkItAsEdited(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "title", LanguageCode: "en", Text: "New title"},
+		{Key: "title", LanguageCode: "en", Text: "Machine title", Status: models.StatusMachine},
+	}
+
+	_, manual, _, manualErr := planUpsert(models.ConflictOverwrite, upsertExisting, resources[:1])
+	_, machine, _, machineErr := planUpsert(models.ConflictOverwrite, upsertExisting, resources[1:])
+
+	assert.NoError(t, manualErr)
+	assert.NoError(t, machineErr)
+	assert.Equal(t, models.StatusEdited, manual[0].Status)
+	assert.Equal(t, models.StatusMachine, machine[0].Status)
+}
+
+func TestPlanUpsert_WhenOverwriteWithSameText_ShouldSkip(t *testing.T) {
+	resources := []models.Resource{{Key: "title", LanguageCode: "en", Text: "Title"}}
+
+	toInsert, toUpdate, result, err := planUpsert(models.ConflictOverwrite, upsertExisting, resources)
+
+	assert.NoError(t, err)
+	assert.Empty(t, toInsert)
+	assert.Empty(t, toUpdate)
+	assert.Equal(t, models.UpsertResult{Skipped: 1}, result)
+}
+
+func TestPlanUpsert_WhenOnlySourceHashChanged_ShouldUpdate(t *testing.T) {
+	resources := []models.Resource{{Key: "title", LanguageCode: "en", Text: "Title", SourceHash: models.SourceHash("Título")}}
+
+	_, toUpdate, result, err := planUpsert(models.ConflictOverwrite, upsertExisting, resources)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.UpsertResult{Updated: 1}, result)
+	assert.Equal(t, models.SourceHash("Título"), toUpdate[0].SourceHash)
+}
+
+func TestPlanUpsert_WhenOnlyProviderChanged_ShouldUpdate(t *testing.T) {
+	resources := []models.Resource{{Key: "title", LanguageCode: "en", Text: "Title", Provider: "deepl"}}
+
+	_, toUpdate, result, err := planUpsert(models.ConflictOverwrite, upsertExisting, resources)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.UpsertResult{Updated: 1}, result)
+	assert.Equal(t, "deepl", toUpdate[0].Provider)
+}
+
+func TestPlanUpsert_WhenKeep_ShouldOnlyInsertNew(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "title", LanguageCode: "en", Text: "New title"},
+		{Key: "title", LanguageCode: "de", Text: "Titel"},
+	}
+
+	toInsert, toUpdate, result, err := planUpsert(models.ConflictKeep, upsertExisting, resources)
+
+	assert.NoError(t, err)
+	assert.Len(t, toInsert, 1)
+	assert.Empty(t, toUpdate)
+	assert.Equal(t, models.UpsertResult{Created: 1, Skipped: 1}, result)
+}
+
+func TestPlanUpsert_WhenStale_ShouldOnlyUpdateTranslationsThatAreStillStale(t *testing.T) {
+	existing := []models.Resource{
+		{Project: models.DefaultProject, Key: "stale", LanguageCode: "es", Text: "viejo", Status: models.StatusMachine, SourceHash: models.SourceHash("old")},
+		{Project: models.DefaultProject, Key: "reviewed", LanguageCode: "es", Text: "revisado", Status: models.StatusReviewed, SourceHash: models.SourceHash("old")},
+		{Project: models.DefaultProject, Key: "retranslated", LanguageCode: "es", Text: "nuevo", Status: models.StatusMachine, SourceHash: models.SourceHash("new")},
+	}
+	resources := []models.Resource{
+		{Key: "stale", LanguageCode: "es", Text: "nuevo", Status: models.StatusMachine, SourceHash: models.SourceHash("new")},
+		{Key: "reviewed", LanguageCode: "es", Text: "nuevo", Status: models.StatusMachine, SourceHash: models.SourceHash("new")},
+		{Key: "retranslated", LanguageCode: "es", Text: "otro", Status: models.StatusMachine, SourceHash: models.SourceHash("new")},
+		{Key: "deleted", LanguageCode: "es", Text: "borrado", Status: models.StatusMachine, SourceHash: models.SourceHash("new")},
+	}
+
+	toInsert, toUpdate, result, err := planUpsert(models.ConflictStale, existing, resources)
+
+	assert.NoError(t, err)
+	assert.Empty(t, toInsert)
+	assert.Len(t, toUpdate, 1)
+	assert.Equal(t, "stale", toUpdate[0].Key)
+	assert.Equal(t, models.UpsertResult{Updated: 1, Skipped: 3}, result)
+}
+
+func TestPlanUpsert_WhenFailAndResourceExists_ShouldReturnConflict(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "title", LanguageCode: "en", Text: "New title"},
+		{Key: "subtitle", LanguageCode: "en", Text: "Subtitle"},
+	}
+
+	toInsert, _, _, err := planUpsert(models.ConflictFail, upsertExisting, resources)
+
+	assert.ErrorIs(t, err, contracts.ErrResourceConflict)
+	assert.ErrorContains(t, err, "title (en)")
+	assert.Empty(t, toInsert)
+}
+
+func TestPlanUpsert_WhenResourceRepeated_ShouldUseTheLastOne(t *testing.T) {
+	resources := []models.Resource{
+		{Key: "subtitle", LanguageCode: "en", Text: "first"},
+		{Key: "subtitle", LanguageCode: "en", Text: "second"},
+	}
+
+	toInsert, _, result, err := planUpsert(models.ConflictFail, upsertExisting, resources)
+
+	assert.NoError(t, err)
+	assert.Len(t, toInsert, 1)
+	assert.Equal(t, "second", toInsert[0].Text)
+	assert.Equal(t, int64(1), result.Created)
+}
+
+func TestPlanUpsert_WhenSameKeyInOtherProject_ShouldInsert(t *testing.T) {
+	resources := []models.Resource{{Project: "other", Key: "title", LanguageCode: "en", Text: "Title"}}
+
+	toInsert, _, _, err := planUpsert(models.ConflictFail, upsertExisting, resources)
+
+	assert.NoError(t, err)
+	assert.Len(t, toInsert, 1)
+}
@@ -8,7 +8,9 @@ import (
 	"gotranslate/core/contracts"
 	"gotranslate/models"
 	"os"
+	"sort"
 	"sync"
+	"time"
 )
 
 // This implementation was mostly to experiment
@@ -44,12 +46,24 @@ func (repo *ResourceFile) Init() error {
 	return nil
 }
 
-func (repo *ResourceFile) GetResourcesByLanguageCode(languageCode string) ([]models.Resource, error) {
-	return repo.getResources([]resourceFilter{{LanguageCode: languageCode}})
+func (repo *ResourceFile) GetResourcesByLanguageCode(project, languageCode string) ([]models.Resource, error) {
+	return repo.getResources([]resourceFilter{{Project: project, LanguageCode: languageCode}})
 }
 
-func (repo *ResourceFile) GetResourcesByKey(key string) ([]models.Resource, error) {
-	return repo.getResources([]resourceFilter{{Key: key}})
+func (repo *ResourceFile) GetResourcesByKey(project, key string) ([]models.Resource, error) {
+	return repo.getResources([]resourceFilter{{Project: project, Key: key}})
+}
+
+func (repo *ResourceFile) GetResourcesPage(project, languageCode, afterKey string, limit int) ([]models.Resource, error) {
+	resources, err := repo.GetResourcesByLanguageCode(project, languageCode)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Key < resources[j].Key })
+	start := sort.Search(len(resources), func(i int) bool { return resources[i].Key > afterKey })
+	end := min(start+limit, len(resources))
+	return resources[start:end], nil
 }
 
 func (repo *ResourceFile) AddResources(resources ...models.Resource) error {
@@ -57,9 +71,10 @@ func (repo *ResourceFile) AddResources(resources ...models.Resource) error {
 		return errors.New("no resources to add")
 	}
 
+	resources = withProject(resources)
 	var filters []resourceFilter
 	for _, resource := range resources {
-		filters = append(filters, resourceFilter{Key: resource.Key, LanguageCode: resource.LanguageCode})
+		filters = append(filters, resourceFilter{Project: resource.Project, Key: resource.Key, LanguageCode: resource.LanguageCode})
 	}
 
 	existingResources, err := repo.getResources(filters)
@@ -94,19 +109,99 @@ func (repo *ResourceFile) AddResources(resources ...models.Resource) error {
 	return nil
 }
 
-func (repo *ResourceFile) UpdateResourceValues(resources ...models.Resource) (rowsAffected int64, err error) {
+func (repo *ResourceFile) UpdateResourceValues(username string, resources ...models.Resource) (rowsAffected int64, err error) {
 	return 0, errors.New("not implemented")
 }
 
-func (repo *ResourceFile) RemoveResources(key, languageCode string) (rowsAffected int64, err error) {
+// UpsertResources rewrites the file with the new and updated resources, the file repository doesn't keep revisions.
+func (repo *ResourceFile) UpsertResources(policy models.ConflictPolicy, username string, resources ...models.Resource) (models.UpsertResult, error) {
+	repo.Mu.Lock()
+	defer repo.Mu.Unlock()
+
+	existing, err := repo.readResources()
+	if err != nil {
+		return models.UpsertResult{}, err
+	}
+
+	toInsert, toUpdate, result, err := planUpsert(policy, existing, resources)
+	if err != nil {
+		return models.UpsertResult{}, err
+	}
+
+	updates := map[resourceFilter]models.Resource{}
+	for _, resource := range toUpdate {
+		updates[identityOf(resource)] = resource
+	}
+	for i, current := range existing {
+		resource, found := updates[identityOf(current)]
+		if !found {
+			continue
+		}
+		current.Text = resource.Text
+		if resource.Description != "" {
+			current.Description = resource.Description
+		}
+		if resource.Status != "" {
+			current.Status = resource.Status
+		}
+		if resource.SourceHash != "" {
+			current.SourceHash = resource.SourceHash
+		}
+		if resource.Provider != "" {
+			current.Provider = resource.Provider
+		}
+		if resource.Status == models.StatusEdited {
+			// the file repository doesn't know the source language, so the source hash is kept
+			current.Provider = ""
+		}
+		existing[i] = current
+	}
+
+	if err := repo.writeResources(append(existing, toInsert...)); err != nil {
+		return models.UpsertResult{}, err
+	}
+	return result, nil
+}
+
+func (repo *ResourceFile) UpdateResourceStatus(resources ...models.Resource) (rowsAffected int64, err error) {
+	return 0, errors.New("not implemented")
+}
+
+func (repo *ResourceFile) RemoveResources(project, key, languageCode, username string) (rowsAffected int64, err error) {
 	return 0, errors.New("notimplemented")
 }
 
+func (repo *ResourceFile) GetRevisions(project, key, languageCode string) ([]models.Revision, error) {
+	return []models.Revision{}, errors.New("not implemented")
+}
+
+func (repo *ResourceFile) RollbackRevision(project string, revisionID int64, username string) (models.Revision, error) {
+	return models.Revision{}, errors.New("not implemented")
+}
+
 func (repo *ResourceFile) getResources(filters resourceFilters) ([]models.Resource, error) {
 	repo.Mu.Lock()
 	defer repo.Mu.Unlock()
 	var results []models.Resource = []models.Resource{}
 
+	resources, err := repo.readResources()
+	if err != nil {
+		return results, err
+	}
+
+	for _, resource := range resources {
+		if filters.Contains(resource.Project, resource.Key, resource.LanguageCode) {
+			results = append(results, resource)
+		}
+	}
+
+	return results, nil
+}
+
+// readResources reads every resource of the file, the caller holds the lock.
+func (repo *ResourceFile) readResources() ([]models.Resource, error) {
+	var results []models.Resource
+
 	file, err := os.Open(repo.File)
 	if err != nil {
 		return results, err
@@ -122,9 +217,10 @@ func (repo *ResourceFile) getResources(filters resourceFilters) ([]models.Resour
 			return results, err
 		}
 
-		if filters.Contains(resource.Key, resource.LanguageCode) {
-			results = append(results, resource)
+		if resource.Project == "" {
+			resource.Project = models.DefaultProject
 		}
+		results = append(results, resource)
 	}
 
 	if err = scanner.Err(); err != nil {
@@ -134,18 +230,171 @@ func (repo *ResourceFile) getResources(filters resourceFilters) ([]models.Resour
 	return results, nil
 }
 
-func (repo *ResourceFile) ExistingLanguageCodes() (results []models.LanguageResult, err error) {
-	panic("unimplemented")
+// writeResources replaces the content of the file with the resources, the caller holds the lock.
+func (repo *ResourceFile) writeResources(resources []models.Resource) error {
+	file, err := os.Create(repo.File)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, resource := range resources {
+		if err := encoder.Encode(resource); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *ResourceFile) ExistingLanguageCodes(project string) (results []models.LanguageResult, err error) {
+	resources, err := repo.getResources([]resourceFilter{{Project: project}})
+	if err != nil {
+		return []models.LanguageResult{}, err
+	}
+
+	counts := map[string]int{}
+	for _, resource := range resources {
+		if counts[resource.LanguageCode] == 0 {
+			results = append(results, models.LanguageResult{LanguageCode: resource.LanguageCode})
+		}
+		counts[resource.LanguageCode]++
+	}
+	for i := range results {
+		results[i].Count = counts[results[i].LanguageCode]
+	}
+
+	return results, nil
+}
+
+// GetProjects only knows the default project, the file repository doesn't store projects
+func (repo *ResourceFile) GetProjects() ([]models.Project, error) {
+	return []models.Project{{Name: models.DefaultProject, TargetLanguages: []string{}}}, nil
+}
+
+func (repo *ResourceFile) GetProject(name string) (project models.Project, found bool, err error) {
+	if name != models.DefaultProject {
+		return project, false, nil
+	}
+	return models.Project{Name: models.DefaultProject, TargetLanguages: []string{}}, true, nil
+}
+
+func (repo *ResourceFile) SaveProject(project models.Project) error {
+	return errors.New("not implemented")
 }
 
 type resourceFilters []resourceFilter
 
-func (filters resourceFilters) Contains(key, languageCode string) bool {
+func (filters resourceFilters) Contains(project, key, languageCode string) bool {
 	for _, filter := range filters {
-		if (filter.LanguageCode == "" || languageCode == filter.LanguageCode) && (filter.Key == "" || key == filter.Key) {
+		if (filter.Project == "" || project == filter.Project) &&
+			(filter.LanguageCode == "" || languageCode == filter.LanguageCode) &&
+			(filter.Key == "" || key == filter.Key) {
 			return true
 		}
 	}
 
 	return false
 }
+
+// CreateJob appends the job to a jobs file next to the resources file.
+func (repo *ResourceFile) CreateJob(job models.Job) (models.Job, error) {
+	repo.Mu.Lock()
+	defer repo.Mu.Unlock()
+
+	jobs, err := repo.readJobs()
+	if err != nil {
+		return job, err
+	}
+
+	job.ID = 1
+	if len(jobs) > 0 {
+		job.ID = jobs[len(jobs)-1].ID + 1
+	}
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = job.CreatedAt
+	return job, repo.writeJobs(append(jobs, job))
+}
+
+func (repo *ResourceFile) UpdateJob(job models.Job) error {
+	repo.Mu.Lock()
+	defer repo.Mu.Unlock()
+
+	jobs, err := repo.readJobs()
+	if err != nil {
+		return err
+	}
+
+	for i, existing := range jobs {
+		if existing.ID == job.ID {
+			job.CreatedAt, job.UpdatedAt = existing.CreatedAt, time.Now()
+			jobs[i] = job
+			return repo.writeJobs(jobs)
+		}
+	}
+	return fmt.Errorf("job %d not found", job.ID)
+}
+
+func (repo *ResourceFile) GetJob(id int64) (job models.Job, found bool, err error) {
+	repo.Mu.Lock()
+	defer repo.Mu.Unlock()
+
+	jobs, err := repo.readJobs()
+	if err != nil {
+		return job, false, err
+	}
+
+	for _, job := range jobs {
+		if job.ID == id {
+			return job, true, nil
+		}
+	}
+	return job, false, nil
+}
+
+func (repo *ResourceFile) jobsFile() string {
+	return repo.File + ".jobs"
+}
+
+// readJobs reads the jobs file, ordered by ID, the caller holds the lock. A missing file has no jobs.
+func (repo *ResourceFile) readJobs() ([]models.Job, error) {
+	var jobs []models.Job
+
+	file, err := os.Open(repo.jobsFile())
+	if os.IsNotExist(err) {
+		return jobs, nil
+	} else if err != nil {
+		return jobs, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var job models.Job
+		if err := json.Unmarshal(scanner.Bytes(), &job); err != nil {
+			return jobs, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, scanner.Err()
+}
+
+// writeJobs replaces the content of the jobs file, the caller holds the lock.
+func (repo *ResourceFile) writeJobs(jobs []models.Job) error {
+	file, err := os.Create(repo.jobsFile())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, job := range jobs {
+		if err := encoder.Encode(job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -20,7 +20,7 @@ func TestGetResources(t *testing.T) {
 		t.Error("failed to create the file")
 	}
 
-	results, err := repo.GetResourcesByLanguageCode(languageCode)
+	results, err := repo.GetResourcesByLanguageCode(models.DefaultProject, languageCode)
 
 	if err != nil || len(results) != expected {
 		t.Error("test failed")
@@ -39,7 +39,7 @@ func TestGetResource(t *testing.T) {
 		t.Error("failed to create the file")
 	}
 
-	results, err := repo.GetResourcesByKey(key)
+	results, err := repo.GetResourcesByKey(models.DefaultProject, key)
 
 	if err != nil || len(results) != expected {
 		t.Error("test failed")
@@ -60,7 +60,7 @@ func TestAddResourcesWith1Resource(t *testing.T) {
 		t.Error(err.Error())
 	}
 
-	data, err := repo.GetResourcesByKey(keyUnderTest)
+	data, err := repo.GetResourcesByKey(models.DefaultProject, keyUnderTest)
 	if err != nil {
 		t.Error(err.Error())
 	}
@@ -92,7 +92,7 @@ func TestAddResourcesFile(t *testing.T) {
 		t.Error(err.Error())
 	}
 
-	data, err := repo.GetResourcesByKey(keyUnderTest)
+	data, err := repo.GetResourcesByKey(models.DefaultProject, keyUnderTest)
 	if err != nil {
 		t.Error(err.Error())
 	}
@@ -105,6 +105,114 @@ func TestAddResourcesFile(t *testing.T) {
 	cleanup()
 }
 
+func TestGetResourcesByKey_WhenResourcesInManyProjects_ShouldOnlyReturnTheProject(t *testing.T) {
+	key := "shared"
+	testData := []models.Resource{
+		{Key: key, LanguageCode: "en", Text: "default project"},
+		{Project: "other", Key: key, LanguageCode: "en", Text: "other project"},
+	}
+	repo, cleanup, err := createTestfileWithData(false, testData)
+	defer cleanup()
+	if err != nil {
+		t.Error("failed to create the file")
+	}
+
+	defaultResults, err := repo.GetResourcesByKey(models.DefaultProject, key)
+	otherResults, otherErr := repo.GetResourcesByKey("other", key)
+
+	if err != nil || otherErr != nil || len(defaultResults) != 1 || len(otherResults) != 1 {
+		t.Fatal("expected one resource per project")
+	}
+	if defaultResults[0].Project != models.DefaultProject || otherResults[0].Text != "other project" {
+		t.Error("resources were returned for the wrong project")
+	}
+}
+
+func TestUpsertResourcesFile_WhenOverwrite_ShouldUpdateExistingAndAddNew(t *testing.T) {
+	// arrange
+	repo, cleanup, err := createTestfileWithData(true, []models.Resource{})
+	defer cleanup()
+	if err != nil {
+		t.Fatal("failed to create the file")
+	}
+
+	// act
+	result, err := repo.UpsertResources(models.ConflictOverwrite, "user",
+		models.Resource{Key: "aa", LanguageCode: "es", Text: "updated", Status: models.StatusMachine, Provider: "deepl"},
+		models.Resource{Key: "cc", LanguageCode: "es", Text: "new"})
+
+	// assert
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if result.Created != 1 || result.Updated != 1 {
+		t.Errorf("expected 1 created and 1 updated but got %+v", result)
+	}
+	resources, _ := repo.GetResourcesByLanguageCode(models.DefaultProject, "es")
+	texts := map[string]models.Resource{}
+	for _, resource := range resources {
+		texts[resource.Key] = resource
+	}
+	if len(resources) != 3 || texts["aa"].Text != "updated" || texts["aa"].Provider != "deepl" || texts["bb"].Text != "val4" || texts["cc"].Text != "new" {
+		t.Errorf("unexpected resources after upsert: %+v", resources)
+	}
+}
+
+func TestExistingLanguageCodesFile_ShouldCountResourcesPerLanguage(t *testing.T) {
+	// arrange
+	repo, cleanup, err := createTestfileWithData(true, []models.Resource{{Project: "other", Key: "aa", LanguageCode: "de", Text: "other project"}})
+	defer cleanup()
+	if err != nil {
+		t.Fatal("failed to create the file")
+	}
+
+	// act
+	results, err := repo.ExistingLanguageCodes(models.DefaultProject)
+
+	// assert
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	counts := map[string]int{}
+	for _, result := range results {
+		counts[result.LanguageCode] = result.Count
+	}
+	if len(counts) != 3 || counts["en"] != 2 || counts["es"] != 2 || counts["sv"] != 1 {
+		t.Errorf("unexpected language counts: %+v", results)
+	}
+}
+
+func TestJobsFile_ShouldCreateUpdateAndGetJobs(t *testing.T) {
+	// arrange
+	repo, cleanup, err := createTestfileWithData(false, []models.Resource{})
+	defer cleanup()
+	if err != nil {
+		t.Fatal("failed to create the file")
+	}
+
+	// act
+	first, firstErr := repo.CreateJob(models.Job{Type: "TranslateLanguage", Status: models.JobQueued})
+	second, secondErr := repo.CreateJob(models.Job{Type: "FillGaps", Status: models.JobQueued})
+	first.Status, first.BatchesDone = models.JobRunning, 2
+	updateErr := repo.UpdateJob(first)
+	job, found, getErr := repo.GetJob(first.ID)
+	_, missing, _ := repo.GetJob(42)
+
+	// assert
+	if firstErr != nil || secondErr != nil || updateErr != nil || getErr != nil {
+		t.Fatal("expected the jobs to be saved")
+	}
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("expected ids 1 and 2 but got %v and %v", first.ID, second.ID)
+	}
+	if !found || job.Status != models.JobRunning || job.BatchesDone != 2 || job.CreatedAt.IsZero() {
+		t.Errorf("unexpected job after update: %+v", job)
+	}
+	if missing {
+		t.Error("expected job 42 not to be found")
+	}
+}
+
 func createTestfileWithData(withFixedData bool, data []models.Resource) (repo *ResourceFile, cleanup func(), err error) {
 	if withFixedData {
 		fixedData := []models.Resource{
@@ -132,6 +240,7 @@ func createTestfileWithData(withFixedData bool, data []models.Resource) (repo *R
 
 	cleanup = func() {
 		os.Remove(repo.File)
+		os.Remove(repo.jobsFile())
 	}
 
 	return repo, cleanup, nil
@@ -1,11 +1,13 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
 	"gotranslate/core/contracts"
 	"gotranslate/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ResourceGorm struct {
@@ -17,24 +19,172 @@ func NewResourceGorm(db *gorm.DB) *ResourceGorm {
 }
 
 var _ contracts.ResoureRepository = (*ResourceGorm)(nil)
+var _ contracts.TranslationMemory = (*ResourceGorm)(nil)
 
+// Init migrates the tables, existing resources without a project are moved to the default project by the column's default value.
+// The migration fails when resources are duplicated, they have to be resolved before the unique index is created.
 func (repo *ResourceGorm) Init() error {
-	repo.DB.AutoMigrate(&models.Resource{})
-	return nil
+	if err := repo.checkDuplicates(); err != nil {
+		return err
+	}
+
+	if err := repo.DB.AutoMigrate(&models.Project{}, &models.Resource{}, &models.Revision{}, &models.Job{}); err != nil {
+		return err
+	}
+
+	defaultProject := models.Project{Name: models.DefaultProject, TargetLanguages: []string{}}
+	return repo.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&defaultProject).Error
+}
+
+func (repo *ResourceGorm) checkDuplicates() error {
+	migrator := repo.DB.Migrator()
+	if !migrator.HasTable(&models.Resource{}) {
+		return nil
+	}
+
+	// resources from before projects existed belong to the default project
+	identity := "'default', key, languagecode"
+	if migrator.HasColumn(&models.Resource{}, "Project") {
+		identity = "project, key, languagecode"
+	}
+
+	rows, err := repo.DB.Raw(`SELECT `+identity+` FROM resources GROUP BY 1, 2, 3 HAVING COUNT(*) > 1 ORDER BY 1, 2, 3 LIMIT ?`, duplicatesLimit).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var duplicates []resourceFilter
+	for rows.Next() {
+		var duplicate resourceFilter
+		if err := rows.Scan(&duplicate.Project, &duplicate.Key, &duplicate.LanguageCode); err != nil {
+			return err
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return duplicatesError(duplicates)
 }
 
 func (repo *ResourceGorm) AddResources(resources ...models.Resource) error {
-	result := repo.DB.CreateInBatches(resources, 10)
-	if result.Error != nil {
+	result := repo.DB.CreateInBatches(withProject(resources), 10)
+	if isUniqueViolation(result.Error) {
+		return fmt.Errorf("%w: %v", contracts.ErrResourceConflict, result.Error.Error())
+	} else if result.Error != nil {
 		return result.Error
 	}
 
 	return nil
 }
 
-func (repo *ResourceGorm) GetResourcesByKey(key string) ([]models.Resource, error) {
+func (repo *ResourceGorm) UpsertResources(policy models.ConflictPolicy, username string, resources ...models.Resource) (result models.UpsertResult, err error) {
+	resources = withProject(resources)
+	if len(resources) == 0 {
+		return result, nil
+	}
+
+	identities := make([][]any, 0, len(resources))
+	for _, resource := range resources {
+		identities = append(identities, []any{resource.Project, resource.Key, resource.LanguageCode})
+	}
+
+	err = repo.DB.Transaction(func(tx *gorm.DB) error {
+		var existing []models.Resource
+		if err := tx.Where("(Project, Key, LanguageCode) IN ?", identities).Find(&existing).Error; err != nil {
+			return err
+		}
+
+		toInsert, toUpdate, planned, err := planUpsert(policy, existing, resources)
+		if err != nil {
+			return err
+		}
+
+		if len(toInsert) > 0 {
+			// rows added since the lookup are resolved by the database with the same policy
+			query := tx
+			if policy == models.ConflictOverwrite {
+				query = tx.Clauses(clause.OnConflict{
+					Columns: []clause.Column{{Name: "project"}, {Name: "key"}, {Name: "languagecode"}},
+					DoUpdates: clause.Set{
+						{Column: clause.Column{Name: "text"}, Value: gorm.Expr("excluded.text")},
+						{Column: clause.Column{Name: "description"}, Value: gorm.Expr("CASE WHEN excluded.description = '' THEN resources.description ELSE excluded.description END")},
+						{Column: clause.Column{Name: "sourcehash"}, Value: gorm.Expr("CASE WHEN excluded.sourcehash = '' THEN resources.sourcehash ELSE excluded.sourcehash END")},
+						{Column: clause.Column{Name: "provider"}, Value: gorm.Expr("CASE WHEN excluded.provider = '' THEN resources.provider ELSE excluded.provider END")},
+					},
+				})
+			} else if policy == models.ConflictKeep {
+				query = tx.Clauses(clause.OnConflict{DoNothing: true})
+			}
+
+			if err := query.CreateInBatches(toInsert, 10).Error; isUniqueViolation(err) {
+				return fmt.Errorf("%w: %v", contracts.ErrResourceConflict, err.Error())
+			} else if err != nil {
+				return err
+			}
+		}
+
+		var updated []models.Resource
+		for _, resource := range toUpdate {
+			updates := map[string]any{"text": resource.Text}
+			if resource.Description != "" {
+				updates["description"] = resource.Description
+			}
+			if resource.Status != "" {
+				updates["status"] = resource.Status
+			}
+			if resource.SourceHash != "" {
+				updates["sourcehash"] = resource.SourceHash
+			}
+			if resource.Provider != "" {
+				updates["provider"] = resource.Provider
+			}
+			if resource.Status == models.StatusEdited {
+				// edits by hand aren't machine translations of the current source text anymore
+				updates["provider"] = ""
+				sourceHash, found, err := currentSourceHash(tx, resource)
+				if err != nil {
+					return err
+				}
+				if found {
+					updates["sourcehash"] = sourceHash
+				}
+			}
+
+			query := tx.Model(&models.Resource{}).
+				Where("Project = ? AND Key = ? AND LanguageCode = ?", resource.Project, resource.Key, resource.LanguageCode)
+			if policy == models.ConflictStale {
+				// the row can have been reviewed or translated again since it was read
+				query = query.Where("SourceHash <> '' AND SourceHash <> ? AND Status IN ?", resource.SourceHash, models.RetranslatableStatuses)
+			}
+			updateResult := query.Updates(updates)
+			if updateResult.Error != nil {
+				return updateResult.Error
+			}
+			if updateResult.RowsAffected == 0 {
+				planned.Updated--
+				planned.Skipped++
+				continue
+			}
+			updated = append(updated, resource)
+		}
+
+		if err := createRevisions(tx, updateRevisions(existing, updated, username)); err != nil {
+			return err
+		}
+
+		result = planned
+		return nil
+	})
+
+	return result, err
+}
+
+func (repo *ResourceGorm) GetResourcesByKey(project, key string) ([]models.Resource, error) {
 	var resources []models.Resource
-	result := repo.DB.Where("Key = ?", key).Find(&resources)
+	result := repo.DB.Where("Project = ? AND Key = ?", project, key).Find(&resources)
 	if result.Error != nil {
 		return []models.Resource{}, result.Error
 	}
@@ -42,9 +192,9 @@ func (repo *ResourceGorm) GetResourcesByKey(key string) ([]models.Resource, erro
 	return resources, nil
 }
 
-func (repo *ResourceGorm) GetResourcesByLanguageCode(languageCode string) ([]models.Resource, error) {
+func (repo *ResourceGorm) GetResourcesByLanguageCode(project, languageCode string) ([]models.Resource, error) {
 	var resources []models.Resource
-	result := repo.DB.Where("LanguageCode = ?", languageCode).Find(&resources)
+	result := repo.DB.Where("Project = ? AND LanguageCode = ?", project, languageCode).Find(&resources)
 	if result.Error != nil {
 		return []models.Resource{}, result.Error
 	}
@@ -52,39 +202,162 @@ func (repo *ResourceGorm) GetResourcesByLanguageCode(languageCode string) ([]mod
 	return resources, nil
 }
 
-func (repo *ResourceGorm) RemoveResources(key string, languageCode string) (rowsAffected int64, err error) {
-	query := repo.DB.Where("Key = ?", key)
-	if languageCode != "" {
-		query = query.Where("LanguageCode = ?", languageCode)
+// memoryPairs joins the approved translations with their source texts, skipping the stale ones.
+func (repo *ResourceGorm) memoryPairs(sourceLanguage, targetLanguage string) *gorm.DB {
+	return repo.DB.Table("resources s").
+		Joins("JOIN resources t ON t.project = s.project AND t.key = s.key").
+		Where("s.languagecode = ? AND t.languagecode = ? AND t.status = ? AND s.text <> ''", sourceLanguage, targetLanguage, models.StatusApproved).
+		Where("(t.sourcehash = '' OR t.sourcehash = encode(sha256(convert_to(s.text, 'UTF8')), 'hex'))")
+}
+
+// FindExactMatches returns the most used approved translation of every text that has one.
+func (repo *ResourceGorm) FindExactMatches(sourceLanguage, targetLanguage string, texts []string) (map[string]string, error) {
+	var pairs []models.MemoryEntry
+	result := repo.memoryPairs(sourceLanguage, targetLanguage).
+		Select("DISTINCT ON (s.text) s.text AS source_text, t.text AS target_text").
+		Where("s.text IN ?", texts).
+		Group("s.text, t.text").
+		Order("s.text, count(*) DESC, t.text").
+		Scan(&pairs)
+	if result.Error != nil {
+		return nil, result.Error
 	}
 
-	result := query.Delete(&models.Resource{})
+	matches := map[string]string{}
+	for _, pair := range pairs {
+		matches[pair.SourceText] = pair.TargetText
+	}
+	return matches, nil
+}
+
+func (repo *ResourceGorm) FindFuzzyMatches(sourceLanguage, targetLanguage, text string, minSimilarity float64, limit int) ([]models.MemoryMatch, error) {
+	shortest, longest := models.SimilarLengths(text, minSimilarity)
+	var entries []models.MemoryEntry
+	result := repo.memoryPairs(sourceLanguage, targetLanguage).
+		Select("s.text AS source_text, t.text AS target_text").
+		Where("char_length(s.text) BETWEEN ? AND ?", shortest, longest).
+		Group("s.text, t.text").
+		Scan(&entries)
 	if result.Error != nil {
-		return 0, result.Error
+		return nil, result.Error
 	}
-	fmt.Println(result.RowsAffected)
-	return result.RowsAffected, nil
+
+	for i := range entries {
+		entries[i].SourceLanguage, entries[i].TargetLanguage = sourceLanguage, targetLanguage
+	}
+	return models.FindMemoryMatches(text, entries, minSimilarity, limit), nil
 }
 
-func (repo *ResourceGorm) UpdateResourceValues(resources ...models.Resource) (rowsAffected int64, err error) {
-	rowsAffected = 0
-	for _, resource := range resources {
-		result := repo.DB.Model(&models.Resource{}).
-			Where("Key = ? AND LanguageCode = ?", resource.Key, resource.LanguageCode).
-			Update("Text", resource.Text)
+func (repo *ResourceGorm) GetResourcesPage(project, languageCode, afterKey string, limit int) ([]models.Resource, error) {
+	var resources []models.Resource
+	result := repo.DB.Where(`Project = ? AND LanguageCode = ? AND Key COLLATE "C" > ?`, project, languageCode, afterKey).
+		Order(`Key COLLATE "C"`).
+		Limit(limit).
+		Find(&resources)
+	if result.Error != nil {
+		return []models.Resource{}, result.Error
+	}
+
+	return resources, nil
+}
+
+func (repo *ResourceGorm) RemoveResources(project, key, languageCode, username string) (rowsAffected int64, err error) {
+	err = repo.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("Project = ? AND Key = ?", project, key)
+		if languageCode != "" {
+			query = query.Where("LanguageCode = ?", languageCode)
+		}
+
+		var deleted []models.Resource
+		result := query.Clauses(clause.Returning{}).Delete(&deleted)
 		if result.Error != nil {
-			return rowsAffected, result.Error
+			return result.Error
 		}
-		rowsAffected += result.RowsAffected
+		rowsAffected = result.RowsAffected
+
+		return createRevisions(tx, deleteRevisions(deleted, username))
+	})
+
+	return rowsAffected, err
+}
+
+func (repo *ResourceGorm) UpdateResourceValues(username string, resources ...models.Resource) (rowsAffected int64, err error) {
+	rowsAffected = 0
+	err = repo.DB.Transaction(func(tx *gorm.DB) error {
+		for _, resource := range withProject(resources) {
+			identity := tx.Where("Project = ? AND Key = ? AND LanguageCode = ?", resource.Project, resource.Key, resource.LanguageCode)
+
+			var existing []models.Resource
+			if err := identity.Session(&gorm.Session{}).Clauses(clause.Locking{Strength: "UPDATE"}).Find(&existing).Error; err != nil {
+				return err
+			}
+
+			updates := map[string]any{"text": resource.Text, "status": models.StatusEdited, "provider": ""}
+			sourceHash, found, err := currentSourceHash(tx, resource)
+			if err != nil {
+				return err
+			}
+			if found {
+				updates["sourcehash"] = sourceHash
+			}
+
+			result := identity.Session(&gorm.Session{}).Model(&models.Resource{}).Updates(updates)
+			if result.Error != nil {
+				return result.Error
+			}
+			rowsAffected += result.RowsAffected
+
+			if err := createRevisions(tx, updateRevisions(existing, []models.Resource{resource}, username)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return rowsAffected, err
+}
+
+// currentSourceHash returns the hash of the text the resource is translated from, found is false for resources in the
+// source language and for projects without one.
+func currentSourceHash(tx *gorm.DB, resource models.Resource) (sourceHash string, found bool, err error) {
+	var project models.Project
+	if err := tx.Where("Name = ?", resource.Project).Limit(1).Find(&project).Error; err != nil {
+		return "", false, err
+	}
+	if project.SourceLanguage == "" || project.SourceLanguage == resource.LanguageCode {
+		return "", false, nil
 	}
 
-	return rowsAffected, nil
+	var sources []models.Resource
+	err = tx.Where("Project = ? AND Key = ? AND LanguageCode = ?", resource.Project, resource.Key, project.SourceLanguage).Limit(1).Find(&sources).Error
+	if err != nil || len(sources) == 0 {
+		return "", false, err
+	}
+	return models.SourceHash(sources[0].Text), true, nil
 }
 
-func (repo *ResourceGorm) ExistingLanguageCodes() (results []models.LanguageResult, err error) {
+func (repo *ResourceGorm) UpdateResourceStatus(resources ...models.Resource) (rowsAffected int64, err error) {
+	err = repo.DB.Transaction(func(tx *gorm.DB) error {
+		for _, resource := range withProject(resources) {
+			result := tx.Model(&models.Resource{}).
+				Where("Project = ? AND Key = ? AND LanguageCode = ?", resource.Project, resource.Key, resource.LanguageCode).
+				Update("Status", resource.Status)
+			if result.Error != nil {
+				return result.Error
+			}
+			rowsAffected += result.RowsAffected
+		}
+		return nil
+	})
+
+	return rowsAffected, err
+}
+
+func (repo *ResourceGorm) ExistingLanguageCodes(project string) (results []models.LanguageResult, err error) {
 	queryResult := repo.DB.
 		Model(&models.Resource{}).
 		Select(`languagecode as "LanguageCode", COUNT(*) as "Count"`).
+		Where("Project = ?", project).
 		Group("languagecode").
 		Scan(&results)
 	if queryResult.Error != nil {
@@ -93,3 +366,123 @@ func (repo *ResourceGorm) ExistingLanguageCodes() (results []models.LanguageResu
 
 	return results, nil
 }
+
+func (repo *ResourceGorm) GetProjects() ([]models.Project, error) {
+	var projects []models.Project
+	result := repo.DB.Order("Name").Find(&projects)
+	if result.Error != nil {
+		return []models.Project{}, result.Error
+	}
+
+	return projects, nil
+}
+
+func (repo *ResourceGorm) GetProject(name string) (project models.Project, found bool, err error) {
+	result := repo.DB.Where("Name = ?", name).First(&project)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return project, false, nil
+	} else if result.Error != nil {
+		return project, false, result.Error
+	}
+
+	return project, true, nil
+}
+
+func (repo *ResourceGorm) SaveProject(project models.Project) error {
+	return repo.DB.Save(&project).Error
+}
+
+func (repo *ResourceGorm) GetRevisions(project, key, languageCode string) ([]models.Revision, error) {
+	query := repo.DB.Where("Project = ? AND Key = ?", project, key)
+	if languageCode != "" {
+		query = query.Where("LanguageCode = ?", languageCode)
+	}
+
+	var revisions []models.Revision
+	if err := query.Order("id DESC").Find(&revisions).Error; err != nil {
+		return []models.Revision{}, err
+	}
+
+	return revisions, nil
+}
+
+func (repo *ResourceGorm) RollbackRevision(project string, revisionID int64, username string) (rollback models.Revision, err error) {
+	err = repo.DB.Transaction(func(tx *gorm.DB) error {
+		var revision models.Revision
+		result := tx.Where("ID = ? AND Project = ?", revisionID, project).First(&revision)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return contracts.ErrRevisionNotFound
+		} else if result.Error != nil {
+			return result.Error
+		}
+		if revision.Action == models.RevisionRestored {
+			return contracts.ErrNothingToRollBack
+		}
+
+		var existing []models.Resource
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("Project = ? AND Key = ? AND LanguageCode = ?", revision.Project, revision.Key, revision.LanguageCode).
+			Find(&existing).Error
+		if err != nil {
+			return err
+		}
+
+		// a rollback is a manual edit of the text, so it's no longer a machine translation of an older source text
+		resource := models.Resource{Project: revision.Project, Key: revision.Key, LanguageCode: revision.LanguageCode, Text: revision.OldText, Status: models.StatusEdited}
+		sourceHash, found, err := currentSourceHash(tx, resource)
+		if err != nil {
+			return err
+		}
+		if found {
+			resource.SourceHash = sourceHash
+		}
+
+		if len(existing) == 0 {
+			rollback = rollbackRevision(revision, models.Resource{}, false, username)
+			err = tx.Create(&resource).Error
+		} else {
+			rollback = rollbackRevision(revision, existing[0], true, username)
+			updates := map[string]any{"text": resource.Text, "status": resource.Status, "provider": ""}
+			if found {
+				updates["sourcehash"] = sourceHash
+			}
+			err = tx.Model(&models.Resource{}).
+				Where("Project = ? AND Key = ? AND LanguageCode = ?", revision.Project, revision.Key, revision.LanguageCode).
+				Updates(updates).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&rollback).Error
+	})
+
+	return rollback, err
+}
+
+func createRevisions(tx *gorm.DB, revisions []models.Revision) error {
+	if len(revisions) == 0 {
+		return nil
+	}
+	return tx.Create(&revisions).Error
+}
+
+func (repo *ResourceGorm) CreateJob(job models.Job) (models.Job, error) {
+	err := repo.DB.Create(&job).Error
+	return job, err
+}
+
+func (repo *ResourceGorm) UpdateJob(job models.Job) error {
+	return repo.DB.Save(&job).Error
+}
+
+func (repo *ResourceGorm) GetJob(id int64) (job models.Job, found bool, err error) {
+	result := repo.DB.Where("ID = ?", id).First(&job)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return job, false, nil
+	} else if result.Error != nil {
+		return job, false, result.Error
+	}
+
+	return job, true, nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"gotranslate/core/contracts"
 	"gotranslate/models"
 	"gotranslate/testutils"
 	"testing"
@@ -17,6 +18,8 @@ var testData = []models.Resource{
 	{Key: "key3", LanguageCode: "en", Text: "text 6"},
 }
 
+const testUsername = "tester"
+
 func TestGetResourcesByLanguageCode_ShouldGetCorrectResults(t *testing.T) {
 	languageCodeUnderTest, expectedResultsCount := "en", 3
 	db, teardown := testutils.SpinUpContainer(t)
@@ -25,7 +28,7 @@ func TestGetResourcesByLanguageCode_ShouldGetCorrectResults(t *testing.T) {
 	repo.Init()
 	db.Create(&testData)
 
-	results, err := repo.GetResourcesByLanguageCode(languageCodeUnderTest)
+	results, err := repo.GetResourcesByLanguageCode(models.DefaultProject, languageCodeUnderTest)
 
 	assert.NoError(t, err)
 	assert.Len(t, results, expectedResultsCount)
@@ -42,7 +45,7 @@ func TestGetResourcesByKey_ShouldGetCorrectResults(t *testing.T) {
 	repo.Init()
 	db.Create(&testData)
 
-	results, err := repo.GetResourcesByKey(keyUnderTest)
+	results, err := repo.GetResourcesByKey(models.DefaultProject, keyUnderTest)
 
 	assert.NoError(t, err)
 	assert.Len(t, results, expectedResultsCount)
@@ -58,7 +61,7 @@ func TestExistingLanguageCodes_ShouldReturn3(t *testing.T) {
 	repo.Init()
 	db.Create(&testData)
 
-	results, err := repo.ExistingLanguageCodes()
+	results, err := repo.ExistingLanguageCodes(models.DefaultProject)
 
 	assert.NoError(t, err)
 	assert.Len(t, results, 3)
@@ -67,10 +70,10 @@ func TestExistingLanguageCodes_ShouldReturn3(t *testing.T) {
 func TestAddResources_WhenManyResources_ShouldSucceedAndResourceShouldBeWrittenInTable(t *testing.T) {
 	// arrange
 	data := []models.Resource{
-		{Key: "testKey1", LanguageCode: "en", Text: "test text1"},
-		{Key: "testKey2", LanguageCode: "en", Text: "test text2"},
-		{Key: "testKey3", LanguageCode: "en", Text: "test text3"},
-		{Key: "testKey4", LanguageCode: "en", Text: "test text4"},
+		{Project: models.DefaultProject, Key: "testKey1", LanguageCode: "en", Text: "test text1", Status: models.StatusUntranslated},
+		{Project: models.DefaultProject, Key: "testKey2", LanguageCode: "en", Text: "test text2", Status: models.StatusUntranslated},
+		{Project: models.DefaultProject, Key: "testKey3", LanguageCode: "en", Text: "test text3", Status: models.StatusUntranslated},
+		{Project: models.DefaultProject, Key: "testKey4", LanguageCode: "en", Text: "test text4", Status: models.StatusUntranslated},
 	}
 	db, teardown := testutils.SpinUpContainer(t)
 	defer teardown()
@@ -126,7 +129,7 @@ func TestUpdateResourceValues_ShouldChangeText(t *testing.T) {
 
 	// act
 	resourceUnderTest.Text = expectedText
-	rowsAffected, err := repo.UpdateResourceValues(resourceUnderTest)
+	rowsAffected, err := repo.UpdateResourceValues(testUsername, resourceUnderTest)
 
 	// assert
 	assert.NoError(t, err)
@@ -152,7 +155,7 @@ func TestRemoveResources_WhenExistsInDb_ShouldBeDeleted(t *testing.T) {
 	var totalResultsFoundBefore, totalResultsFoundAfter int64
 	fetchQuery.Count(&totalResultsFoundBefore)
 
-	rowsAffected, err := repo.RemoveResources(keyUnderTest, languageCodeUnderTest)
+	rowsAffected, err := repo.RemoveResources(models.DefaultProject, keyUnderTest, languageCodeUnderTest, testUsername)
 
 	assert.Equal(t, totalResultsFoundBefore, int64(1))
 	assert.NoError(t, err)
@@ -160,3 +163,337 @@ func TestRemoveResources_WhenExistsInDb_ShouldBeDeleted(t *testing.T) {
 	fetchQuery.Count(&totalResultsFoundAfter)
 	assert.NotEqual(t, totalResultsFoundBefore, totalResultsFoundAfter)
 }
+
+func TestGetResourcesByKey_WhenKeyExistsInOtherProject_ShouldOnlyGetResourcesOfProject(t *testing.T) {
+	// arrange
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := NewResourceGorm(db)
+	repo.Init()
+	repo.SaveProject(models.Project{Name: "other", TargetLanguages: []string{}})
+	db.Create(&testData)
+	repo.AddResources(models.Resource{Project: "other", Key: "key1", LanguageCode: "en", Text: "other text"})
+
+	// act
+	results, err := repo.GetResourcesByKey("other", "key1")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "other text", results[0].Text)
+}
+
+func TestInit_ShouldCreateDefaultProject(t *testing.T) {
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := NewResourceGorm(db)
+
+	err := repo.Init()
+	project, found, getErr := repo.GetProject(models.DefaultProject)
+
+	assert.NoError(t, err)
+	assert.NoError(t, getErr)
+	assert.True(t, found)
+	assert.Equal(t, models.DefaultProject, project.Name)
+}
+
+func TestInit_WhenResourcesDuplicated_ShouldFailListingThem(t *testing.T) {
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := NewResourceGorm(db)
+	db.Exec(`CREATE TABLE resources (key TEXT NOT NULL, languagecode TEXT NOT NULL, text TEXT NOT NULL)`)
+	db.Exec(`INSERT INTO resources (key, languagecode, text) VALUES ('hello', 'en', 'Hello'), ('hello', 'en', 'Hi'), ('bye', 'en', 'Bye')`)
+
+	err := repo.Init()
+
+	assert.ErrorContains(t, err, "default/hello (en)")
+	assert.NotContains(t, err.Error(), "bye")
+}
+
+func TestAddResources_WhenResourceExists_ShouldReturnConflict(t *testing.T) {
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := NewResourceGorm(db)
+	repo.Init()
+	db.Create(&testData)
+
+	err := repo.AddResources(models.Resource{Key: testData[0].Key, LanguageCode: testData[0].LanguageCode, Text: "duplicate"})
+
+	assert.ErrorIs(t, err, contracts.ErrResourceConflict)
+}
+
+func TestUpsertResources_WhenOverwrite_ShouldCreateAndUpdate(t *testing.T) {
+	// arrange
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := NewResourceGorm(db)
+	repo.Init()
+	db.Create(&testData)
+	resources := []models.Resource{
+		{Key: testData[0].Key, LanguageCode: testData[0].LanguageCode, Text: "changed"},
+		{Key: "key4", LanguageCode: "en", Text: "new"},
+	}
+
+	// act
+	result, err := repo.UpsertResources(models.ConflictOverwrite, testUsername, resources...)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.UpsertResult{Created: 1, Updated: 1}, result)
+	updated, _ := repo.GetResourcesByKey(models.DefaultProject, testData[0].Key)
+	assert.Contains(t, updated, models.Resource{Project: models.DefaultProject, Key: testData[0].Key, LanguageCode: testData[0].LanguageCode, Text: "changed", Status: models.StatusUntranslated})
+}
+
+func TestUpsertResources_WhenKeep_ShouldNotChangeExisting(t *testing.T) {
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := NewResourceGorm(db)
+	repo.Init()
+	db.Create(&testData)
+
+	result, err := repo.UpsertResources(models.ConflictKeep, testUsername, models.Resource{Key: testData[0].Key, LanguageCode: testData[0].LanguageCode, Text: "changed"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), result.Skipped)
+	existing, _ := repo.GetResourcesByLanguageCode(models.DefaultProject, testData[0].LanguageCode)
+	assert.Contains(t, existing, models.Resource{Project: models.DefaultProject, Key: testData[0].Key, LanguageCode: testData[0].LanguageCode, Text: testData[0].Text, Status: models.StatusUntranslated})
+}
+
+func TestUpdateResourceValues_WhenMachineTranslation_ShouldResetItAsManualEdit(t *testing.T) {
+	// arrange
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := NewResourceGorm(db)
+	repo.Init()
+	repo.SaveProject(models.Project{Name: "webapp", SourceLanguage: "en", TargetLanguages: []string{}})
+	db.Create(&[]models.Resource{
+		{Project: "webapp", Key: "title", LanguageCode: "en", Text: "New title", Status: models.StatusUntranslated},
+		{Project: "webapp", Key: "title", LanguageCode: "de", Text: "Titel", Status: models.StatusMachine, Provider: "deepl", SourceHash: models.SourceHash("Old title")},
+	})
+
+	// act
+	_, err := repo.UpdateResourceValues(testUsername, models.Resource{Project: "webapp", Key: "title", LanguageCode: "de", Text: "Neuer Titel"})
+
+	// assert
+	assert.NoError(t, err)
+	results, _ := repo.GetResourcesByLanguageCode("webapp", "de")
+	assert.Len(t, results, 1)
+	assert.Equal(t, "Neuer Titel", results[0].Text)
+	assert.Equal(t, models.StatusEdited, results[0].Status)
+	assert.Equal(t, "", results[0].Provider)
+	assert.Equal(t, models.SourceHash("New title"), results[0].SourceHash)
+}
+
+func TestUpsertResources_WhenStaleAndReviewedSinceScan_ShouldKeepIt(t *testing.T) {
+	// arrange
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := NewResourceGorm(db)
+	repo.Init()
+	db.Create(&[]models.Resource{
+		{Key: "title", LanguageCode: "de", Text: "Titel", Status: models.StatusMachine, SourceHash: models.SourceHash("Old title")},
+		{Key: "subtitle", LanguageCode: "de", Text: "Untertitel", Status: models.StatusApproved, SourceHash: models.SourceHash("Old subtitle")},
+	})
+
+	// act
+	result, err := repo.UpsertResources(models.ConflictStale, testUsername,
+		models.Resource{Key: "title", LanguageCode: "de", Text: "Neuer Titel", Status: models.StatusMachine, SourceHash: models.SourceHash("New title")},
+		models.Resource{Key: "subtitle", LanguageCode: "de", Text: "Neuer Untertitel", Status: models.StatusMachine, SourceHash: models.SourceHash("New subtitle")})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.UpsertResult{Updated: 1, Skipped: 1}, result)
+	subtitle, _ := repo.GetResourcesByKey(models.DefaultProject, "subtitle")
+	assert.Equal(t, "Untertitel", subtitle[0].Text)
+	assert.Equal(t, models.StatusApproved, subtitle[0].Status)
+}
+
+func TestUpsertResources_WhenTextOverwrittenByHand_ShouldResetItAsManualEdit(t *testing.T) {
+	// arrange
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := NewResourceGorm(db)
+	repo.Init()
+	repo.SaveProject(models.Project{Name: "webapp", SourceLanguage: "en", TargetLanguages: []string{}})
+	db.Create(&[]models.Resource{
+		{Project: "webapp", Key: "title", LanguageCode: "en", Text: "New title", Status: models.StatusUntranslated},
+		{Project: "webapp", Key: "title", LanguageCode: "de", Text: "Titel", Status: models.StatusApproved, Provider: "deepl", SourceHash: models.SourceHash("Old title")},
+	})
+
+	// act
+	_, err := repo.UpsertResources(models.ConflictOverwrite, testUsername, models.Resource{Project: "webapp", Key: "title", LanguageCode: "de", Text: "Neuer Titel"})
+
+	// assert
+	assert.NoError(t, err)
+	results, _ := repo.GetResourcesByLanguageCode("webapp", "de")
+	assert.Len(t, results, 1)
+	assert.Equal(t, models.StatusEdited, results[0].Status)
+	assert.Equal(t, "", results[0].Provider)
+	assert.Equal(t, models.SourceHash("New title"), results[0].SourceHash)
+}
+
+func TestUpdateResourceValues_ShouldRecordRevisionWithUsername(t *testing.T) {
+	// arrange
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := NewResourceGorm(db)
+	repo.Init()
+	db.Create(&testData)
+	resource := models.Resource{Key: testData[0].Key, LanguageCode: testData[0].LanguageCode, Text: "changed"}
+
+	// act
+	_, err := repo.UpdateResourceValues(testUsername, resource)
+	revisions, historyErr := repo.GetRevisions(models.DefaultProject, resource.Key, resource.LanguageCode)
+
+	// assert
+	assert.NoError(t, err)
+	assert.NoError(t, historyErr)
+	assert.Len(t, revisions, 1)
+	assert.Equal(t, models.RevisionUpdated, revisions[0].Action)
+	assert.Equal(t, testData[0].Text, revisions[0].OldText)
+	assert.Equal(t, "changed", revisions[0].NewText)
+	assert.Equal(t, testUsername, revisions[0].Username)
+}
+
+func TestRollbackRevision_WhenResourceWasDeleted_ShouldRestoreIt(t *testing.T) {
+	// arrange
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := NewResourceGorm(db)
+	repo.Init()
+	db.Create(&testData)
+	repo.RemoveResources(models.DefaultProject, testData[0].Key, testData[0].LanguageCode, testUsername)
+	revisions, _ := repo.GetRevisions(models.DefaultProject, testData[0].Key, testData[0].LanguageCode)
+
+	// act
+	rollback, err := repo.RollbackRevision(models.DefaultProject, revisions[0].ID, testUsername)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.RevisionRestored, rollback.Action)
+	restored, _ := repo.GetResourcesByKey(models.DefaultProject, testData[0].Key)
+	assert.Contains(t, restored, models.Resource{Project: models.DefaultProject, Key: testData[0].Key, LanguageCode: testData[0].LanguageCode, Text: testData[0].Text, Status: models.StatusEdited})
+}
+
+func TestRollbackRevision_WhenMachineTranslation_ShouldResetItAsManualEdit(t *testing.T) {
+	// arrange
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := NewResourceGorm(db)
+	repo.Init()
+	repo.SaveProject(models.Project{Name: "webapp", SourceLanguage: "en", TargetLanguages: []string{}})
+	db.Create(&[]models.Resource{
+		{Project: "webapp", Key: "title", LanguageCode: "en", Text: "New title", Status: models.StatusUntranslated},
+		{Project: "webapp", Key: "title", LanguageCode: "de", Text: "Titel", Status: models.StatusReviewed},
+	})
+	repo.UpdateResourceValues(testUsername, models.Resource{Project: "webapp", Key: "title", LanguageCode: "de", Text: "Neuer Titel"})
+	db.Model(&models.Resource{}).Where("Project = ? AND Key = ? AND LanguageCode = ?", "webapp", "title", "de").
+		Updates(map[string]any{"status": models.StatusMachine, "provider": "deepl", "sourcehash": models.SourceHash("Old title")})
+	revisions, _ := repo.GetRevisions("webapp", "title", "de")
+
+	// act
+	_, err := repo.RollbackRevision("webapp", revisions[0].ID, testUsername)
+
+	// assert
+	assert.NoError(t, err)
+	results, _ := repo.GetResourcesByLanguageCode("webapp", "de")
+	assert.Len(t, results, 1)
+	assert.Equal(t, "Titel", results[0].Text)
+	assert.Equal(t, models.StatusEdited, results[0].Status)
+	assert.Equal(t, "", results[0].Provider)
+	assert.Equal(t, models.SourceHash("New title"), results[0].SourceHash)
+}
+
+func TestRollbackRevision_WhenRevisionInOtherProject_ShouldReturnNotFound(t *testing.T) {
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := NewResourceGorm(db)
+	repo.Init()
+	db.Create(&testData)
+	repo.UpdateResourceValues(testUsername, models.Resource{Key: testData[0].Key, LanguageCode: testData[0].LanguageCode, Text: "changed"})
+	revisions, _ := repo.GetRevisions(models.DefaultProject, testData[0].Key, testData[0].LanguageCode)
+
+	_, err := repo.RollbackRevision("other", revisions[0].ID, testUsername)
+
+	assert.ErrorIs(t, err, contracts.ErrRevisionNotFound)
+}
+
+func TestUpdateResourceStatus_ShouldOnlyChangeStatus(t *testing.T) {
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := NewResourceGorm(db)
+	repo.Init()
+	db.Create(&testData)
+
+	rowsAffected, err := repo.UpdateResourceStatus(models.Resource{Key: testData[0].Key, LanguageCode: testData[0].LanguageCode, Status: models.StatusReviewed})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rowsAffected)
+	results, _ := repo.GetResourcesByKey(models.DefaultProject, testData[0].Key)
+	assert.Contains(t, results, models.Resource{Project: models.DefaultProject, Key: testData[0].Key, LanguageCode: testData[0].LanguageCode, Text: testData[0].Text, Status: models.StatusReviewed})
+}
+
+func TestGetResourcesPage_ShouldReturnKeysAfterTheLastOne(t *testing.T) {
+	// arrange
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := NewResourceGorm(db)
+	repo.Init()
+	db.Create(&testData)
+
+	// act
+	firstPage, err := repo.GetResourcesPage(models.DefaultProject, "en", "", 2)
+	secondPage, _ := repo.GetResourcesPage(models.DefaultProject, "en", firstPage[len(firstPage)-1].Key, 2)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"key1", "key2"}, []string{firstPage[0].Key, firstPage[1].Key})
+	assert.Len(t, secondPage, 1)
+	assert.Equal(t, "key3", secondPage[0].Key)
+}
+
+func TestFindExactMatches_ShouldOnlyUseApprovedTranslationsOfCurrentSourceTexts(t *testing.T) {
+	// arrange
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := NewResourceGorm(db)
+	repo.Init()
+	db.Create(&[]models.Resource{
+		{Project: models.DefaultProject, Key: "save", LanguageCode: "en", Text: "Save"},
+		{Project: models.DefaultProject, Key: "save", LanguageCode: "de", Text: "Speichern", Status: models.StatusApproved},
+		{Project: models.DefaultProject, Key: "cancel", LanguageCode: "en", Text: "Cancel"},
+		{Project: models.DefaultProject, Key: "cancel", LanguageCode: "de", Text: "Abbrechen", Status: models.StatusMachine},
+		{Project: "other", Key: "close", LanguageCode: "en", Text: "Close"},
+		{Project: "other", Key: "close", LanguageCode: "de", Text: "Zumachen", Status: models.StatusApproved, SourceHash: models.SourceHash("Shut")},
+	})
+
+	// act
+	matches, err := repo.FindExactMatches("en", "de", []string{"Save", "Cancel", "Close"})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"Save": "Speichern"}, matches)
+}
+
+func TestFindFuzzyMatches_ShouldReturnSimilarTextsFromEveryProject(t *testing.T) {
+	// arrange
+	db, teardown := testutils.SpinUpContainer(t)
+	defer teardown()
+	repo := NewResourceGorm(db)
+	repo.Init()
+	db.Create(&[]models.Resource{
+		{Project: models.DefaultProject, Key: "save", LanguageCode: "en", Text: "Save changes"},
+		{Project: models.DefaultProject, Key: "save", LanguageCode: "de", Text: "Änderungen speichern", Status: models.StatusApproved},
+		{Project: "other", Key: "delete", LanguageCode: "en", Text: "Delete account"},
+		{Project: "other", Key: "delete", LanguageCode: "de", Text: "Konto löschen", Status: models.StatusApproved},
+	})
+
+	// act
+	matches, err := repo.FindFuzzyMatches("en", "de", "Save change", 0.75, 5)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, "Änderungen speichern", matches[0].TargetText)
+	assert.Less(t, matches[0].Similarity, 1.0)
+}
@@ -8,6 +8,7 @@ import (
 	"gotranslate/core/contracts"
 	"gotranslate/models"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -21,6 +22,7 @@ func NewResourceSql(pool *pgxpool.Pool) *ResourceSql {
 }
 
 var _ contracts.ResoureRepository = (*ResourceSql)(nil)
+var _ contracts.TranslationMemory = (*ResourceSql)(nil)
 
 func (repo *ResourceSql) Init() error {
 	createTableQuery := `
@@ -30,95 +32,336 @@ func (repo *ResourceSql) Init() error {
 			Text TEXT NOT NULL,
 			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
 		);
+		ALTER TABLE resources ADD COLUMN IF NOT EXISTS Description TEXT NOT NULL DEFAULT '';
+		ALTER TABLE resources ADD COLUMN IF NOT EXISTS Status TEXT NOT NULL DEFAULT 'untranslated';
+		ALTER TABLE resources ADD COLUMN IF NOT EXISTS Project TEXT NOT NULL DEFAULT 'default';
+		ALTER TABLE resources ADD COLUMN IF NOT EXISTS SourceHash TEXT NOT NULL DEFAULT '';
+		ALTER TABLE resources ADD COLUMN IF NOT EXISTS Provider TEXT NOT NULL DEFAULT '';
+		CREATE TABLE IF NOT EXISTS projects (
+			Name TEXT PRIMARY KEY,
+			SourceLanguage TEXT NOT NULL DEFAULT '',
+			TargetLanguages TEXT NOT NULL DEFAULT '[]'
+		);
+		ALTER TABLE projects ADD COLUMN IF NOT EXISTS RetranslateStale BOOLEAN NOT NULL DEFAULT false;
+		INSERT INTO projects (Name) VALUES ('default') ON CONFLICT DO NOTHING;
+	`
+	identityQuery := `
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_resources_identity ON resources (Project, Key, LanguageCode);
+		CREATE TABLE IF NOT EXISTS resource_revisions (
+			ID BIGSERIAL PRIMARY KEY,
+			Project TEXT NOT NULL,
+			Key TEXT NOT NULL,
+			LanguageCode TEXT NOT NULL,
+			Action TEXT NOT NULL,
+			OldText TEXT NOT NULL,
+			NewText TEXT NOT NULL,
+			Username TEXT NOT NULL,
+			CreatedAt TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+		CREATE INDEX IF NOT EXISTS idx_revisions_resource ON resource_revisions (Project, Key, LanguageCode);
+		CREATE TABLE IF NOT EXISTS jobs (
+			ID BIGSERIAL PRIMARY KEY,
+			Type TEXT NOT NULL,
+			Project TEXT NOT NULL,
+			SourceLanguage TEXT NOT NULL,
+			TargetLanguage TEXT NOT NULL,
+			Username TEXT NOT NULL,
+			Status TEXT NOT NULL,
+			BatchesDone INT NOT NULL DEFAULT 0,
+			BatchesTotal INT NOT NULL DEFAULT 0,
+			Created BIGINT NOT NULL DEFAULT 0,
+			Skipped BIGINT NOT NULL DEFAULT 0,
+			Error TEXT NOT NULL DEFAULT '',
+			CreatedAt TIMESTAMPTZ NOT NULL DEFAULT now(),
+			UpdatedAt TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
 	`
 
 	_, err := repo.Pool.Exec(context.Background(), createTableQuery)
 	if err != nil {
 		return err
 	}
+
+	if err := repo.checkDuplicates(); err != nil {
+		return err
+	}
+
+	_, err = repo.Pool.Exec(context.Background(), identityQuery)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
-func (repo *ResourceSql) GetResourcesByLanguageCode(languageCode string) ([]models.Resource, error) {
-	return repo.getResources(resourceFilter{LanguageCode: languageCode})
+// checkDuplicates fails when resources are duplicated, they have to be resolved before the unique index is created.
+func (repo *ResourceSql) checkDuplicates() error {
+	query := `SELECT Project, Key, LanguageCode FROM resources GROUP BY Project, Key, LanguageCode HAVING COUNT(*) > 1
+		ORDER BY Project, Key, LanguageCode LIMIT $1`
+	rows, err := repo.Pool.Query(context.Background(), query, duplicatesLimit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var duplicates []resourceFilter
+	for rows.Next() {
+		var duplicate resourceFilter
+		if err := rows.Scan(&duplicate.Project, &duplicate.Key, &duplicate.LanguageCode); err != nil {
+			return err
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return duplicatesError(duplicates)
 }
 
-func (repo *ResourceSql) GetResourcesByKey(key string) ([]models.Resource, error) {
-	return repo.getResources(resourceFilter{Key: key})
+func (repo *ResourceSql) GetResourcesByLanguageCode(project, languageCode string) ([]models.Resource, error) {
+	return repo.getResources(resourceFilter{Project: project, LanguageCode: languageCode})
+}
+
+func (repo *ResourceSql) GetResourcesByKey(project, key string) ([]models.Resource, error) {
+	return repo.getResources(resourceFilter{Project: project, Key: key})
 }
 
 func (repo *ResourceSql) AddResources(resources ...models.Resource) error {
-	sqlStatement := `INSERT INTO resources (Key, LanguageCode, Text) VALUES `
-	columns := 3
-	params := []interface{}{}
+	sqlStatement, params := generateInsert(resources, "")
+
+	_, err := repo.Pool.Exec(context.Background(), sqlStatement, params...)
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: %v", contracts.ErrResourceConflict, err.Error())
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// generateInsert creates a multi row insert, onConflict is appended as is (e.g. "ON CONFLICT DO NOTHING").
+func generateInsert(resources []models.Resource, onConflict string) (sqlStatement string, params []interface{}) {
+	sqlStatement = `INSERT INTO resources (Project, Key, LanguageCode, Text, Description, Status, SourceHash, Provider) VALUES `
+	columns := 8
 	totalResources := len(resources)
 
-	for i, resource := range resources {
-		sqlStatement += fmt.Sprintf("($%d, $%d, $%d)", i*columns+1, i*columns+2, i*columns+3)
+	for i, resource := range withProject(resources) {
+		sqlStatement += fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", i*columns+1, i*columns+2, i*columns+3, i*columns+4, i*columns+5, i*columns+6, i*columns+7, i*columns+8)
 		if i+1 < totalResources {
 			sqlStatement += ", "
-		} else {
-			sqlStatement += ";"
 		}
-		param := []interface{}{resource.Key, resource.LanguageCode, resource.Text}
+		if resource.Status == "" {
+			resource.Status = models.StatusUntranslated
+		}
+		param := []interface{}{resource.Project, resource.Key, resource.LanguageCode, resource.Text, resource.Description, resource.Status, resource.SourceHash, resource.Provider}
 		params = append(params, param...)
 	}
 
-	_, err := repo.Pool.Exec(context.Background(), sqlStatement, params...)
-	if err != nil {
-		return err
+	if onConflict != "" {
+		sqlStatement += " " + onConflict
 	}
-
-	return nil
+	return sqlStatement + ";", params
 }
 
-func (repo *ResourceSql) UpdateResourceValues(resources ...models.Resource) (rowsAffected int64, err error) {
-	type sqlWithParams struct {
-		sqlStatement string
-		params       []interface{}
+func (repo *ResourceSql) UpsertResources(policy models.ConflictPolicy, username string, resources ...models.Resource) (result models.UpsertResult, err error) {
+	resources = withProject(resources)
+	if len(resources) == 0 {
+		return result, nil
 	}
 
-	generatedUpdates := []sqlWithParams{}
+	ctx := context.Background()
+	tx, err := repo.Pool.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(ctx)
+
+	filters := make([]resourceFilter, 0, len(resources))
 	for _, resource := range resources {
-		update := sqlWithParams{
-			"UPDATE resources SET Text = $1 WHERE Key = $2 AND LanguageCode = $3;",
-			[]interface{}{resource.Text, resource.Key, resource.LanguageCode},
+		filters = append(filters, identityOf(resource))
+	}
+	query, params, err := generateQueryAndParameters(filters...)
+	if err != nil {
+		return result, err
+	}
+	rows, err := tx.Query(ctx, query, params...)
+	if err != nil {
+		return result, err
+	}
+	existing, err := scanResources(rows)
+	if err != nil {
+		return result, err
+	}
+
+	toInsert, toUpdate, result, err := planUpsert(policy, existing, resources)
+	if err != nil {
+		return models.UpsertResult{}, err
+	}
+
+	if len(toInsert) > 0 {
+		// rows added since the lookup are resolved by the database with the same policy
+		onConflict := ""
+		if policy == models.ConflictOverwrite {
+			onConflict = "ON CONFLICT (Project, Key, LanguageCode) DO UPDATE SET Text = EXCLUDED.Text, " +
+				"Description = CASE WHEN EXCLUDED.Description = '' THEN resources.Description ELSE EXCLUDED.Description END, " +
+				"SourceHash = CASE WHEN EXCLUDED.SourceHash = '' THEN resources.SourceHash ELSE EXCLUDED.SourceHash END, " +
+				"Provider = CASE WHEN EXCLUDED.Provider = '' THEN resources.Provider ELSE EXCLUDED.Provider END"
+		} else if policy == models.ConflictKeep {
+			onConflict = "ON CONFLICT DO NOTHING"
+		}
+
+		sqlStatement, params := generateInsert(toInsert, onConflict)
+		if _, err := tx.Exec(ctx, sqlStatement, params...); isUniqueViolation(err) {
+			return models.UpsertResult{}, fmt.Errorf("%w: %v", contracts.ErrResourceConflict, err.Error())
+		} else if err != nil {
+			return models.UpsertResult{}, err
 		}
-		generatedUpdates = append(generatedUpdates, update)
 	}
 
-	for i, update := range generatedUpdates {
-		cmd, err := repo.Pool.Exec(context.Background(), update.sqlStatement, update.params...)
+	var updated []models.Resource
+	for _, resource := range toUpdate {
+		sqlStatement := `
+			UPDATE resources SET Text = $1,
+				Description = CASE WHEN $2 = '' THEN Description ELSE $2 END,
+				Status = CASE WHEN $3 = '' THEN Status ELSE $3 END,
+				SourceHash = CASE WHEN $4 = '' THEN SourceHash ELSE $4 END,
+				Provider = CASE WHEN $5 = '' THEN Provider ELSE $5 END
+			WHERE Project = $6 AND Key = $7 AND LanguageCode = $8`
+		params := []any{resource.Text, resource.Description, resource.Status, resource.SourceHash, resource.Provider, resource.Project, resource.Key, resource.LanguageCode}
+		if resource.Status == models.StatusEdited {
+			// edits by hand aren't machine translations of the current source text anymore
+			sqlStatement = `
+				UPDATE resources r SET Text = $1,
+					Description = CASE WHEN $2 = '' THEN r.Description ELSE $2 END,
+					Status = $3, Provider = '', SourceHash = ` + currentSourceHashSQL + `
+				WHERE r.Project = $4 AND r.Key = $5 AND r.LanguageCode = $6`
+			params = []any{resource.Text, resource.Description, resource.Status, resource.Project, resource.Key, resource.LanguageCode}
+		} else if policy == models.ConflictStale {
+			// the row can have been reviewed or translated again since it was read
+			sqlStatement += " AND SourceHash <> '' AND SourceHash <> $4 AND Status = ANY($9)"
+			params = append(params, models.RetranslatableStatuses)
+		}
+		tag, err := tx.Exec(ctx, sqlStatement, params...)
 		if err != nil {
-			shouldUpdateCount := len(resources)
-			if i > 0 {
-				return rowsAffected, fmt.Errorf("partially updated %d/%d rows. Error: %v", i, shouldUpdateCount, err.Error())
-			} else {
-				return rowsAffected, fmt.Errorf("no entries were updated. Error: %v", err.Error())
-			}
+			return models.UpsertResult{}, err
 		}
-		rowsAffected += cmd.RowsAffected()
+		if tag.RowsAffected() == 0 {
+			result.Updated--
+			result.Skipped++
+			continue
+		}
+		updated = append(updated, resource)
+	}
+
+	if err := insertRevisions(ctx, tx, updateRevisions(existing, updated, username)); err != nil {
+		return models.UpsertResult{}, err
+	}
+
+	return result, tx.Commit(ctx)
+}
+
+// currentSourceHashSQL is the hash of the text in the source language with the key of the resource r, it keeps the hash of
+// resources in the source language and of projects without one.
+const currentSourceHashSQL = `COALESCE((
+	SELECT encode(sha256(convert_to(s.Text, 'UTF8')), 'hex') FROM resources s JOIN projects p ON p.Name = s.Project
+	WHERE s.Project = r.Project AND s.Key = r.Key AND s.LanguageCode = p.SourceLanguage AND s.LanguageCode <> r.LanguageCode
+), r.SourceHash)`
+
+func (repo *ResourceSql) UpdateResourceValues(username string, resources ...models.Resource) (rowsAffected int64, err error) {
+	ctx := context.Background()
+	tx, err := repo.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	sqlStatement := `
+		WITH old AS (
+			SELECT ctid, Text FROM resources WHERE Key = $2 AND LanguageCode = $3 AND Project = $4 FOR UPDATE
+		)
+		UPDATE resources r SET Text = $1, Status = $5, Provider = '',
+			SourceHash = ` + currentSourceHashSQL + `
+		FROM old WHERE r.ctid = old.ctid
+		RETURNING r.Project, r.Key, r.LanguageCode, old.Text, r.Description, r.Status, r.SourceHash, r.Provider;`
+
+	var revisions []models.Revision
+	for _, resource := range withProject(resources) {
+		rows, err := tx.Query(ctx, sqlStatement, resource.Text, resource.Key, resource.LanguageCode, resource.Project, models.StatusEdited)
+		if err != nil {
+			return 0, fmt.Errorf("no entries were updated. Error: %v", err.Error())
+		}
+		existing, err := scanResources(rows)
+		if err != nil {
+			return 0, fmt.Errorf("no entries were updated. Error: %v", err.Error())
+		}
+
+		rowsAffected += int64(len(existing))
+		revisions = append(revisions, updateRevisions(existing, []models.Resource{resource}, username)...)
+	}
+
+	if err := insertRevisions(ctx, tx, revisions); err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, tx.Commit(ctx)
+}
+
+func (repo *ResourceSql) RemoveResources(project, key, languageCode, username string) (rowsAffected int64, err error) {
+	ctx := context.Background()
+	tx, err := repo.Pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	sqlStatement := `
+		DELETE FROM resources WHERE Project = $1 AND Key = $2 AND ($3 = '' OR LanguageCode = $3)
+		RETURNING Project, Key, LanguageCode, Text, Description, Status, SourceHash, Provider;`
+	rows, err := tx.Query(ctx, sqlStatement, project, key, languageCode)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := scanResources(rows)
+	if err != nil {
+		return 0, err
 	}
 
-	return rowsAffected, nil
+	if err := insertRevisions(ctx, tx, deleteRevisions(deleted, username)); err != nil {
+		return 0, err
+	}
+
+	return int64(len(deleted)), tx.Commit(ctx)
 }
 
-func (repo *ResourceSql) RemoveResources(key, languageCode string) (rowsAffected int64, err error) {
-	sqlStatement := "DELETE FROM resources WHERE Key = $1 AND LanguageCode = $2;"
-	cmd, err := repo.Pool.Exec(context.Background(), sqlStatement, key, languageCode)
+func (repo *ResourceSql) UpdateResourceStatus(resources ...models.Resource) (rowsAffected int64, err error) {
+	ctx := context.Background()
+	tx, err := repo.Pool.Begin(ctx)
 	if err != nil {
 		return 0, err
 	}
-	return cmd.RowsAffected(), nil
+	defer tx.Rollback(ctx)
+
+	for _, resource := range withProject(resources) {
+		cmd, err := tx.Exec(ctx, "UPDATE resources SET Status = $1 WHERE Project = $2 AND Key = $3 AND LanguageCode = $4;",
+			resource.Status, resource.Project, resource.Key, resource.LanguageCode)
+		if err != nil {
+			return 0, err
+		}
+		rowsAffected += cmd.RowsAffected()
+	}
+
+	return rowsAffected, tx.Commit(ctx)
 }
 
-func (repo *ResourceSql) ExistingLanguageCodes() (results []models.LanguageResult, err error) {
-	query := `SELECT languagecode as "LanguageCode", COUNT(*) as "Count" FROM resources GROUP BY languagecode`
-	rows, err := repo.Pool.Query(context.Background(), query)
+func (repo *ResourceSql) ExistingLanguageCodes(project string) (results []models.LanguageResult, err error) {
+	query := `SELECT languagecode as "LanguageCode", COUNT(*) as "Count" FROM resources WHERE Project = $1 GROUP BY languagecode`
+	rows, err := repo.Pool.Query(context.Background(), query, project)
 	if err != nil {
 		return []models.LanguageResult{}, err
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		item := models.LanguageResult{}
 		rows.Scan(&item.LanguageCode, &item.Count)
@@ -140,18 +383,100 @@ func (repo *ResourceSql) getResources(filters ...resourceFilter) ([]models.Resou
 	if err != nil {
 		return emptyResult, err
 	}
+
+	results, err = scanResources(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (repo *ResourceSql) GetResourcesPage(project, languageCode, afterKey string, limit int) ([]models.Resource, error) {
+	query := `
+		SELECT Project, Key, LanguageCode, Text, Description, Status, SourceHash, Provider FROM resources
+		WHERE Project = $1 AND LanguageCode = $2 AND Key COLLATE "C" > $3
+		ORDER BY Key COLLATE "C"
+		LIMIT $4`
+	rows, err := repo.Pool.Query(context.Background(), query, project, languageCode, afterKey, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanResources(rows)
+}
+
+// memoryPairsQuery joins the approved translations with their source texts, skipping the stale ones.
+const memoryPairsQuery = `
+	FROM resources s
+	JOIN resources t ON t.Project = s.Project AND t.Key = s.Key
+	WHERE s.LanguageCode = $1 AND t.LanguageCode = $2 AND t.Status = $3 AND s.Text <> ''
+		AND (t.SourceHash = '' OR t.SourceHash = encode(sha256(convert_to(s.Text, 'UTF8')), 'hex'))`
+
+// FindExactMatches returns the most used approved translation of every text that has one.
+func (repo *ResourceSql) FindExactMatches(sourceLanguage, targetLanguage string, texts []string) (map[string]string, error) {
+	query := `SELECT DISTINCT ON (s.Text) s.Text, t.Text` + memoryPairsQuery + `
+		AND s.Text = ANY($4)
+		GROUP BY s.Text, t.Text
+		ORDER BY s.Text, count(*) DESC, t.Text`
+	rows, err := repo.Pool.Query(context.Background(), query, sourceLanguage, targetLanguage, models.StatusApproved, texts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := map[string]string{}
+	for rows.Next() {
+		var sourceText, targetText string
+		if err := rows.Scan(&sourceText, &targetText); err != nil {
+			return nil, err
+		}
+		matches[sourceText] = targetText
+	}
+
+	return matches, rows.Err()
+}
+
+func (repo *ResourceSql) FindFuzzyMatches(sourceLanguage, targetLanguage, text string, minSimilarity float64, limit int) ([]models.MemoryMatch, error) {
+	shortest, longest := models.SimilarLengths(text, minSimilarity)
+	query := `SELECT s.Text, t.Text` + memoryPairsQuery + `
+		AND char_length(s.Text) BETWEEN $4 AND $5
+		GROUP BY s.Text, t.Text`
+	rows, err := repo.Pool.Query(context.Background(), query, sourceLanguage, targetLanguage, models.StatusApproved, shortest, longest)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.MemoryEntry
+	for rows.Next() {
+		entry := models.MemoryEntry{SourceLanguage: sourceLanguage, TargetLanguage: targetLanguage}
+		if err := rows.Scan(&entry.SourceText, &entry.TargetText); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return models.FindMemoryMatches(text, entries, minSimilarity, limit), nil
+}
+
+func scanResources(rows pgx.Rows) ([]models.Resource, error) {
 	defer rows.Close()
 
+	results := []models.Resource{}
 	for rows.Next() {
 		var resource models.Resource
-		err := rows.Scan(&resource.Key, &resource.LanguageCode, &resource.Text)
+		err := rows.Scan(&resource.Project, &resource.Key, &resource.LanguageCode, &resource.Text, &resource.Description, &resource.Status, &resource.SourceHash, &resource.Provider)
 		if err != nil {
 			return nil, err
 		}
 		results = append(results, resource)
 	}
 
-	return results, nil
+	return results, rows.Err()
 }
 
 func generateQueryAndParameters(filters ...resourceFilter) (query string, params []any, err error) {
@@ -164,14 +489,24 @@ func generateQueryAndParameters(filters ...resourceFilter) (query string, params
 	if filtersCount == 1 {
 		query = `
 			SELECT
+				Project,
 				Key,
 				LanguageCode,
-				Text
+				Text,
+				Description,
+				Status,
+				SourceHash,
+				Provider
 			FROM resources
 			WHERE 1=1
 		`
 		filter := filters[0]
 
+		if len(filter.Project) > 0 {
+			params = append(params, filter.Project)
+			query += fmt.Sprintf(" AND Project = $%d", len(params))
+		}
+
 		if len(filter.LanguageCode) > 0 {
 			params = append(params, filter.LanguageCode)
 			query += fmt.Sprintf(" AND LanguageCode = $%d", len(params))
@@ -184,11 +519,14 @@ func generateQueryAndParameters(filters ...resourceFilter) (query string, params
 	} else if filtersCount > 1 {
 		query = `
 			WITH filter_data AS (
-				SELECT * FROM jsonb_to_recordset($1::jsonb) AS x(k TEXT, l TEXT)
+				SELECT * FROM jsonb_to_recordset($1::jsonb) AS x("Project" TEXT, "Key" TEXT, "LanguageCode" TEXT)
 			)
-			SELECT r.*
+			SELECT DISTINCT r.Project, r.Key, r.LanguageCode, r.Text, r.Description, r.Status, r.SourceHash, r.Provider
 			FROM resources r
-			INNER JOIN filter_data f ON r.Key = f.Key AND r.LanguageCode = f.LanguageCode
+			INNER JOIN filter_data f ON
+				(f."Project" = '' OR r.Project = f."Project") AND
+				(f."Key" = '' OR r.Key = f."Key") AND
+				(f."LanguageCode" = '' OR r.LanguageCode = f."LanguageCode")
 		`
 		filterData, err := json.Marshal(filters)
 		if err != nil {
@@ -199,3 +537,202 @@ func generateQueryAndParameters(filters ...resourceFilter) (query string, params
 
 	return query, params, nil
 }
+
+func (repo *ResourceSql) GetProjects() ([]models.Project, error) {
+	rows, err := repo.Pool.Query(context.Background(), "SELECT Name, SourceLanguage, TargetLanguages, RetranslateStale FROM projects ORDER BY Name")
+	if err != nil {
+		return []models.Project{}, err
+	}
+	defer rows.Close()
+
+	results := []models.Project{}
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return []models.Project{}, err
+		}
+		results = append(results, project)
+	}
+
+	return results, rows.Err()
+}
+
+func (repo *ResourceSql) GetProject(name string) (project models.Project, found bool, err error) {
+	rows, err := repo.Pool.Query(context.Background(), "SELECT Name, SourceLanguage, TargetLanguages, RetranslateStale FROM projects WHERE Name = $1", name)
+	if err != nil {
+		return project, false, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return project, false, rows.Err()
+	}
+
+	project, err = scanProject(rows)
+	return project, err == nil, err
+}
+
+func (repo *ResourceSql) SaveProject(project models.Project) error {
+	targetLanguages, err := json.Marshal(project.TargetLanguages)
+	if err != nil {
+		return err
+	}
+
+	sqlStatement := `
+		INSERT INTO projects (Name, SourceLanguage, TargetLanguages, RetranslateStale) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (Name) DO UPDATE SET SourceLanguage = EXCLUDED.SourceLanguage, TargetLanguages = EXCLUDED.TargetLanguages,
+			RetranslateStale = EXCLUDED.RetranslateStale;
+	`
+	_, err = repo.Pool.Exec(context.Background(), sqlStatement, project.Name, project.SourceLanguage, string(targetLanguages), project.RetranslateStale)
+	return err
+}
+
+func scanProject(rows pgx.Rows) (models.Project, error) {
+	var project models.Project
+	var targetLanguages string
+	if err := rows.Scan(&project.Name, &project.SourceLanguage, &targetLanguages, &project.RetranslateStale); err != nil {
+		return project, err
+	}
+
+	err := json.Unmarshal([]byte(targetLanguages), &project.TargetLanguages)
+	return project, err
+}
+
+func (repo *ResourceSql) GetRevisions(project, key, languageCode string) ([]models.Revision, error) {
+	query := `
+		SELECT ID, Project, Key, LanguageCode, Action, OldText, NewText, Username, CreatedAt
+		FROM resource_revisions
+		WHERE Project = $1 AND Key = $2 AND ($3 = '' OR LanguageCode = $3)
+		ORDER BY ID DESC`
+	rows, err := repo.Pool.Query(context.Background(), query, project, key, languageCode)
+	if err != nil {
+		return []models.Revision{}, err
+	}
+	defer rows.Close()
+
+	results := []models.Revision{}
+	for rows.Next() {
+		var revision models.Revision
+		err := rows.Scan(&revision.ID, &revision.Project, &revision.Key, &revision.LanguageCode, &revision.Action,
+			&revision.OldText, &revision.NewText, &revision.Username, &revision.CreatedAt)
+		if err != nil {
+			return []models.Revision{}, err
+		}
+		results = append(results, revision)
+	}
+
+	return results, rows.Err()
+}
+
+func (repo *ResourceSql) RollbackRevision(project string, revisionID int64, username string) (models.Revision, error) {
+	ctx := context.Background()
+	tx, err := repo.Pool.Begin(ctx)
+	if err != nil {
+		return models.Revision{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var revision models.Revision
+	err = tx.QueryRow(ctx, "SELECT Project, Key, LanguageCode, Action, OldText FROM resource_revisions WHERE ID = $1 AND Project = $2", revisionID, project).
+		Scan(&revision.Project, &revision.Key, &revision.LanguageCode, &revision.Action, &revision.OldText)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Revision{}, contracts.ErrRevisionNotFound
+	} else if err != nil {
+		return models.Revision{}, err
+	}
+	if revision.Action == models.RevisionRestored {
+		return models.Revision{}, contracts.ErrNothingToRollBack
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT Project, Key, LanguageCode, Text, Description, Status, SourceHash, Provider FROM resources
+		WHERE Project = $1 AND Key = $2 AND LanguageCode = $3 FOR UPDATE`, revision.Project, revision.Key, revision.LanguageCode)
+	if err != nil {
+		return models.Revision{}, err
+	}
+	existing, err := scanResources(rows)
+	if err != nil {
+		return models.Revision{}, err
+	}
+
+	var rollback models.Revision
+	if len(existing) == 0 {
+		rollback = rollbackRevision(revision, models.Resource{}, false, username)
+		sqlStatement, params := generateInsert([]models.Resource{{Project: revision.Project, Key: revision.Key, LanguageCode: revision.LanguageCode, Text: revision.OldText}}, "")
+		if _, err := tx.Exec(ctx, sqlStatement, params...); err != nil {
+			return models.Revision{}, err
+		}
+	} else {
+		rollback = rollbackRevision(revision, existing[0], true, username)
+	}
+
+	// a rollback is a manual edit of the text, so it's no longer a machine translation of an older source text
+	_, err = tx.Exec(ctx, `
+		UPDATE resources r SET Text = $1, Status = $2, Provider = '', SourceHash = `+currentSourceHashSQL+`
+		WHERE r.Project = $3 AND r.Key = $4 AND r.LanguageCode = $5`,
+		revision.OldText, models.StatusEdited, revision.Project, revision.Key, revision.LanguageCode)
+	if err != nil {
+		return models.Revision{}, err
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO resource_revisions (Project, Key, LanguageCode, Action, OldText, NewText, Username)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING ID, CreatedAt`,
+		rollback.Project, rollback.Key, rollback.LanguageCode, rollback.Action, rollback.OldText, rollback.NewText, rollback.Username).
+		Scan(&rollback.ID, &rollback.CreatedAt)
+	if err != nil {
+		return models.Revision{}, err
+	}
+
+	return rollback, tx.Commit(ctx)
+}
+
+func insertRevisions(ctx context.Context, tx pgx.Tx, revisions []models.Revision) error {
+	for _, revision := range revisions {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO resource_revisions (Project, Key, LanguageCode, Action, OldText, NewText, Username)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			revision.Project, revision.Key, revision.LanguageCode, revision.Action, revision.OldText, revision.NewText, revision.Username)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *ResourceSql) CreateJob(job models.Job) (models.Job, error) {
+	sqlStatement := `
+		INSERT INTO jobs (Type, Project, SourceLanguage, TargetLanguage, Username, Status, BatchesDone, BatchesTotal, Created, Skipped, Error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING ID, CreatedAt, UpdatedAt`
+	err := repo.Pool.QueryRow(context.Background(), sqlStatement,
+		job.Type, job.Project, job.SourceLanguage, job.TargetLanguage, job.Username, job.Status,
+		job.BatchesDone, job.BatchesTotal, job.Created, job.Skipped, job.Error).
+		Scan(&job.ID, &job.CreatedAt, &job.UpdatedAt)
+	return job, err
+}
+
+func (repo *ResourceSql) UpdateJob(job models.Job) error {
+	sqlStatement := `
+		UPDATE jobs SET Status = $1, BatchesDone = $2, BatchesTotal = $3, Created = $4, Skipped = $5, Error = $6, UpdatedAt = now()
+		WHERE ID = $7`
+	_, err := repo.Pool.Exec(context.Background(), sqlStatement,
+		job.Status, job.BatchesDone, job.BatchesTotal, job.Created, job.Skipped, job.Error, job.ID)
+	return err
+}
+
+func (repo *ResourceSql) GetJob(id int64) (job models.Job, found bool, err error) {
+	query := `
+		SELECT ID, Type, Project, SourceLanguage, TargetLanguage, Username, Status, BatchesDone, BatchesTotal, Created, Skipped, Error, CreatedAt, UpdatedAt
+		FROM jobs WHERE ID = $1`
+	err = repo.Pool.QueryRow(context.Background(), query, id).Scan(
+		&job.ID, &job.Type, &job.Project, &job.SourceLanguage, &job.TargetLanguage, &job.Username, &job.Status,
+		&job.BatchesDone, &job.BatchesTotal, &job.Created, &job.Skipped, &job.Error, &job.CreatedAt, &job.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return job, false, nil
+	} else if err != nil {
+		return job, false, err
+	}
+
+	return job, true, nil
+}
@@ -17,6 +17,7 @@ func TestGenerateQuery_WhenOneFilter_ShouldFilterColumns(t *testing.T) {
 	}{
 		{"testing for Key", []string{" AND Key = $1"}, resourceFilter{Key: "aaa"}, 1},
 		{"testing for LanguageCode", []string{" AND LanguageCode = $1"}, resourceFilter{LanguageCode: "en"}, 1},
+		{"testing for Project and Key", []string{" AND Project = $1", " AND Key = $2"}, resourceFilter{Project: "app", Key: "aaa"}, 2},
 		{"testing for Key and LanguageCode",
 			[]string{" AND Key = $", " AND LanguageCode = $"},
 			resourceFilter{Key: "aaa", LanguageCode: "en"},
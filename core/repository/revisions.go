@@ -0,0 +1,48 @@
+package repository
+
+import "gotranslate/models"
+
+func newRevision(resource models.Resource, action, oldText, newText, username string) models.Revision {
+	return models.Revision{
+		Project:      resource.Project,
+		Key:          resource.Key,
+		LanguageCode: resource.LanguageCode,
+		Action:       action,
+		OldText:      oldText,
+		NewText:      newText,
+		Username:     username,
+	}
+}
+
+// updateRevisions returns a revision for every updated resource whose text changed.
+func updateRevisions(existing, updated []models.Resource, username string) []models.Revision {
+	existingTexts := map[resourceFilter]string{}
+	for _, resource := range existing {
+		existingTexts[identityOf(resource)] = resource.Text
+	}
+
+	var revisions []models.Revision
+	for _, resource := range updated {
+		if oldText, exists := existingTexts[identityOf(resource)]; exists && oldText != resource.Text {
+			revisions = append(revisions, newRevision(resource, models.RevisionUpdated, oldText, resource.Text, username))
+		}
+	}
+	return revisions
+}
+
+func deleteRevisions(deleted []models.Resource, username string) []models.Revision {
+	revisions := make([]models.Revision, 0, len(deleted))
+	for _, resource := range deleted {
+		revisions = append(revisions, newRevision(resource, models.RevisionDeleted, resource.Text, "", username))
+	}
+	return revisions
+}
+
+// rollbackRevision returns the revision that undoes the given one, current is the resource as it is now (if it exists).
+func rollbackRevision(revision models.Revision, current models.Resource, exists bool, username string) models.Revision {
+	resource := models.Resource{Project: revision.Project, Key: revision.Key, LanguageCode: revision.LanguageCode}
+	if !exists {
+		return newRevision(resource, models.RevisionRestored, "", revision.OldText, username)
+	}
+	return newRevision(resource, models.RevisionUpdated, current.Text, revision.OldText, username)
+}
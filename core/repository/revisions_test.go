@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"gotranslate/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateRevisions_ShouldOnlyRecordChangedTexts(t *testing.T) {
+	existing := []models.Resource{
+		{Project: models.DefaultProject, Key: "title", LanguageCode: "en", Text: "Title"},
+		{Project: models.DefaultProject, Key: "subtitle", LanguageCode: "en", Text: "Subtitle"},
+	}
+	updated := []models.Resource{
+		{Project: models.DefaultProject, Key: "title", LanguageCode: "en", Text: "New title"},
+		{Project: models.DefaultProject, Key: "subtitle", LanguageCode: "en", Text: "Subtitle"},
+		{Project: models.DefaultProject, Key: "missing", LanguageCode: "en", Text: "Missing"},
+	}
+
+	revisions := updateRevisions(existing, updated, "tester")
+
+	assert.Equal(t, []models.Revision{{
+		Project:      models.DefaultProject,
+		Key:          "title",
+		LanguageCode: "en",
+		Action:       models.RevisionUpdated,
+		OldText:      "Title",
+		NewText:      "New title",
+		Username:     "tester",
+	}}, revisions)
+}
+
+func TestRollbackRevisionOf_WhenResourceExists_ShouldSetOldTextBack(t *testing.T) {
+	revision := models.Revision{Project: "app", Key: "title", LanguageCode: "en", Action: models.RevisionUpdated, OldText: "first", NewText: "second"}
+	current := models.Resource{Project: "app", Key: "title", LanguageCode: "en", Text: "third"}
+
+	rollback := rollbackRevision(revision, current, true, "tester")
+
+	assert.Equal(t, models.RevisionUpdated, rollback.Action)
+	assert.Equal(t, "third", rollback.OldText)
+	assert.Equal(t, "first", rollback.NewText)
+	assert.Equal(t, "tester", rollback.Username)
+}
+
+func TestRollbackRevisionOf_WhenResourceWasDeleted_ShouldRestoreIt(t *testing.T) {
+	revision := models.Revision{Project: "app", Key: "title", LanguageCode: "en", Action: models.RevisionDeleted, OldText: "first"}
+
+	rollback := rollbackRevision(revision, models.Resource{}, false, "tester")
+
+	assert.Equal(t, models.RevisionRestored, rollback.Action)
+	assert.Equal(t, "", rollback.OldText)
+	assert.Equal(t, "first", rollback.NewText)
+}
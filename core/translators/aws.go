@@ -0,0 +1,107 @@
+package translators
+
+import (
+	"context"
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/translate"
+)
+
+// AWSOptions configures Amazon Translate with the keys of an IAM user, URL overrides the endpoint of the region.
+type AWSOptions struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	URL             string
+}
+
+// AWS translates with Amazon Translate, which takes one text per request, so the texts of a batch are sent one by one.
+type AWS struct {
+	Client AWSTranslateClient
+}
+
+var _ contracts.Translator = (*AWS)(nil)
+var _ contracts.RequestCounter = (*AWS)(nil)
+
+type AWSTranslateClient interface {
+	TranslateText(ctx context.Context, params *translate.TranslateTextInput, optFns ...func(*translate.Options)) (*translate.TranslateTextOutput, error)
+}
+
+func NewAWS(client *http.Client, options AWSOptions) contracts.Translator {
+	clientOptions := translate.Options{
+		Region: options.Region,
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: options.AccessKeyID, SecretAccessKey: options.SecretAccessKey, SessionToken: options.SessionToken}, nil
+		}),
+	}
+	if client != nil {
+		clientOptions.HTTPClient = client
+	}
+	if options.URL != "" {
+		clientOptions.BaseEndpoint = aws.String(options.URL)
+	}
+
+	return &AWS{Client: translate.New(clientOptions)}
+}
+
+func (t *AWS) Translate(tq models.TranslationQuery) ([]string, error) {
+	return t.translate(tq.Q, "auto", tq.Target)
+}
+
+func (t *AWS) translate(texts []string, sourceLanguageCode, targetLanguageCode string) ([]string, error) {
+	results, emptyResult := []string{}, []string{}
+	for _, text := range texts {
+		output, err := t.Client.TranslateText(context.Background(), &translate.TranslateTextInput{
+			Text:               aws.String(text),
+			SourceLanguageCode: aws.String(sourceLanguageCode),
+			TargetLanguageCode: aws.String(targetLanguageCode),
+		})
+		if err != nil {
+			return emptyResult, err
+		}
+		results = append(results, aws.ToString(output.TranslatedText))
+	}
+
+	return results, nil
+}
+
+func (t *AWS) TranslateResources(targetLanguageCode string, resources []models.Resource) ([]models.Resource, error) {
+	results, emptyResult := []models.Resource{}, []models.Resource{}
+	if len(resources) == 0 {
+		return emptyResult, nil
+	}
+
+	if len(resources) > t.GetBatchLimit() {
+		return emptyResult, fmt.Errorf("there's a limit of processing %d resources per request but found %d", t.GetBatchLimit(), len(resources))
+	}
+
+	var textsToTranslate []string
+	for _, resource := range resources {
+		textsToTranslate = append(textsToTranslate, resource.Text)
+	}
+
+	translations, err := t.translate(textsToTranslate, resources[0].LanguageCode, targetLanguageCode)
+	if err != nil {
+		return emptyResult, err
+	}
+
+	for i, text := range translations {
+		results = append(results, models.Resource{Key: resources[i].Key, LanguageCode: targetLanguageCode, Text: text, Status: models.StatusMachine})
+	}
+
+	return results, nil
+}
+
+// Requests is a request per text, TranslateText translates a single text.
+func (t *AWS) Requests(texts int) int {
+	return texts
+}
+
+func (t *AWS) GetBatchLimit() int {
+	return 25 // texts are sent one by one, so a batch is saved after 25 requests, which Limited counts as 25 requests
+}
@@ -0,0 +1,97 @@
+package translators
+
+import (
+	"encoding/json"
+	"fmt"
+	"gotranslate/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type awsTranslateTextRequest struct {
+	Text               string
+	SourceLanguageCode string
+	TargetLanguageCode string
+}
+
+// awsServerStub answers TranslateText calls with "translated <text>" and records the requests
+func awsServerStub(t *testing.T, requests *[]awsTranslateTextRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "AWSShineFrontendService_20170701.TranslateText", r.Header.Get("X-Amz-Target"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access-key/"), "the request should be signed")
+
+		var request awsTranslateTextRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		*requests = append(*requests, request)
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		json.NewEncoder(w).Encode(map[string]string{
+			"TranslatedText":     "translated " + request.Text,
+			"SourceLanguageCode": request.SourceLanguageCode,
+			"TargetLanguageCode": request.TargetLanguageCode,
+		})
+	}))
+}
+
+func TestAWSTranslateResources_ShouldTranslateEveryText(t *testing.T) {
+	// arrange
+	var requests []awsTranslateTextRequest
+	server := awsServerStub(t, &requests)
+	defer server.Close()
+	translator := NewAWS(server.Client(), AWSOptions{Region: "eu-west-1", AccessKeyID: "access-key", SecretAccessKey: "secret", URL: server.URL})
+	inputs := []models.Resource{
+		{Key: "key1", LanguageCode: "en", Text: "text 1"},
+		{Key: "key2", LanguageCode: "en", Text: "text 2"},
+	}
+
+	// act
+	results, err := translator.TranslateResources("de", inputs)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Resource{
+		{Key: "key1", LanguageCode: "de", Text: "translated text 1", Status: models.StatusMachine},
+		{Key: "key2", LanguageCode: "de", Text: "translated text 2", Status: models.StatusMachine},
+	}, results)
+	assert.Equal(t, []awsTranslateTextRequest{
+		{Text: "text 1", SourceLanguageCode: "en", TargetLanguageCode: "de"},
+		{Text: "text 2", SourceLanguageCode: "en", TargetLanguageCode: "de"},
+	}, requests)
+}
+
+func TestAWSTranslate_ShouldDetectSourceLanguage(t *testing.T) {
+	// arrange
+	var requests []awsTranslateTextRequest
+	server := awsServerStub(t, &requests)
+	defer server.Close()
+	translator := NewAWS(server.Client(), AWSOptions{Region: "eu-west-1", AccessKeyID: "access-key", SecretAccessKey: "secret", URL: server.URL})
+
+	// act
+	results, err := translator.Translate(models.TranslationQuery{Target: "ja", Q: []string{"text"}})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"translated text"}, results)
+	assert.Equal(t, "auto", requests[0].SourceLanguageCode)
+}
+
+func TestAWSTranslate_WhenLanguagePairUnsupported_ShouldReturnError(t *testing.T) {
+	// arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"__type": "UnsupportedLanguagePairException", "Message": "Unsupported language pair: en to xx"}`)
+	}))
+	defer server.Close()
+	translator := NewAWS(server.Client(), AWSOptions{Region: "eu-west-1", AccessKeyID: "access-key", SecretAccessKey: "secret", URL: server.URL})
+
+	// act
+	_, err := translator.Translate(models.TranslationQuery{Target: "xx", Q: []string{"text"}})
+
+	// assert
+	assert.ErrorContains(t, err, "Unsupported language pair")
+}
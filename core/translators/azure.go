@@ -0,0 +1,208 @@
+package translators
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	azureURL = "https://api.cognitive.microsofttranslator.com"
+	// azureCharacterLimit is the number of characters Azure translates per request, all the texts included
+	azureCharacterLimit = 50000
+)
+
+// AzureOptions configures Azure AI Translator. Region is required for regional and multi-service resources,
+// URL overrides the global endpoint.
+type AzureOptions struct {
+	Key    string
+	Region string
+	URL    string
+}
+
+type Azure struct {
+	client  *http.Client
+	url     string
+	options AzureOptions
+}
+
+var _ contracts.Translator = (*Azure)(nil)
+
+func NewAzure(client *http.Client, options AzureOptions) contracts.Translator {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	endpoint := options.URL
+	if endpoint == "" {
+		endpoint = azureURL
+	}
+
+	return &Azure{client: client, url: strings.TrimSuffix(endpoint, "/"), options: options}
+}
+
+type azureText struct {
+	Text string `json:"Text"`
+}
+
+type azureResult struct {
+	Translations []struct {
+		Text string `json:"text"`
+		To   string `json:"to"`
+	} `json:"translations"`
+}
+
+type azureError struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (t *Azure) Translate(tq models.TranslationQuery) ([]string, error) {
+	return t.translate(tq.Q, "", tq.Target)
+}
+
+// translate sends the texts in as many requests as the character limit of Azure requires.
+func (t *Azure) translate(texts []string, sourceLanguageCode, targetLanguageCode string) ([]string, error) {
+	results, emptyResult := []string{}, []string{}
+	requests, err := azureRequests(texts)
+	if err != nil {
+		return emptyResult, err
+	}
+
+	for _, request := range requests {
+		translations, err := t.translateRequest(request, sourceLanguageCode, targetLanguageCode)
+		if err != nil {
+			return emptyResult, err
+		}
+		results = append(results, translations...)
+	}
+
+	return results, nil
+}
+
+// azureRequests splits the texts in order into requests of at most azureCharacterLimit characters.
+func azureRequests(texts []string) ([][]string, error) {
+	var requests [][]string
+	var request []string
+	characters := 0
+	for _, text := range texts {
+		length := utf8.RuneCountInString(text)
+		if length > azureCharacterLimit {
+			return nil, fmt.Errorf("there's a limit of translating %d characters per request but a text has %d", azureCharacterLimit, length)
+		}
+		if characters+length > azureCharacterLimit {
+			requests = append(requests, request)
+			request, characters = nil, 0
+		}
+		request = append(request, text)
+		characters += length
+	}
+	if len(request) > 0 {
+		requests = append(requests, request)
+	}
+	return requests, nil
+}
+
+func (t *Azure) translateRequest(texts []string, sourceLanguageCode, targetLanguageCode string) ([]string, error) {
+	emptyResult := []string{}
+	if len(texts) == 0 {
+		return emptyResult, nil
+	}
+
+	var request []azureText
+	for _, text := range texts {
+		request = append(request, azureText{Text: text})
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return emptyResult, err
+	}
+
+	query := url.Values{"api-version": {"3.0"}, "to": {targetLanguageCode}}
+	if sourceLanguageCode != "" {
+		query.Set("from", sourceLanguageCode)
+	}
+
+	httpRequest, err := http.NewRequest(http.MethodPost, t.url+"/translate?"+query.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return emptyResult, err
+	}
+	httpRequest.Header.Set("Ocp-Apim-Subscription-Key", t.options.Key)
+	if t.options.Region != "" {
+		httpRequest.Header.Set("Ocp-Apim-Subscription-Region", t.options.Region)
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+
+	httpResponse, err := t.client.Do(httpRequest)
+	if err != nil {
+		return emptyResult, err
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		var response azureError
+		json.NewDecoder(httpResponse.Body).Decode(&response)
+		if response.Error.Message == "" {
+			response.Error.Message = http.StatusText(httpResponse.StatusCode)
+		}
+		return emptyResult, fmt.Errorf("azure returned status %d: %v", httpResponse.StatusCode, response.Error.Message)
+	}
+
+	var response []azureResult
+	if err := json.NewDecoder(httpResponse.Body).Decode(&response); err != nil {
+		return emptyResult, err
+	}
+
+	results := []string{}
+	for _, result := range response {
+		if len(result.Translations) == 0 {
+			return emptyResult, fmt.Errorf("azure returned no translation to %v", targetLanguageCode)
+		}
+		results = append(results, result.Translations[0].Text)
+	}
+
+	return results, nil
+}
+
+func (t *Azure) TranslateResources(targetLanguageCode string, resources []models.Resource) ([]models.Resource, error) {
+	results, emptyResult := []models.Resource{}, []models.Resource{}
+	if len(resources) == 0 {
+		return emptyResult, nil
+	}
+
+	if len(resources) > t.GetBatchLimit() {
+		return emptyResult, fmt.Errorf("there's a limit of processing %d resources per request but found %d", t.GetBatchLimit(), len(resources))
+	}
+
+	var textsToTranslate []string
+	for _, resource := range resources {
+		textsToTranslate = append(textsToTranslate, resource.Text)
+	}
+
+	translations, err := t.translate(textsToTranslate, resources[0].LanguageCode, targetLanguageCode)
+	if err != nil {
+		return emptyResult, err
+	}
+
+	if translationsCount, resourcesCount := len(translations), len(resources); translationsCount != resourcesCount {
+		return emptyResult, fmt.Errorf("translation was done but expected %d results and got %d", resourcesCount, translationsCount)
+	}
+
+	for i, text := range translations {
+		results = append(results, models.Resource{Key: resources[i].Key, LanguageCode: targetLanguageCode, Text: text, Status: models.StatusMachine})
+	}
+
+	return results, nil
+}
+
+func (t *Azure) GetBatchLimit() int {
+	return 100 // the actual limit is 1000 texts, longer batches are split by the limit of 50000 characters per request
+}
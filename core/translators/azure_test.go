@@ -0,0 +1,125 @@
+package translators
+
+import (
+	"encoding/json"
+	"fmt"
+	"gotranslate/models"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// azureServerStub translates every text to "translated <text>" and records the queries and headers of the requests
+func azureServerStub(t *testing.T, queries *[]url.Values, headers *[]http.Header) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/translate", r.URL.Path)
+		*queries = append(*queries, r.URL.Query())
+		*headers = append(*headers, r.Header)
+
+		var texts []azureText
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&texts))
+		var response []map[string]any
+		for _, text := range texts {
+			response = append(response, map[string]any{
+				"translations": []map[string]string{{"text": "translated " + text.Text, "to": r.URL.Query().Get("to")}},
+			})
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+}
+
+func TestAzureTranslateResources_ShouldReturnTranslatedResources(t *testing.T) {
+	// arrange
+	var queries []url.Values
+	var headers []http.Header
+	server := azureServerStub(t, &queries, &headers)
+	defer server.Close()
+	translator := NewAzure(server.Client(), AzureOptions{Key: "key", Region: "westeurope", URL: server.URL})
+	inputs := []models.Resource{
+		{Key: "key1", LanguageCode: "en", Text: "text 1"},
+		{Key: "key2", LanguageCode: "en", Text: "text 2"},
+	}
+
+	// act
+	results, err := translator.TranslateResources("de", inputs)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Resource{
+		{Key: "key1", LanguageCode: "de", Text: "translated text 1", Status: models.StatusMachine},
+		{Key: "key2", LanguageCode: "de", Text: "translated text 2", Status: models.StatusMachine},
+	}, results)
+	assert.Equal(t, url.Values{"api-version": {"3.0"}, "from": {"en"}, "to": {"de"}}, queries[0])
+	assert.Equal(t, "key", headers[0].Get("Ocp-Apim-Subscription-Key"))
+	assert.Equal(t, "westeurope", headers[0].Get("Ocp-Apim-Subscription-Region"))
+}
+
+func TestAzureTranslateResources_WhenOverCharacterLimit_ShouldSplitRequests(t *testing.T) {
+	// arrange
+	var queries []url.Values
+	var headers []http.Header
+	server := azureServerStub(t, &queries, &headers)
+	defer server.Close()
+	translator := NewAzure(server.Client(), AzureOptions{Key: "key", URL: server.URL})
+	long := strings.Repeat("a", azureCharacterLimit/2)
+	inputs := []models.Resource{
+		{Key: "key1", LanguageCode: "en", Text: long},
+		{Key: "key2", LanguageCode: "en", Text: long},
+		{Key: "key3", LanguageCode: "en", Text: "text 3"},
+	}
+
+	// act
+	results, err := translator.TranslateResources("de", inputs)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, queries, 2)
+	assert.Len(t, results, 3)
+	assert.Equal(t, "key3", results[2].Key)
+	assert.Equal(t, "translated text 3", results[2].Text)
+}
+
+func TestAzureRequests_WhenTextIsOverCharacterLimit_ShouldReturnError(t *testing.T) {
+	_, err := azureRequests([]string{strings.Repeat("ü", azureCharacterLimit+1)})
+
+	assert.Error(t, err)
+}
+
+func TestAzureTranslate_ShouldDetectSourceLanguage(t *testing.T) {
+	// arrange
+	var queries []url.Values
+	var headers []http.Header
+	server := azureServerStub(t, &queries, &headers)
+	defer server.Close()
+	translator := NewAzure(server.Client(), AzureOptions{Key: "key", URL: server.URL})
+
+	// act
+	results, err := translator.Translate(models.TranslationQuery{Target: "ja", Q: []string{"text"}})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"translated text"}, results)
+	assert.False(t, queries[0].Has("from"))
+	assert.Empty(t, headers[0].Get("Ocp-Apim-Subscription-Region"))
+}
+
+func TestAzureTranslate_WhenKeyIsInvalid_ShouldReturnError(t *testing.T) {
+	// arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error": {"code": 401000, "message": "The request is not authorized because credentials are missing or invalid."}}`)
+	}))
+	defer server.Close()
+	translator := NewAzure(server.Client(), AzureOptions{Key: "key", URL: server.URL})
+
+	// act
+	_, err := translator.Translate(models.TranslationQuery{Target: "de", Q: []string{"text"}})
+
+	// assert
+	assert.ErrorContains(t, err, "401")
+	assert.ErrorContains(t, err, "credentials are missing or invalid")
+}
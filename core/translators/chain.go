@@ -0,0 +1,191 @@
+package translators
+
+import (
+	"errors"
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"gotranslate/slices"
+	"strings"
+)
+
+// ChainLink is a translator of a Chain, used for the target Languages or for all of them when it has none.
+type ChainLink struct {
+	Name       string
+	Translator contracts.Translator
+	Languages  []string
+}
+
+// Chain routes every translation to the first translator of the target language, and falls back to the next ones
+// when it fails or doesn't support the languages. The name of the translator is set as the Provider of the resources.
+type Chain struct {
+	links []ChainLink
+}
+
+var _ contracts.Translator = (*Chain)(nil)
+var _ contracts.ConcurrentTranslator = (*Chain)(nil)
+var _ contracts.LanguageLister = (*Chain)(nil)
+
+func NewChain(links ...ChainLink) *Chain {
+	return &Chain{links: links}
+}
+
+// routes returns the translators of the target language in order.
+func (c *Chain) routes(targetLanguageCode string) []ChainLink {
+	var routes []ChainLink
+	for _, link := range c.links {
+		if len(link.Languages) == 0 || routesLanguage(link.Languages, targetLanguageCode) {
+			routes = append(routes, link)
+		}
+	}
+	return routes
+}
+
+// routesLanguage matches the language or its base language, e.g. "de" routes "de-AT".
+func routesLanguage(languages []string, languageCode string) bool {
+	baseLanguage, _, _ := strings.Cut(languageCode, "-")
+	for _, language := range languages {
+		if strings.EqualFold(language, languageCode) || strings.EqualFold(language, baseLanguage) {
+			return true
+		}
+	}
+	return false
+}
+
+// supports checks the languages with translators that list them, the others are assumed to support them.
+func supports(translator contracts.Translator, sourceLanguageCode, targetLanguageCode string) error {
+	lister, ok := translator.(contracts.LanguageLister)
+	if !ok {
+		return nil
+	}
+
+	languages, err := lister.SupportedLanguages()
+	if errors.Is(err, contracts.ErrLanguagesUnknown) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, language := range languages {
+		if language.Code != sourceLanguageCode {
+			continue
+		}
+		for _, target := range language.Targets {
+			if target == targetLanguageCode {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("doesn't translate from '%v' to '%v'", sourceLanguageCode, targetLanguageCode)
+}
+
+func (c *Chain) Translate(tq models.TranslationQuery) ([]string, error) {
+	var errs []error
+	for _, link := range c.routes(tq.Target) {
+		results, err := link.Translator.Translate(tq)
+		if err == nil {
+			return results, nil
+		}
+		errs = append(errs, fmt.Errorf("%v: %w", link.Name, err))
+	}
+
+	return []string{}, chainError(tq.Target, errs)
+}
+
+func (c *Chain) TranslateResources(targetLanguageCode string, resources []models.Resource) ([]models.Resource, error) {
+	if len(resources) == 0 {
+		return []models.Resource{}, nil
+	}
+
+	var errs []error
+	for _, link := range c.routes(targetLanguageCode) {
+		if err := supports(link.Translator, resources[0].LanguageCode, targetLanguageCode); err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", link.Name, err))
+			continue
+		}
+
+		results, err := link.Translator.TranslateResources(targetLanguageCode, resources)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", link.Name, err))
+			continue
+		}
+
+		for i := range results {
+			results[i].Provider = link.Name
+		}
+		return results, nil
+	}
+
+	return []models.Resource{}, chainError(targetLanguageCode, errs)
+}
+
+func chainError(targetLanguageCode string, errs []error) error {
+	if len(errs) == 0 {
+		return fmt.Errorf("no translator is configured for language %v", targetLanguageCode)
+	}
+	return fmt.Errorf("all translators failed: %w", errors.Join(errs...))
+}
+
+// GetBatchLimit is the smallest limit of the translators, so every batch can fall back to any of them.
+func (c *Chain) GetBatchLimit() int {
+	limit := 0
+	for _, link := range c.links {
+		if batchLimit := link.Translator.GetBatchLimit(); limit == 0 || batchLimit < limit {
+			limit = batchLimit
+		}
+	}
+	return max(limit, 1)
+}
+
+// Concurrency is the smallest concurrency of the translators, so a fallback doesn't receive more batches than it allows.
+func (c *Chain) Concurrency() int {
+	concurrency := 0
+	for _, link := range c.links {
+		linkConcurrency := 1
+		if concurrent, ok := link.Translator.(contracts.ConcurrentTranslator); ok {
+			linkConcurrency = concurrent.Concurrency()
+		}
+		if concurrency == 0 || linkConcurrency < concurrency {
+			concurrency = linkConcurrency
+		}
+	}
+	return max(concurrency, 1)
+}
+
+// SupportedLanguages merges the languages of the translators, limited to the languages routed to each one.
+// It's only known when every translator lists its languages.
+func (c *Chain) SupportedLanguages() ([]models.SupportedLanguage, error) {
+	var results []models.SupportedLanguage
+	indexes := map[string]int{}
+	for _, link := range c.links {
+		lister, ok := link.Translator.(contracts.LanguageLister)
+		if !ok {
+			return nil, contracts.ErrLanguagesUnknown
+		}
+
+		languages, err := lister.SupportedLanguages()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, language := range languages {
+			index, exists := indexes[language.Code]
+			if !exists {
+				index = len(results)
+				indexes[language.Code] = index
+				results = append(results, models.SupportedLanguage{Code: language.Code, Name: language.Name, Targets: []string{}})
+			}
+
+			for _, target := range language.Targets {
+				if len(link.Languages) > 0 && !routesLanguage(link.Languages, target) {
+					continue
+				}
+				if !slices.Contains(results[index].Targets, func(existing string) bool { return existing == target }) {
+					results[index].Targets = append(results[index].Targets, target)
+				}
+			}
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,154 @@
+package translators
+
+import (
+	"errors"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// translatorStub translates every text to "<prefix> <text>" or fails with err, and counts its calls
+type translatorStub struct {
+	prefix     string
+	err        error
+	batchLimit int
+	calls      int
+}
+
+func (s *translatorStub) Translate(tq models.TranslationQuery) ([]string, error) {
+	s.calls++
+	if s.err != nil {
+		return []string{}, s.err
+	}
+	results := []string{}
+	for _, text := range tq.Q {
+		results = append(results, s.prefix+" "+text)
+	}
+	return results, nil
+}
+
+func (s *translatorStub) TranslateResources(targetLanguageCode string, resources []models.Resource) ([]models.Resource, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	results := []models.Resource{}
+	for _, resource := range resources {
+		results = append(results, models.Resource{Key: resource.Key, LanguageCode: targetLanguageCode, Text: s.prefix + " " + resource.Text, Status: models.StatusMachine})
+	}
+	return results, nil
+}
+
+func (s *translatorStub) GetBatchLimit() int {
+	return s.batchLimit
+}
+
+// listingTranslatorStub is a translatorStub that lists its languages
+type listingTranslatorStub struct {
+	translatorStub
+	languages []models.SupportedLanguage
+}
+
+func (s *listingTranslatorStub) SupportedLanguages() ([]models.SupportedLanguage, error) {
+	return s.languages, nil
+}
+
+var _ contracts.LanguageLister = (*listingTranslatorStub)(nil)
+
+var chainInputs = []models.Resource{{Key: "key1", LanguageCode: "en", Text: "text 1"}}
+
+func TestChainTranslateResources_ShouldRouteByTargetLanguageAndRecordProvider(t *testing.T) {
+	// arrange
+	deepl, google := &translatorStub{prefix: "deepl", batchLimit: 50}, &translatorStub{prefix: "google", batchLimit: 100}
+	chain := NewChain(
+		ChainLink{Name: "deepl", Translator: deepl, Languages: []string{"de", "fr", "ja"}},
+		ChainLink{Name: "google", Translator: google},
+	)
+
+	// act
+	german, germanErr := chain.TranslateResources("de-AT", chainInputs)
+	finnish, finnishErr := chain.TranslateResources("fi", chainInputs)
+
+	// assert
+	assert.NoError(t, germanErr)
+	assert.NoError(t, finnishErr)
+	assert.Equal(t, []models.Resource{{Key: "key1", LanguageCode: "de-AT", Text: "deepl text 1", Status: models.StatusMachine, Provider: "deepl"}}, german)
+	assert.Equal(t, []models.Resource{{Key: "key1", LanguageCode: "fi", Text: "google text 1", Status: models.StatusMachine, Provider: "google"}}, finnish)
+	assert.Equal(t, 1, deepl.calls)
+	assert.Equal(t, 1, google.calls)
+	assert.Equal(t, 50, chain.GetBatchLimit())
+}
+
+func TestChainTranslateResources_WhenTranslatorFails_ShouldFallBackToNext(t *testing.T) {
+	// arrange
+	deepl, google := &translatorStub{prefix: "deepl", err: errors.New("quota exceeded")}, &translatorStub{prefix: "google"}
+	chain := NewChain(
+		ChainLink{Name: "deepl", Translator: deepl, Languages: []string{"de"}},
+		ChainLink{Name: "google", Translator: google},
+	)
+
+	// act
+	results, err := chain.TranslateResources("de", chainInputs)
+	texts, translateErr := chain.Translate(models.TranslationQuery{Target: "de", Q: []string{"text"}})
+
+	// assert
+	assert.NoError(t, err)
+	assert.NoError(t, translateErr)
+	assert.Equal(t, "google", results[0].Provider)
+	assert.Equal(t, []string{"google text"}, texts)
+}
+
+func TestChainTranslateResources_WhenLanguageUnsupported_ShouldSkipTranslator(t *testing.T) {
+	// arrange
+	libre := &listingTranslatorStub{
+		translatorStub: translatorStub{prefix: "libre"},
+		languages:      []models.SupportedLanguage{{Code: "en", Targets: []string{"de"}}},
+	}
+	google := &translatorStub{prefix: "google"}
+	chain := NewChain(ChainLink{Name: "libre", Translator: libre}, ChainLink{Name: "google", Translator: google})
+
+	// act
+	results, err := chain.TranslateResources("ja", chainInputs)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "google", results[0].Provider)
+	assert.Equal(t, 0, libre.calls)
+}
+
+func TestChainTranslateResources_WhenEveryTranslatorFails_ShouldReturnTheirErrors(t *testing.T) {
+	// arrange
+	chain := NewChain(
+		ChainLink{Name: "deepl", Translator: &translatorStub{err: errors.New("quota exceeded")}},
+		ChainLink{Name: "google", Translator: &translatorStub{err: errors.New("invalid key")}},
+		ChainLink{Name: "azure", Translator: &translatorStub{}, Languages: []string{"fr"}},
+	)
+
+	// act
+	_, err := chain.TranslateResources("de", chainInputs)
+	_, notRoutedErr := NewChain(ChainLink{Name: "deepl", Translator: &translatorStub{}, Languages: []string{"fr"}}).TranslateResources("de", chainInputs)
+
+	// assert
+	assert.ErrorContains(t, err, "deepl: quota exceeded")
+	assert.ErrorContains(t, err, "google: invalid key")
+	assert.NotContains(t, err.Error(), "azure")
+	assert.ErrorContains(t, notRoutedErr, "no translator is configured for language de")
+}
+
+func TestChainSupportedLanguages_ShouldMergeRoutedTargets(t *testing.T) {
+	// arrange
+	libre := &listingTranslatorStub{languages: []models.SupportedLanguage{{Code: "en", Name: "English", Targets: []string{"de", "fi"}}}}
+	other := &listingTranslatorStub{languages: []models.SupportedLanguage{{Code: "en", Name: "English", Targets: []string{"fi", "ja", "sv"}}}}
+	chain := NewChain(ChainLink{Name: "libre", Translator: libre}, ChainLink{Name: "other", Translator: other, Languages: []string{"ja", "fi"}})
+
+	// act
+	results, err := chain.SupportedLanguages()
+	_, unknownErr := NewChain(ChainLink{Name: "libre", Translator: libre}, ChainLink{Name: "google", Translator: &translatorStub{}}).SupportedLanguages()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SupportedLanguage{{Code: "en", Name: "English", Targets: []string{"de", "fi", "ja"}}}, results)
+	assert.ErrorIs(t, unknownErr, contracts.ErrLanguagesUnknown)
+}
@@ -0,0 +1,166 @@
+package translators
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"net/http"
+	"strings"
+)
+
+const (
+	deepLFreeURL = "https://api-free.deepl.com"
+	deepLProURL  = "https://api.deepl.com"
+)
+
+// DeepLOptions configures the DeepL translator. Keys of the free plan end with ":fx" and use the free endpoint,
+// URL overrides the endpoint.
+type DeepLOptions struct {
+	AuthKey string
+	Free    bool
+	URL     string
+	// Formality is "default", "more", "less", "prefer_more" or "prefer_less", the prefer options don't fail for
+	// languages without formality.
+	Formality string
+	// GlossaryID is only sent when the source language is known, DeepL requires it with a glossary.
+	GlossaryID string
+}
+
+type DeepL struct {
+	client  *http.Client
+	url     string
+	options DeepLOptions
+}
+
+var _ contracts.Translator = (*DeepL)(nil)
+
+func NewDeepL(client *http.Client, options DeepLOptions) contracts.Translator {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	url := options.URL
+	if url == "" && (options.Free || strings.HasSuffix(options.AuthKey, ":fx")) {
+		url = deepLFreeURL
+	} else if url == "" {
+		url = deepLProURL
+	}
+
+	return &DeepL{client: client, url: strings.TrimSuffix(url, "/"), options: options}
+}
+
+type deepLRequest struct {
+	Text       []string `json:"text"`
+	TargetLang string   `json:"target_lang"`
+	SourceLang string   `json:"source_lang,omitempty"`
+	Formality  string   `json:"formality,omitempty"`
+	GlossaryID string   `json:"glossary_id,omitempty"`
+}
+
+type deepLResponse struct {
+	Translations []struct {
+		DetectedSourceLanguage string `json:"detected_source_language"`
+		Text                   string `json:"text"`
+	} `json:"translations"`
+	Message string `json:"message"`
+}
+
+func (t *DeepL) Translate(tq models.TranslationQuery) ([]string, error) {
+	return t.translate(tq.Q, "", tq.Target)
+}
+
+func (t *DeepL) translate(texts []string, sourceLanguageCode, targetLanguageCode string) ([]string, error) {
+	emptyResult := []string{}
+	if len(texts) == 0 {
+		return emptyResult, nil
+	}
+
+	request := deepLRequest{
+		Text:       texts,
+		TargetLang: strings.ToUpper(targetLanguageCode),
+		Formality:  t.options.Formality,
+	}
+	if sourceLanguageCode != "" {
+		// source languages don't have variants, e.g. EN instead of EN-US
+		request.SourceLang = strings.ToUpper(strings.SplitN(sourceLanguageCode, "-", 2)[0])
+		request.GlossaryID = t.options.GlossaryID
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return emptyResult, err
+	}
+
+	httpRequest, err := http.NewRequest(http.MethodPost, t.url+"/v2/translate", bytes.NewReader(body))
+	if err != nil {
+		return emptyResult, err
+	}
+	httpRequest.Header.Set("Authorization", "DeepL-Auth-Key "+t.options.AuthKey)
+	httpRequest.Header.Set("Content-Type", "application/json")
+
+	httpResponse, err := t.client.Do(httpRequest)
+	if err != nil {
+		return emptyResult, err
+	}
+	defer httpResponse.Body.Close()
+
+	var response deepLResponse
+	decodeErr := json.NewDecoder(httpResponse.Body).Decode(&response)
+	if httpResponse.StatusCode != http.StatusOK {
+		if response.Message == "" {
+			response.Message = http.StatusText(httpResponse.StatusCode)
+		}
+		if httpResponse.StatusCode == 456 {
+			response.Message = "quota exceeded: " + response.Message
+		}
+		return emptyResult, fmt.Errorf("deepl returned status %d: %v", httpResponse.StatusCode, response.Message)
+	}
+	if decodeErr != nil {
+		return emptyResult, decodeErr
+	}
+
+	results := []string{}
+	for _, translation := range response.Translations {
+		results = append(results, translation.Text)
+	}
+
+	return results, nil
+}
+
+// TranslateResources sends the language of the resources as the source language, so the glossary can be used.
+func (t *DeepL) TranslateResources(targetLanguageCode string, resources []models.Resource) ([]models.Resource, error) {
+	results, emptyResult := []models.Resource{}, []models.Resource{}
+	if len(resources) == 0 {
+		return emptyResult, nil
+	}
+
+	if len(resources) > t.GetBatchLimit() {
+		return emptyResult, fmt.Errorf("there's a limit of processing %d resources per request but found %d", t.GetBatchLimit(), len(resources))
+	}
+
+	var textsToTranslate []string
+	for _, resource := range resources {
+		textsToTranslate = append(textsToTranslate, resource.Text)
+	}
+
+	translations, err := t.translate(textsToTranslate, resources[0].LanguageCode, targetLanguageCode)
+	if err != nil {
+		return emptyResult, err
+	}
+
+	if translationsCount, resourcesCount := len(translations), len(resources); translationsCount != resourcesCount {
+		return emptyResult, fmt.Errorf("translation was done but expected %d results and got %d", resourcesCount, translationsCount)
+	}
+
+	for i, text := range translations {
+		results = append(results, models.Resource{Key: resources[i].Key, LanguageCode: targetLanguageCode, Text: text, Status: models.StatusMachine})
+	}
+
+	return results, nil
+}
+
+func (t *DeepL) GetBatchLimit() int {
+	return 50 // the limit of texts per request
+}
@@ -0,0 +1,121 @@
+package translators
+
+import (
+	"encoding/json"
+	"fmt"
+	"gotranslate/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// deepLServerStub answers like DeepL, translating every text to "translated <text>", and records the requests
+func deepLServerStub(t *testing.T, requests *[]deepLRequest, authorizations *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/translate", r.URL.Path)
+		var request deepLRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		*requests = append(*requests, request)
+		*authorizations = append(*authorizations, r.Header.Get("Authorization"))
+
+		response := deepLResponse{}
+		for _, text := range request.Text {
+			response.Translations = append(response.Translations, struct {
+				DetectedSourceLanguage string `json:"detected_source_language"`
+				Text                   string `json:"text"`
+			}{DetectedSourceLanguage: "EN", Text: "translated " + text})
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+}
+
+func TestDeepLTranslateResources_ShouldSendOptionsAndReturnTranslatedResources(t *testing.T) {
+	// arrange
+	var requests []deepLRequest
+	var authorizations []string
+	server := deepLServerStub(t, &requests, &authorizations)
+	defer server.Close()
+	translator := NewDeepL(server.Client(), DeepLOptions{AuthKey: "key", URL: server.URL, Formality: "prefer_more", GlossaryID: "glossary"})
+	inputs := []models.Resource{
+		{Key: "key1", LanguageCode: "en-US", Text: "text 1"},
+		{Key: "key2", LanguageCode: "en-US", Text: "text 2"},
+	}
+
+	// act
+	results, err := translator.TranslateResources("de", inputs)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Resource{
+		{Key: "key1", LanguageCode: "de", Text: "translated text 1", Status: models.StatusMachine},
+		{Key: "key2", LanguageCode: "de", Text: "translated text 2", Status: models.StatusMachine},
+	}, results)
+	assert.Equal(t, []string{"DeepL-Auth-Key key"}, authorizations)
+	assert.Equal(t, []deepLRequest{{
+		Text:       []string{"text 1", "text 2"},
+		TargetLang: "DE",
+		SourceLang: "EN",
+		Formality:  "prefer_more",
+		GlossaryID: "glossary",
+	}}, requests)
+}
+
+func TestDeepLTranslate_WhenSourceUnknown_ShouldNotSendGlossary(t *testing.T) {
+	// arrange
+	var requests []deepLRequest
+	var authorizations []string
+	server := deepLServerStub(t, &requests, &authorizations)
+	defer server.Close()
+	translator := NewDeepL(server.Client(), DeepLOptions{AuthKey: "key", URL: server.URL, GlossaryID: "glossary"})
+
+	// act
+	results, err := translator.Translate(models.TranslationQuery{Target: "ja", Q: []string{"text"}})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"translated text"}, results)
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "JA", requests[0].TargetLang)
+	assert.Empty(t, requests[0].SourceLang)
+	assert.Empty(t, requests[0].GlossaryID)
+}
+
+func TestDeepLTranslate_WhenQuotaExceeded_ShouldReturnError(t *testing.T) {
+	// arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(456)
+		fmt.Fprint(w, `{"message": "Quota Exceeded"}`)
+	}))
+	defer server.Close()
+	translator := NewDeepL(server.Client(), DeepLOptions{AuthKey: "key", URL: server.URL})
+
+	// act
+	_, err := translator.Translate(models.TranslationQuery{Target: "de", Q: []string{"text"}})
+
+	// assert
+	assert.ErrorContains(t, err, "456")
+	assert.ErrorContains(t, err, "Quota Exceeded")
+}
+
+func TestNewDeepL_ShouldSelectEndpointOfPlan(t *testing.T) {
+	// arrange
+	tests := []struct {
+		options  DeepLOptions
+		expected string
+	}{
+		{DeepLOptions{AuthKey: "key"}, deepLProURL},
+		{DeepLOptions{AuthKey: "key:fx"}, deepLFreeURL},
+		{DeepLOptions{AuthKey: "key", Free: true}, deepLFreeURL},
+		{DeepLOptions{AuthKey: "key:fx", URL: "http://localhost:8080/"}, "http://localhost:8080"},
+	}
+
+	for _, test := range tests {
+		// act
+		translator := NewDeepL(nil, test.options).(*DeepL)
+
+		// assert
+		assert.Equal(t, test.expected, translator.url)
+	}
+}
@@ -29,6 +29,7 @@ func (f *Fake) TranslateResources(targetLanguageCode string, resources []models.
 			Key:          r.Key,
 			Text:         gofakeit.ProductName(),
 			LanguageCode: targetLanguageCode,
+			Status:       models.StatusMachine,
 		}
 		results = append(results, newResource)
 	}
@@ -74,7 +74,7 @@ func (t *Google) TranslateResources(targetLanguageCode string, resources []model
 	}
 
 	for i, text := range translations {
-		results = append(results, models.Resource{Key: resources[i].Key, LanguageCode: targetLanguageCode, Text: text})
+		results = append(results, models.Resource{Key: resources[i].Key, LanguageCode: targetLanguageCode, Text: text, Status: models.StatusMachine})
 	}
 
 	return results, nil
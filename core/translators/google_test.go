@@ -64,5 +64,6 @@ func TestTranslateResources_ShouldReturnResourcesWithTranslatedText(t *testing.T
 			slices.Contains(inputs, func(r models.Resource) bool { return r.Key == translatedResource.Key }),
 			"translated resource's Key should be in input data")
 		assert.Equal(t, translatedResource.LanguageCode, expectedLanguageCode)
+		assert.Equal(t, models.StatusMachine, translatedResource.Status)
 	}
 }
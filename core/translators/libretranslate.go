@@ -0,0 +1,193 @@
+package translators
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LibreTranslateOptions configures a LibreTranslate server, APIKey is only needed when the server requires keys.
+type LibreTranslateOptions struct {
+	URL        string
+	APIKey     string
+	BatchLimit int
+}
+
+// LibreTranslate translates with a self-hosted LibreTranslate server, or other Argos Translate servers with the same api.
+// The languages of the server are cached for an hour.
+type LibreTranslate struct {
+	client  *http.Client
+	url     string
+	options LibreTranslateOptions
+
+	mu          sync.Mutex
+	languages   []models.SupportedLanguage
+	languagesAt time.Time
+}
+
+var _ contracts.Translator = (*LibreTranslate)(nil)
+var _ contracts.LanguageLister = (*LibreTranslate)(nil)
+
+const libreTranslateLanguagesTTL = time.Hour
+
+func NewLibreTranslate(client *http.Client, options LibreTranslateOptions) *LibreTranslate {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if options.BatchLimit <= 0 {
+		options.BatchLimit = 50
+	}
+
+	return &LibreTranslate{client: client, url: strings.TrimSuffix(options.URL, "/"), options: options}
+}
+
+type libreTranslateRequest struct {
+	Q      []string `json:"q"`
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Format string   `json:"format"`
+	APIKey string   `json:"api_key,omitempty"`
+}
+
+type libreTranslateResponse struct {
+	TranslatedText []string `json:"translatedText"`
+	Error          string   `json:"error"`
+}
+
+func (t *LibreTranslate) Translate(tq models.TranslationQuery) ([]string, error) {
+	return t.translate(tq.Q, "auto", tq.Target)
+}
+
+func (t *LibreTranslate) translate(texts []string, sourceLanguageCode, targetLanguageCode string) ([]string, error) {
+	emptyResult := []string{}
+	if len(texts) == 0 {
+		return emptyResult, nil
+	}
+
+	request := libreTranslateRequest{
+		Q:      texts,
+		Source: sourceLanguageCode,
+		Target: targetLanguageCode,
+		Format: "text",
+		APIKey: t.options.APIKey,
+	}
+
+	var response libreTranslateResponse
+	if err := t.send(http.MethodPost, "/translate", request, &response); err != nil {
+		return emptyResult, err
+	}
+
+	return response.TranslatedText, nil
+}
+
+// send calls the server and decodes its response into result, errors of the server are returned with their message.
+func (t *LibreTranslate) send(method, path string, request any, result any) error {
+	var body bytes.Buffer
+	if request != nil {
+		if err := json.NewEncoder(&body).Encode(request); err != nil {
+			return err
+		}
+	}
+
+	httpRequest, err := http.NewRequest(method, t.url+path, &body)
+	if err != nil {
+		return err
+	}
+	if request != nil {
+		httpRequest.Header.Set("Content-Type", "application/json")
+	}
+
+	httpResponse, err := t.client.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		var response libreTranslateResponse
+		json.NewDecoder(httpResponse.Body).Decode(&response)
+		if response.Error == "" {
+			response.Error = http.StatusText(httpResponse.StatusCode)
+		}
+		return fmt.Errorf("libretranslate returned status %d: %v", httpResponse.StatusCode, response.Error)
+	}
+
+	return json.NewDecoder(httpResponse.Body).Decode(result)
+}
+
+// TranslateResources translates from the language of the resources, which must be supported by the server.
+func (t *LibreTranslate) TranslateResources(targetLanguageCode string, resources []models.Resource) ([]models.Resource, error) {
+	results, emptyResult := []models.Resource{}, []models.Resource{}
+	if len(resources) == 0 {
+		return emptyResult, nil
+	}
+
+	if len(resources) > t.GetBatchLimit() {
+		return emptyResult, fmt.Errorf("there's a limit of processing %d resources per request but found %d", t.GetBatchLimit(), len(resources))
+	}
+
+	sourceLanguageCode := resources[0].LanguageCode
+	if err := supports(t, sourceLanguageCode, targetLanguageCode); err != nil {
+		return emptyResult, fmt.Errorf("libretranslate %w", err)
+	}
+
+	var textsToTranslate []string
+	for _, resource := range resources {
+		textsToTranslate = append(textsToTranslate, resource.Text)
+	}
+
+	translations, err := t.translate(textsToTranslate, sourceLanguageCode, targetLanguageCode)
+	if err != nil {
+		return emptyResult, err
+	}
+
+	if translationsCount, resourcesCount := len(translations), len(resources); translationsCount != resourcesCount {
+		return emptyResult, fmt.Errorf("translation was done but expected %d results and got %d", resourcesCount, translationsCount)
+	}
+
+	for i, text := range translations {
+		results = append(results, models.Resource{Key: resources[i].Key, LanguageCode: targetLanguageCode, Text: text, Status: models.StatusMachine})
+	}
+
+	return results, nil
+}
+
+// SupportedLanguages returns the languages of the server, older servers don't list the targets of each language and
+// can translate between all of them.
+func (t *LibreTranslate) SupportedLanguages() ([]models.SupportedLanguage, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.languages != nil && time.Since(t.languagesAt) < libreTranslateLanguagesTTL {
+		return t.languages, nil
+	}
+
+	var languages []models.SupportedLanguage
+	if err := t.send(http.MethodGet, "/languages", nil, &languages); err != nil {
+		return nil, err
+	}
+
+	for i := range languages {
+		if len(languages[i].Targets) > 0 {
+			continue
+		}
+		for _, language := range languages {
+			if language.Code != languages[i].Code {
+				languages[i].Targets = append(languages[i].Targets, language.Code)
+			}
+		}
+	}
+
+	t.languages, t.languagesAt = languages, time.Now()
+	return languages, nil
+}
+
+func (t *LibreTranslate) GetBatchLimit() int {
+	return t.options.BatchLimit
+}
@@ -0,0 +1,132 @@
+package translators
+
+import (
+	"encoding/json"
+	"fmt"
+	"gotranslate/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// libreTranslateServerStub translates every text to "translated <text>", languages is the response of /languages
+func libreTranslateServerStub(t *testing.T, languages string, requests *[]libreTranslateRequest, languageRequests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/languages":
+			*languageRequests++
+			fmt.Fprint(w, languages)
+		case "/translate":
+			var request libreTranslateRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			*requests = append(*requests, request)
+
+			response := libreTranslateResponse{}
+			for _, text := range request.Q {
+				response.TranslatedText = append(response.TranslatedText, "translated "+text)
+			}
+			json.NewEncoder(w).Encode(response)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+const libreTranslateLanguages = `[
+	{"code": "en", "name": "English", "targets": ["de", "fi"]},
+	{"code": "de", "name": "German", "targets": ["en"]}
+]`
+
+func TestLibreTranslateTranslateResources_ShouldReturnTranslatedResources(t *testing.T) {
+	// arrange
+	var requests []libreTranslateRequest
+	var languageRequests int
+	server := libreTranslateServerStub(t, libreTranslateLanguages, &requests, &languageRequests)
+	defer server.Close()
+	translator := NewLibreTranslate(server.Client(), LibreTranslateOptions{URL: server.URL + "/", APIKey: "key"})
+	inputs := []models.Resource{
+		{Key: "key1", LanguageCode: "en", Text: "text 1"},
+		{Key: "key2", LanguageCode: "en", Text: "text 2"},
+	}
+
+	// act
+	results, err := translator.TranslateResources("fi", inputs)
+	_, secondErr := translator.TranslateResources("de", inputs)
+
+	// assert
+	assert.NoError(t, err)
+	assert.NoError(t, secondErr)
+	assert.Equal(t, []models.Resource{
+		{Key: "key1", LanguageCode: "fi", Text: "translated text 1", Status: models.StatusMachine},
+		{Key: "key2", LanguageCode: "fi", Text: "translated text 2", Status: models.StatusMachine},
+	}, results)
+	assert.Equal(t, libreTranslateRequest{Q: []string{"text 1", "text 2"}, Source: "en", Target: "fi", Format: "text", APIKey: "key"}, requests[0])
+	assert.Equal(t, 1, languageRequests, "the languages should be cached")
+}
+
+func TestLibreTranslateTranslateResources_WhenTargetNotSupported_ShouldReturnError(t *testing.T) {
+	// arrange
+	var requests []libreTranslateRequest
+	var languageRequests int
+	server := libreTranslateServerStub(t, libreTranslateLanguages, &requests, &languageRequests)
+	defer server.Close()
+	translator := NewLibreTranslate(server.Client(), LibreTranslateOptions{URL: server.URL})
+
+	// act
+	_, err := translator.TranslateResources("ja", []models.Resource{{Key: "key1", LanguageCode: "en", Text: "text 1"}})
+
+	// assert
+	assert.ErrorContains(t, err, "from 'en' to 'ja'")
+	assert.Empty(t, requests)
+}
+
+func TestLibreTranslateSupportedLanguages_WhenServerHasNoTargets_ShouldTranslateBetweenAll(t *testing.T) {
+	// arrange
+	var requests []libreTranslateRequest
+	var languageRequests int
+	languages := `[{"code": "en", "name": "English"}, {"code": "de", "name": "German"}, {"code": "fi", "name": "Finnish"}]`
+	server := libreTranslateServerStub(t, languages, &requests, &languageRequests)
+	defer server.Close()
+	translator := NewLibreTranslate(server.Client(), LibreTranslateOptions{URL: server.URL})
+
+	// act
+	results, err := translator.SupportedLanguages()
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, []string{"de", "fi"}, results[0].Targets)
+	assert.Equal(t, []string{"en", "fi"}, results[1].Targets)
+}
+
+func TestLibreTranslateTranslate_WhenServerFails_ShouldReturnItsError(t *testing.T) {
+	// arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"error": "Invalid API key"}`)
+	}))
+	defer server.Close()
+	translator := NewLibreTranslate(server.Client(), LibreTranslateOptions{URL: server.URL})
+
+	// act
+	_, err := translator.Translate(models.TranslationQuery{Target: "de", Q: []string{"text"}})
+
+	// assert
+	assert.ErrorContains(t, err, "403")
+	assert.ErrorContains(t, err, "Invalid API key")
+}
+
+func TestLibreTranslate_ShouldUseConfiguredBatchLimit(t *testing.T) {
+	// arrange
+	defaultLimit := NewLibreTranslate(nil, LibreTranslateOptions{URL: "http://localhost:5000"})
+	configured := NewLibreTranslate(nil, LibreTranslateOptions{URL: "http://localhost:5000", BatchLimit: 10})
+
+	// act
+	batchLimit := configured.GetBatchLimit()
+
+	// assert
+	assert.Equal(t, 10, batchLimit)
+	assert.Equal(t, 50, defaultLimit.GetBatchLimit())
+}
@@ -0,0 +1,111 @@
+package translators
+
+import (
+	"context"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"math"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/time/rate"
+)
+
+// Limits of a translation service, the limits that are not set aren't applied.
+type Limits struct {
+	// Concurrency is how many batches of a job are translated at the same time
+	Concurrency         int
+	RequestsPerSecond   float64
+	CharactersPerMinute int
+}
+
+// Limited waits for the token buckets of the requests and characters before calling the translator,
+// so jobs translating batches concurrently stay inside the quota of the provider.
+type Limited struct {
+	translator  contracts.Translator
+	concurrency int
+	requests    *rate.Limiter
+	characters  *rate.Limiter
+}
+
+var _ contracts.Translator = (*Limited)(nil)
+var _ contracts.ConcurrentTranslator = (*Limited)(nil)
+var _ contracts.LanguageLister = (*Limited)(nil)
+
+func NewLimited(translator contracts.Translator, limits Limits) *Limited {
+	limited := &Limited{translator: translator, concurrency: max(limits.Concurrency, 1)}
+	if limits.RequestsPerSecond > 0 {
+		limited.requests = rate.NewLimiter(rate.Limit(limits.RequestsPerSecond), int(math.Ceil(limits.RequestsPerSecond)))
+	}
+	if limits.CharactersPerMinute > 0 {
+		limited.characters = rate.NewLimiter(rate.Every(time.Minute/time.Duration(limits.CharactersPerMinute)), limits.CharactersPerMinute)
+	}
+	return limited
+}
+
+func (t *Limited) Translate(tq models.TranslationQuery) ([]string, error) {
+	characters := 0
+	for _, text := range tq.Q {
+		characters += utf8.RuneCountInString(text)
+	}
+	if err := t.wait(t.requestsOf(len(tq.Q)), characters); err != nil {
+		return []string{}, err
+	}
+
+	return t.translator.Translate(tq)
+}
+
+func (t *Limited) TranslateResources(targetLanguageCode string, resources []models.Resource) ([]models.Resource, error) {
+	characters := 0
+	for _, resource := range resources {
+		characters += utf8.RuneCountInString(resource.Text)
+	}
+	if err := t.wait(t.requestsOf(len(resources)), characters); err != nil {
+		return nil, err
+	}
+
+	return t.translator.TranslateResources(targetLanguageCode, resources)
+}
+
+// requestsOf returns the requests to the provider translating the texts takes, one per call unless the translator counts them.
+func (t *Limited) requestsOf(texts int) int {
+	if counter, ok := t.translator.(contracts.RequestCounter); ok {
+		return max(counter.Requests(texts), 1)
+	}
+	return 1
+}
+
+// wait blocks until the requests with the characters are allowed. Requests over the burst wait for the following tokens,
+// and a request with more characters than the limit per minute waits for the whole minute.
+func (t *Limited) wait(requests, characters int) error {
+	ctx := context.Background()
+	if t.requests != nil {
+		for remaining := requests; remaining > 0; remaining -= t.requests.Burst() {
+			if err := t.requests.WaitN(ctx, min(remaining, t.requests.Burst())); err != nil {
+				return err
+			}
+		}
+	}
+	if t.characters != nil && characters > 0 {
+		if err := t.characters.WaitN(ctx, min(characters, t.characters.Burst())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Limited) GetBatchLimit() int {
+	return t.translator.GetBatchLimit()
+}
+
+func (t *Limited) Concurrency() int {
+	return t.concurrency
+}
+
+// SupportedLanguages lists the languages of the translator without waiting for the limits.
+func (t *Limited) SupportedLanguages() ([]models.SupportedLanguage, error) {
+	if lister, ok := t.translator.(contracts.LanguageLister); ok {
+		return lister.SupportedLanguages()
+	}
+	return nil, contracts.ErrLanguagesUnknown
+}
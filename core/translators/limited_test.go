@@ -0,0 +1,112 @@
+package translators
+
+import (
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimited_WhenRequestsPerSecondExceeded_ShouldWait(t *testing.T) {
+	// arrange
+	translator := NewLimited(&Fake{}, Limits{RequestsPerSecond: 10})
+	query := models.TranslationQuery{Target: "fi", Q: []string{"text"}}
+
+	// act
+	started := time.Now()
+	for i := 0; i < 13; i++ {
+		_, err := translator.Translate(query)
+		assert.NoError(t, err)
+	}
+	elapsed := time.Since(started)
+
+	// assert
+	assert.GreaterOrEqual(t, elapsed, 250*time.Millisecond, "the 3 requests over the burst should wait 100ms each")
+}
+
+// perTextTranslator sends a request per text, like AWS
+type perTextTranslator struct {
+	Fake
+}
+
+func (t *perTextTranslator) Requests(texts int) int {
+	return texts
+}
+
+func TestLimited_WhenTranslatorSendsARequestPerText_ShouldWaitForEveryText(t *testing.T) {
+	// arrange
+	translator := NewLimited(&perTextTranslator{}, Limits{RequestsPerSecond: 10})
+	resources := []models.Resource{{Key: "a", Text: "a"}, {Key: "b", Text: "b"}, {Key: "c", Text: "c"}, {Key: "d", Text: "d"}, {Key: "e", Text: "e"}}
+
+	// act
+	started := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := translator.TranslateResources("fi", resources)
+		assert.NoError(t, err)
+	}
+	elapsed := time.Since(started)
+
+	// assert
+	assert.GreaterOrEqual(t, elapsed, 450*time.Millisecond, "the 5 texts over the burst should wait 100ms each")
+}
+
+func TestLimited_WhenCharactersPerMinuteExceeded_ShouldWait(t *testing.T) {
+	// arrange
+	translator := NewLimited(&Fake{}, Limits{CharactersPerMinute: 600}) // 10 characters per second
+	resources := []models.Resource{{Key: "a", Text: "ääääää"}, {Key: "b", Text: "bbbb"}}
+
+	// act
+	started := time.Now()
+	_, err := translator.TranslateResources("fi", resources)
+	assert.NoError(t, err)
+	for i := 0; i < 59; i++ {
+		_, err = translator.TranslateResources("fi", resources)
+		assert.NoError(t, err)
+	}
+	_, err = translator.TranslateResources("fi", resources[1:])
+	elapsed := time.Since(started)
+
+	// assert
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, elapsed, 350*time.Millisecond, "4 characters over the minute should wait 400ms")
+}
+
+func TestLimited_WhenBatchLargerThanMinute_ShouldNotFail(t *testing.T) {
+	// arrange
+	translator := NewLimited(&Fake{}, Limits{CharactersPerMinute: 60000})
+	query := models.TranslationQuery{Target: "fi", Q: []string{string(make([]rune, 60001))}}
+
+	// act
+	results, err := translator.Translate(query)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+}
+
+func TestLimited_ShouldKeepBatchLimitAndSetConcurrency(t *testing.T) {
+	// arrange
+	unlimited := NewLimited(&Fake{}, Limits{})
+	limited := NewLimited(&Fake{}, Limits{Concurrency: 3})
+
+	// act
+	batchLimit := limited.GetBatchLimit()
+
+	// assert
+	assert.Equal(t, 5, batchLimit)
+	assert.Equal(t, 1, unlimited.Concurrency())
+	assert.Equal(t, 3, limited.Concurrency())
+}
+
+func TestLimitedSupportedLanguages_WhenTranslatorCantListThem_ShouldReturnErrLanguagesUnknown(t *testing.T) {
+	// arrange
+	translator := NewLimited(&Fake{}, Limits{Concurrency: 2})
+
+	// act
+	_, err := translator.SupportedLanguages()
+
+	// assert
+	assert.ErrorIs(t, err, contracts.ErrLanguagesUnknown)
+}
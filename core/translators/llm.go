@@ -0,0 +1,272 @@
+package translators
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gotranslate/core/contracts"
+	"gotranslate/models"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidLLMResponse is returned when the model doesn't answer with exactly one translation per key.
+var ErrInvalidLLMResponse = errors.New("invalid translations from the model")
+
+// LLMGlossaryEntry is the translation the model must use for a term in the target Language.
+type LLMGlossaryEntry struct {
+	Language string
+	Source   string
+	Target   string
+}
+
+// LLMOptions configures a model served with an OpenAI-compatible chat completions api, like Ollama, the llama.cpp
+// server or vLLM. URL is the base url of the api, e.g. http://localhost:11434/v1, and APIKey is only sent when it's set.
+type LLMOptions struct {
+	URL         string
+	APIKey      string
+	Model       string
+	Temperature float64
+	BatchLimit  int
+	Glossary    []LLMGlossaryEntry
+}
+
+// LLM translates a batch of resources in one chat completion. The prompt has the key and description of every text, and
+// the other texts of the batch, which have nearby keys, are the context. The model answers with a JSON translation per key.
+type LLM struct {
+	client  *http.Client
+	url     string
+	options LLMOptions
+}
+
+var _ contracts.Translator = (*LLM)(nil)
+
+func NewLLM(client *http.Client, options LLMOptions) contracts.Translator {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if options.BatchLimit <= 0 {
+		options.BatchLimit = 20
+	}
+
+	return &LLM{client: client, url: strings.TrimSuffix(options.URL, "/"), options: options}
+}
+
+const llmSystemPrompt = `You translate the user interface strings of an application.
+You receive a JSON object with the source and target language, a glossary and the strings to translate.
+Strings with keys that share a prefix are on the same screen, use them and the descriptions as context.
+Always use the glossary translation of a term. Keep placeholders like {name}, %s, %d and {{count}}, HTML tags and line breaks unchanged.
+Answer only with a JSON object like {"translations": [{"key": "the key", "text": "the translation"}]}, with exactly one translation for every key.`
+
+type llmString struct {
+	Key         string `json:"key"`
+	Text        string `json:"text"`
+	Description string `json:"description,omitempty"`
+}
+
+type llmPrompt struct {
+	SourceLanguage string            `json:"source_language,omitempty"`
+	TargetLanguage string            `json:"target_language"`
+	Glossary       map[string]string `json:"glossary,omitempty"`
+	Strings        []llmString       `json:"strings"`
+}
+
+type llmMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type llmRequest struct {
+	Model          string            `json:"model"`
+	Messages       []llmMessage      `json:"messages"`
+	Temperature    float64           `json:"temperature"`
+	ResponseFormat map[string]string `json:"response_format"`
+}
+
+type llmResponse struct {
+	Choices []struct {
+		Message llmMessage `json:"message"`
+	} `json:"choices"`
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type llmTranslations struct {
+	Translations []llmString `json:"translations"`
+}
+
+// Translate numbers the texts to use them as keys.
+func (t *LLM) Translate(tq models.TranslationQuery) ([]string, error) {
+	var texts []llmString
+	for i, text := range tq.Q {
+		texts = append(texts, llmString{Key: strconv.Itoa(i + 1), Text: text})
+	}
+
+	translations, err := t.translate(texts, "", tq.Target)
+	if err != nil {
+		return []string{}, err
+	}
+
+	results := []string{}
+	for _, text := range texts {
+		results = append(results, translations[text.Key])
+	}
+	return results, nil
+}
+
+func (t *LLM) TranslateResources(targetLanguageCode string, resources []models.Resource) ([]models.Resource, error) {
+	results, emptyResult := []models.Resource{}, []models.Resource{}
+	if len(resources) == 0 {
+		return emptyResult, nil
+	}
+
+	if len(resources) > t.GetBatchLimit() {
+		return emptyResult, fmt.Errorf("there's a limit of processing %d resources per request but found %d", t.GetBatchLimit(), len(resources))
+	}
+
+	var texts []llmString
+	for _, resource := range resources {
+		texts = append(texts, llmString{Key: resource.Key, Text: resource.Text, Description: resource.Description})
+	}
+
+	translations, err := t.translate(texts, resources[0].LanguageCode, targetLanguageCode)
+	if err != nil {
+		return emptyResult, err
+	}
+
+	for _, resource := range resources {
+		results = append(results, models.Resource{Key: resource.Key, LanguageCode: targetLanguageCode, Text: translations[resource.Key], Status: models.StatusMachine})
+	}
+
+	return results, nil
+}
+
+// translate returns the translations by key, after checking there's exactly one for every key of the texts.
+func (t *LLM) translate(texts []llmString, sourceLanguageCode, targetLanguageCode string) (map[string]string, error) {
+	if len(texts) == 0 {
+		return map[string]string{}, nil
+	}
+
+	prompt, err := json.Marshal(llmPrompt{
+		SourceLanguage: sourceLanguageCode,
+		TargetLanguage: targetLanguageCode,
+		Glossary:       t.glossary(targetLanguageCode),
+		Strings:        texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := t.complete(string(prompt))
+	if err != nil {
+		return nil, err
+	}
+
+	return validLLMTranslations(texts, content)
+}
+
+func (t *LLM) glossary(targetLanguageCode string) map[string]string {
+	glossary := map[string]string{}
+	for _, entry := range t.options.Glossary {
+		if strings.EqualFold(entry.Language, targetLanguageCode) {
+			glossary[entry.Source] = entry.Target
+		}
+	}
+	return glossary
+}
+
+// complete sends the prompt and returns the answer of the model.
+func (t *LLM) complete(prompt string) (string, error) {
+	body, err := json.Marshal(llmRequest{
+		Model: t.options.Model,
+		Messages: []llmMessage{
+			{Role: "system", Content: llmSystemPrompt},
+			{Role: "user", Content: prompt},
+		},
+		Temperature:    t.options.Temperature,
+		ResponseFormat: map[string]string{"type": "json_object"},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	httpRequest, err := http.NewRequest(http.MethodPost, t.url+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	if t.options.APIKey != "" {
+		httpRequest.Header.Set("Authorization", "Bearer "+t.options.APIKey)
+	}
+
+	httpResponse, err := t.client.Do(httpRequest)
+	if err != nil {
+		return "", err
+	}
+	defer httpResponse.Body.Close()
+
+	var response llmResponse
+	decodeErr := json.NewDecoder(httpResponse.Body).Decode(&response)
+	if httpResponse.StatusCode != http.StatusOK {
+		if response.Error.Message == "" {
+			response.Error.Message = http.StatusText(httpResponse.StatusCode)
+		}
+		return "", fmt.Errorf("the model returned status %d: %v", httpResponse.StatusCode, response.Error.Message)
+	}
+	if decodeErr != nil {
+		return "", decodeErr
+	}
+
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("%w: the response has no choices", ErrInvalidLLMResponse)
+	}
+	return response.Choices[0].Message.Content, nil
+}
+
+// validLLMTranslations reads the answer of the model, which must have one translation for every key and no other keys.
+// Models without JSON mode often wrap the answer in a markdown code block, which is removed.
+func validLLMTranslations(texts []llmString, content string) (map[string]string, error) {
+	content = strings.TrimSpace(content)
+	if strings.HasPrefix(content, "```") {
+		content = strings.TrimPrefix(content, "```json")
+		content = strings.TrimPrefix(content, "```")
+		content = strings.TrimSuffix(content, "```")
+	}
+
+	var answer llmTranslations
+	if err := json.Unmarshal([]byte(content), &answer); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLLMResponse, err)
+	}
+
+	expected := map[string]bool{}
+	for _, text := range texts {
+		expected[text.Key] = true
+	}
+
+	translations := map[string]string{}
+	for _, translation := range answer.Translations {
+		if !expected[translation.Key] {
+			return nil, fmt.Errorf("%w: unexpected key '%v'", ErrInvalidLLMResponse, translation.Key)
+		} else if _, duplicated := translations[translation.Key]; duplicated {
+			return nil, fmt.Errorf("%w: key '%v' was translated more than once", ErrInvalidLLMResponse, translation.Key)
+		} else if strings.TrimSpace(translation.Text) == "" {
+			return nil, fmt.Errorf("%w: key '%v' has an empty translation", ErrInvalidLLMResponse, translation.Key)
+		}
+		translations[translation.Key] = translation.Text
+	}
+
+	for _, text := range texts {
+		if _, translated := translations[text.Key]; !translated {
+			return nil, fmt.Errorf("%w: key '%v' wasn't translated", ErrInvalidLLMResponse, text.Key)
+		}
+	}
+
+	return translations, nil
+}
+
+func (t *LLM) GetBatchLimit() int {
+	return t.options.BatchLimit
+}
@@ -0,0 +1,129 @@
+package translators
+
+import (
+	"encoding/json"
+	"gotranslate/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// llmServerStub answers every chat completion with the content returned by answer for the prompt of the request
+func llmServerStub(t *testing.T, requests *[]llmRequest, answer func(prompt llmPrompt) string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		var request llmRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		*requests = append(*requests, request)
+
+		var prompt llmPrompt
+		assert.NoError(t, json.Unmarshal([]byte(request.Messages[len(request.Messages)-1].Content), &prompt))
+
+		response := llmResponse{}
+		response.Choices = append(response.Choices, struct {
+			Message llmMessage `json:"message"`
+		}{Message: llmMessage{Role: "assistant", Content: answer(prompt)}})
+		json.NewEncoder(w).Encode(response)
+	}))
+}
+
+// reversedTranslations translates every string, listing the translations in reverse order
+func reversedTranslations(prompt llmPrompt) string {
+	answer := llmTranslations{}
+	for i := len(prompt.Strings) - 1; i >= 0; i-- {
+		answer.Translations = append(answer.Translations, llmString{Key: prompt.Strings[i].Key, Text: "translated " + prompt.Strings[i].Text})
+	}
+	content, _ := json.Marshal(answer)
+	return string(content)
+}
+
+func TestLLMTranslateResources_ShouldSendContextAndMatchTranslationsByKey(t *testing.T) {
+	// arrange
+	var requests []llmRequest
+	server := llmServerStub(t, &requests, reversedTranslations)
+	defer server.Close()
+	translator := NewLLM(server.Client(), LLMOptions{
+		URL:   server.URL + "/v1",
+		Model: "llama3.1",
+		Glossary: []LLMGlossaryEntry{
+			{Language: "de", Source: "Workspace", Target: "Arbeitsbereich"},
+			{Language: "fi", Source: "Workspace", Target: "Työtila"},
+		},
+	})
+	inputs := []models.Resource{
+		{Key: "workspace.title", LanguageCode: "en", Text: "Workspace", Description: "title of the page"},
+		{Key: "workspace.save", LanguageCode: "en", Text: "Save"},
+	}
+
+	// act
+	results, err := translator.TranslateResources("de", inputs)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Resource{
+		{Key: "workspace.title", LanguageCode: "de", Text: "translated Workspace", Status: models.StatusMachine},
+		{Key: "workspace.save", LanguageCode: "de", Text: "translated Save", Status: models.StatusMachine},
+	}, results)
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "llama3.1", requests[0].Model)
+	assert.Equal(t, "system", requests[0].Messages[0].Role)
+	var prompt llmPrompt
+	json.Unmarshal([]byte(requests[0].Messages[1].Content), &prompt)
+	assert.Equal(t, llmPrompt{
+		SourceLanguage: "en",
+		TargetLanguage: "de",
+		Glossary:       map[string]string{"Workspace": "Arbeitsbereich"},
+		Strings: []llmString{
+			{Key: "workspace.title", Text: "Workspace", Description: "title of the page"},
+			{Key: "workspace.save", Text: "Save"},
+		},
+	}, prompt)
+}
+
+func TestLLMTranslate_WhenAnswerIsInCodeBlock_ShouldReturnTranslationsInOrder(t *testing.T) {
+	// arrange
+	var requests []llmRequest
+	server := llmServerStub(t, &requests, func(prompt llmPrompt) string {
+		return "```json\n" + reversedTranslations(prompt) + "\n```"
+	})
+	defer server.Close()
+	translator := NewLLM(server.Client(), LLMOptions{URL: server.URL + "/v1", Model: "llama3.1"})
+
+	// act
+	results, err := translator.Translate(models.TranslationQuery{Target: "fi", Q: []string{"text 1", "text 2"}})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"translated text 1", "translated text 2"}, results)
+}
+
+func TestLLMTranslateResources_WhenAnswerIsInvalid_ShouldReturnError(t *testing.T) {
+	// arrange
+	tests := map[string]string{
+		"missing key":    `{"translations": [{"key": "key1", "text": "teksti 1"}]}`,
+		"duplicated key": `{"translations": [{"key": "key1", "text": "teksti 1"}, {"key": "key1", "text": "teksti 1"}, {"key": "key2", "text": "teksti 2"}]}`,
+		"unexpected key": `{"translations": [{"key": "key1", "text": "teksti 1"}, {"key": "key2", "text": "teksti 2"}, {"key": "key3", "text": "teksti 3"}]}`,
+		"empty text":     `{"translations": [{"key": "key1", "text": "teksti 1"}, {"key": "key2", "text": " "}]}`,
+		"not json":       `Here are the translations: teksti 1, teksti 2`,
+	}
+	inputs := []models.Resource{
+		{Key: "key1", LanguageCode: "en", Text: "text 1"},
+		{Key: "key2", LanguageCode: "en", Text: "text 2"},
+	}
+
+	for name, answer := range tests {
+		var requests []llmRequest
+		server := llmServerStub(t, &requests, func(prompt llmPrompt) string { return answer })
+		translator := NewLLM(server.Client(), LLMOptions{URL: server.URL + "/v1", Model: "llama3.1"})
+
+		// act
+		results, err := translator.TranslateResources("fi", inputs)
+		server.Close()
+
+		// assert
+		assert.ErrorIs(t, err, ErrInvalidLLMResponse, name)
+		assert.Empty(t, results, name)
+	}
+}
@@ -2,20 +2,27 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"gotranslate/api/rest"
 	"gotranslate/core/contracts"
+	"gotranslate/core/formats"
 	"gotranslate/core/messages"
 	"gotranslate/core/queue"
 	"gotranslate/core/repository"
 	"gotranslate/core/translators"
 	"gotranslate/models"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"cloud.google.com/go/translate"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/spf13/viper"
 	"google.golang.org/api/option"
 	"gorm.io/driver/postgres"
@@ -25,18 +32,39 @@ import (
 func main() {
 	loadConfig()
 
-	repo, cleanup := initializeRepository()
+	repo, db, cleanup := initializeRepository()
 	defer cleanup()
 
 	translator := initializeTranslationService()
 
-	queueClient := initializeQueue(repo, translator)
-	defer queueClient.Close()
+	queueClient := initializeQueue(repo, translator, db)
+
+	formatters := formats.NewDefaultRegistry()
 
 	auth := loadAuthenticationConfig()
-	var router = rest.NewRouter(repo, translator, queueClient, auth)
+	var router = rest.NewRouter(repo, translator, queueClient, formatters, auth)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: "localhost:3000", Handler: router}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	log.Println("shutting down, finishing the requests in progress")
 
-	router.Run("localhost:3000")
+	// the requests finish before the queue is closed, so the messages they publish are still handled
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("unable to finish the requests in progress. Error: %v", err)
+	}
+	queueClient.Close()
 }
 
 func loadConfig() {
@@ -58,7 +86,8 @@ func loadAuthenticationConfig() models.AuthConfig {
 	return auth
 }
 
-func initializeRepository() (repo contracts.ResoureRepository, cleanup func()) {
+// initializeRepository also returns the database of the repository for the queue, it's nil for files.
+func initializeRepository() (repo contracts.ResoureRepository, db *sql.DB, cleanup func()) {
 	persistenceMedium := viper.GetString("persistence")
 	if persistenceMedium == "file" {
 		file := viper.GetString("file")
@@ -68,7 +97,7 @@ func initializeRepository() (repo contracts.ResoureRepository, cleanup func()) {
 			log.Fatal(err)
 		}
 
-		return repo, func() {}
+		return repo, nil, func() {}
 	} else if persistenceMedium == "postgres" {
 		pool, err := pgxpool.New(context.Background(), viper.GetString("database.connection_string"))
 		if err != nil {
@@ -85,7 +114,9 @@ func initializeRepository() (repo contracts.ResoureRepository, cleanup func()) {
 			log.Fatal(err)
 		}
 
-		return repo, func() { pool.Close() }
+		sqlDB := stdlib.OpenDBFromPool(pool)
+
+		return repo, sqlDB, func() { sqlDB.Close(); pool.Close() }
 	} else if persistenceMedium == "gorm" {
 		db, err := gorm.Open(postgres.Open(viper.GetString("database.connection_string")), &gorm.Config{})
 		if err != nil {
@@ -108,14 +139,43 @@ func initializeRepository() (repo contracts.ResoureRepository, cleanup func()) {
 		sqlDB.SetMaxIdleConns(10)
 		sqlDB.SetConnMaxLifetime(time.Hour)
 
-		return repo, func() { sqlDB.Close() }
+		return repo, sqlDB, func() { sqlDB.Close() }
 	} else {
 		panic(fmt.Errorf("no repository implemented for selection %v", persistenceMedium))
 	}
 }
 
 func initializeTranslationService() contracts.Translator {
-	if service := viper.GetString("translation"); service == "" {
+	service := viper.GetString("translation")
+	if service != "chain" {
+		return newTranslator(service)
+	}
+
+	var chain []struct {
+		Translator string
+		Languages  []string
+	}
+	if err := viper.UnmarshalKey("chain", &chain); err != nil {
+		log.Fatal(err)
+	} else if len(chain) == 0 {
+		log.Fatal(errors.New("chain has no translators"))
+	}
+
+	// a translator used for several languages shares its limits
+	created := map[string]contracts.Translator{}
+	var links []translators.ChainLink
+	for _, link := range chain {
+		if _, exists := created[link.Translator]; !exists {
+			created[link.Translator] = newTranslator(link.Translator)
+		}
+		links = append(links, translators.ChainLink{Name: link.Translator, Translator: created[link.Translator], Languages: link.Languages})
+	}
+
+	return translators.NewChain(links...)
+}
+
+func newTranslator(service string) contracts.Translator {
+	if service == "" {
 		log.Fatal(errors.New("translation not configured"))
 	} else if service == "google" {
 		apiKey := viper.GetString("google_api_key")
@@ -128,9 +188,70 @@ func initializeTranslationService() contracts.Translator {
 			log.Fatal(err)
 		}
 
-		return translators.NewGoogle(client)
+		return withLimits(service, translators.NewGoogle(client))
+	} else if service == "deepl" {
+		authKey := viper.GetString("deepl.auth_key")
+		if authKey == "" {
+			log.Fatal(errors.New("deepl.auth_key not set"))
+		}
+
+		options := translators.DeepLOptions{
+			AuthKey:    authKey,
+			Free:       viper.GetBool("deepl.free"),
+			URL:        viper.GetString("deepl.url"),
+			Formality:  viper.GetString("deepl.formality"),
+			GlossaryID: viper.GetString("deepl.glossary_id"),
+		}
+		return withLimits(service, translators.NewDeepL(&http.Client{Timeout: 30 * time.Second}, options))
+	} else if service == "libretranslate" {
+		url := viper.GetString("libretranslate.url")
+		if url == "" {
+			log.Fatal(errors.New("libretranslate.url not set"))
+		}
+
+		options := translators.LibreTranslateOptions{
+			URL:        url,
+			APIKey:     viper.GetString("libretranslate.api_key"),
+			BatchLimit: viper.GetInt("libretranslate.batch_size"),
+		}
+		return withLimits(service, translators.NewLibreTranslate(&http.Client{Timeout: 2 * time.Minute}, options))
+	} else if service == "azure" {
+		key := viper.GetString("azure.key")
+		if key == "" {
+			log.Fatal(errors.New("azure.key not set"))
+		}
+
+		options := translators.AzureOptions{Key: key, Region: viper.GetString("azure.region"), URL: viper.GetString("azure.url")}
+		return withLimits(service, translators.NewAzure(&http.Client{Timeout: 30 * time.Second}, options))
+	} else if service == "aws" {
+		options := translators.AWSOptions{
+			Region:          viper.GetString("aws.region"),
+			AccessKeyID:     viper.GetString("aws.access_key_id"),
+			SecretAccessKey: viper.GetString("aws.secret_access_key"),
+			SessionToken:    viper.GetString("aws.session_token"),
+			URL:             viper.GetString("aws.url"),
+		}
+		if options.Region == "" || options.AccessKeyID == "" || options.SecretAccessKey == "" {
+			log.Fatal(errors.New("aws.region, aws.access_key_id and aws.secret_access_key must be set"))
+		}
+		return withLimits(service, translators.NewAWS(&http.Client{Timeout: 30 * time.Second}, options))
+	} else if service == "llm" {
+		options := translators.LLMOptions{
+			URL:         viper.GetString("llm.url"),
+			APIKey:      viper.GetString("llm.api_key"),
+			Model:       viper.GetString("llm.model"),
+			Temperature: viper.GetFloat64("llm.temperature"),
+			BatchLimit:  viper.GetInt("llm.batch_size"),
+		}
+		if options.URL == "" || options.Model == "" {
+			log.Fatal(errors.New("llm.url and llm.model must be set"))
+		}
+		if err := viper.UnmarshalKey("llm.glossary", &options.Glossary); err != nil {
+			log.Fatal(err)
+		}
+		return withLimits(service, translators.NewLLM(&http.Client{Timeout: 5 * time.Minute}, options))
 	} else if service == "fake" {
-		return &translators.Fake{}
+		return withLimits(service, &translators.Fake{})
 	} else {
 		log.Fatal(errors.New("unsupported translation service"))
 	}
@@ -138,26 +259,79 @@ func initializeTranslationService() contracts.Translator {
 	return nil
 }
 
-func initializeQueue(repo contracts.ResoureRepository, translator contracts.Translator) contracts.QueueService {
+// withLimits wraps the translator with the limits configured in its translators section, when there are any.
+func withLimits(service string, translator contracts.Translator) contracts.Translator {
+	limits := translators.Limits{
+		Concurrency:         viper.GetInt("translators." + service + ".concurrency"),
+		RequestsPerSecond:   viper.GetFloat64("translators." + service + ".requests_per_second"),
+		CharactersPerMinute: viper.GetInt("translators." + service + ".characters_per_minute"),
+	}
+	if limits == (translators.Limits{}) {
+		return translator
+	}
+
+	return translators.NewLimited(translator, limits)
+}
+
+func initializeQueue(repo contracts.ResoureRepository, translator contracts.Translator, db *sql.DB) contracts.QueueService {
+	var queueClient contracts.QueueService
 	if queueType := viper.GetString("queue.type"); queueType == "" {
 		log.Fatal(errors.New("queue not configured"))
-	} else if queueType != "rabbitmq" {
-		log.Fatal(errors.New("unsupported queue"))
-	}
+	} else if queueType == "rabbitmq" {
+		url, queueName := viper.GetString("queue.url"), viper.GetString("queue.queue_name")
+		if url == "" || queueName == "" {
+			log.Fatal("****** queue configuration missing ******")
+		}
 
-	url, queueName := viper.GetString("queue.url"), viper.GetString("queue.queue_name")
-	if url == "" || queueName == "" {
-		log.Fatal("****** queue configuration missing ******")
-	}
+		rabbitMQ, err := queue.NewRabbitMQ(url, queueName, loadRetryOptions())
+		if err != nil {
+			log.Fatal(err)
+		}
+		queueClient = rabbitMQ
+	} else if queueType == "nats" {
+		url, queueName := viper.GetString("queue.url"), viper.GetString("queue.queue_name")
+		if url == "" || queueName == "" {
+			log.Fatal("****** queue configuration missing ******")
+		}
 
-	queueClient, err := queue.NewRabbitMQ(url, queueName)
-	if err != nil {
-		log.Fatal(err)
+		natsQueue, err := queue.NewNATS(url, queueName, loadRetryOptions())
+		if err != nil {
+			log.Fatal(err)
+		}
+		queueClient = natsQueue
+	} else if queueType == "memory" {
+		queueClient = queue.NewMemory(viper.GetInt("queue.concurrency"), viper.GetInt("queue.buffer_size"), loadRetryOptions())
+	} else if queueType == "postgres" {
+		if db == nil {
+			log.Fatal(errors.New("the postgres queue needs \"persistence\" to be postgres or gorm"))
+		}
+
+		queueName := viper.GetString("queue.queue_name")
+		visibilityTimeout := time.Duration(viper.GetInt("queue.visibility_timeout_ms")) * time.Millisecond
+		postgresQueue := queue.NewPostgres(db, queueName, viper.GetInt("queue.concurrency"), visibilityTimeout, loadRetryOptions())
+		if err := postgresQueue.Init(); err != nil {
+			log.Fatal(err)
+		}
+		queueClient = postgresQueue
+	} else {
+		log.Fatal(errors.New("unsupported queue"))
 	}
 
 	strategy := messages.GetMessageHandlers(repo, translator)
 
-	queueClient.Consume(strategy)
+	if err := queueClient.Consume(strategy); err != nil {
+		log.Fatal(err)
+	}
 
 	return queueClient
 }
+
+// loadRetryOptions reads how failed messages are retried, the values that aren't set use the defaults.
+func loadRetryOptions() queue.RetryOptions {
+	options := queue.RetryOptions{
+		MaxRetries:    viper.GetInt("queue.max_retries"),
+		RetryDelay:    time.Duration(viper.GetInt("queue.retry_delay_ms")) * time.Millisecond,
+		MaxRetryDelay: time.Duration(viper.GetInt("queue.max_retry_delay_ms")) * time.Millisecond,
+	}
+	return options.WithDefaults()
+}
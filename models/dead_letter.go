@@ -0,0 +1,16 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeadLetter is a message that couldn't be handled after all its retries, or that can never be handled (e.g. unknown type).
+type DeadLetter struct {
+	ID      string
+	Type    string
+	Body    json.RawMessage
+	Error   string
+	Retries int
+	DeadAt  time.Time
+}
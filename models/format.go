@@ -0,0 +1,29 @@
+package models
+
+type FormatOptions struct {
+	LanguageCodes []string // the languages to export, for source/target formats the first one is the source
+	Version       string
+	Nested        bool
+	Separator     string
+}
+
+// MainLanguage is the language of single language formats, or the source language of source/target formats.
+func (o FormatOptions) MainLanguage() string {
+	if len(o.LanguageCodes) == 0 {
+		return ""
+	}
+	return o.LanguageCodes[0]
+}
+
+type EncodedFile struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+	RenamedKeys map[string]string // keys the format doesn't allow, mapped to the name used in the file
+}
+
+// DecodedResource is a resource read from a file, SkipReason is set when it exists in the file but shouldn't be imported.
+type DecodedResource struct {
+	Resource   Resource
+	SkipReason string
+}
@@ -0,0 +1,14 @@
+package models
+
+const (
+	ImportCreated = "created"
+	ImportUpdated = "updated"
+	ImportSkipped = "skipped"
+	ImportInvalid = "invalid"
+)
+
+type ImportResult struct {
+	Key     string
+	Status  string
+	Message string `json:",omitempty"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+// Job tracks a translation running in the background, progress is counted in the batches sent to the translator.
+type Job struct {
+	ID             int64     `gorm:"column:id;primaryKey"`
+	Type           string    `gorm:"column:type;not null"`
+	Project        string    `gorm:"column:project;not null"`
+	SourceLanguage string    `gorm:"column:sourcelanguage;not null"`
+	TargetLanguage string    `gorm:"column:targetlanguage;not null"`
+	Username       string    `gorm:"column:username;not null"`
+	Status         string    `gorm:"column:status;not null"`
+	BatchesDone    int       `gorm:"column:batchesdone;not null"`
+	BatchesTotal   int       `gorm:"column:batchestotal;not null"`
+	Created        int64     `gorm:"column:created;not null"`
+	Skipped        int64     `gorm:"column:skipped;not null"`
+	Error          string    `gorm:"column:error;not null"`
+	CreatedAt      time.Time `gorm:"column:createdat;not null"`
+	UpdatedAt      time.Time `gorm:"column:updatedat;not null"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+func (j Job) IsFinished() bool {
+	return j.Status == JobCompleted || j.Status == JobFailed
+}
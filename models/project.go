@@ -0,0 +1,29 @@
+package models
+
+// DefaultProject holds the resources that were created before projects existed, and the ones created without a project.
+const DefaultProject = "default"
+
+type Project struct {
+	Name            string   `gorm:"column:name;primaryKey"`
+	SourceLanguage  string   `gorm:"column:sourcelanguage"`
+	TargetLanguages []string `gorm:"column:targetlanguages;serializer:json"`
+	// RetranslateStale queues the re-translation of the targets when the text of the source language changes
+	RetranslateStale bool `gorm:"column:retranslatestale;not null;default:false"`
+}
+
+func (Project) TableName() string {
+	return "projects"
+}
+
+// AllowsTargetLanguage reports whether resources can be translated to the language, all languages are allowed when no targets are set.
+func (p Project) AllowsTargetLanguage(languageCode string) bool {
+	if len(p.TargetLanguages) == 0 {
+		return true
+	}
+	for _, target := range p.TargetLanguages {
+		if target == languageCode {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,17 @@
 package models
 
 type Resource struct {
-	Key          string `gorm:"column:key"`
-	LanguageCode string `gorm:"column:languagecode"`
+	Project      string `gorm:"column:project;not null;default:default;uniqueIndex:idx_resources_identity,priority:1"`
+	Key          string `gorm:"column:key;uniqueIndex:idx_resources_identity,priority:2"`
+	LanguageCode string `gorm:"column:languagecode;uniqueIndex:idx_resources_identity,priority:3"`
 	Text         string `gorm:"column:text"`
+	Description  string `gorm:"column:description"`
+	Status       string `gorm:"column:status;not null;default:untranslated"`
+	// SourceHash is the hash of the source text a translation was made from, empty for resources that weren't translated
+	SourceHash string `gorm:"column:sourcehash;not null;default:''"`
+	// Provider is the name of the translator that made a machine translation when translators are chained,
+	// or ProviderMemory when it was reused from the translation memory
+	Provider string `gorm:"column:provider;not null;default:''"`
 }
 
 func (Resource) TableName() string {
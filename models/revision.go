@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	RevisionUpdated  = "updated"
+	RevisionDeleted  = "deleted"
+	RevisionRestored = "restored"
+)
+
+// Revision is a change to the text of a resource. Rolling back a revision sets the text back to OldText.
+type Revision struct {
+	ID           int64     `gorm:"column:id;primaryKey"`
+	Project      string    `gorm:"column:project;not null;index:idx_revisions_resource,priority:1"`
+	Key          string    `gorm:"column:key;not null;index:idx_revisions_resource,priority:2"`
+	LanguageCode string    `gorm:"column:languagecode;not null;index:idx_revisions_resource,priority:3"`
+	Action       string    `gorm:"column:action;not null"`
+	OldText      string    `gorm:"column:oldtext;not null"`
+	NewText      string    `gorm:"column:newtext;not null"`
+	Username     string    `gorm:"column:username;not null"`
+	CreatedAt    time.Time `gorm:"column:createdat;not null;default:now()"`
+}
+
+func (Revision) TableName() string {
+	return "resource_revisions"
+}
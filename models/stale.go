@@ -0,0 +1,48 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+)
+
+// StaleTranslation is a translation made from a source text that changed since. Reviewed and approved translations
+// need review, re-translating only overwrites untranslated and machine translations.
+type StaleTranslation struct {
+	Resource
+	SourceText  string
+	NeedsReview bool
+}
+
+// SourceHash identifies the source text a translation was made from.
+func SourceHash(text string) string {
+	hash := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(hash[:])
+}
+
+// RetranslatableStatuses are the statuses of translations that can be overwritten by a machine translation.
+var RetranslatableStatuses = []string{"", StatusUntranslated, StatusMachine}
+
+// CanRetranslate reports whether a translation in the status can be overwritten by a machine translation.
+func CanRetranslate(status string) bool {
+	return slices.Contains(RetranslatableStatuses, status)
+}
+
+// FindStaleTranslations returns the target resources whose source hash doesn't match the text of the source resource
+// with the same key. Targets without source hash weren't translated by the service, so they're never stale.
+func FindStaleTranslations(sourceResources, targetResources []Resource) []StaleTranslation {
+	sourceTexts := map[string]string{}
+	for _, resource := range sourceResources {
+		sourceTexts[resource.Key] = resource.Text
+	}
+
+	results := []StaleTranslation{}
+	for _, resource := range targetResources {
+		sourceText, exists := sourceTexts[resource.Key]
+		if !exists || resource.SourceHash == "" || resource.SourceHash == SourceHash(sourceText) {
+			continue
+		}
+		results = append(results, StaleTranslation{Resource: resource, SourceText: sourceText, NeedsReview: !CanRetranslate(resource.Status)})
+	}
+	return results
+}
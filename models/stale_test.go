@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindStaleTranslations_ShouldReturnTranslationsOfChangedSources(t *testing.T) {
+	source := []Resource{
+		{Key: "changed", LanguageCode: "en", Text: "new text"},
+		{Key: "same", LanguageCode: "en", Text: "same text"},
+		{Key: "reviewed", LanguageCode: "en", Text: "new text"},
+		{Key: "manual", LanguageCode: "en", Text: "new text"},
+	}
+	target := []Resource{
+		{Key: "changed", LanguageCode: "es", Status: StatusMachine, SourceHash: SourceHash("old text")},
+		{Key: "same", LanguageCode: "es", Status: StatusMachine, SourceHash: SourceHash("same text")},
+		{Key: "reviewed", LanguageCode: "es", Status: StatusReviewed, SourceHash: SourceHash("old text")},
+		{Key: "manual", LanguageCode: "es", Status: StatusApproved},
+		{Key: "removed", LanguageCode: "es", Status: StatusMachine, SourceHash: SourceHash("old text")},
+	}
+
+	results := FindStaleTranslations(source, target)
+
+	assert.Len(t, results, 2)
+	assert.Equal(t, "changed", results[0].Key)
+	assert.Equal(t, "new text", results[0].SourceText)
+	assert.False(t, results[0].NeedsReview)
+	assert.Equal(t, "reviewed", results[1].Key)
+	assert.True(t, results[1].NeedsReview)
+}
+
+func TestCanRetranslate(t *testing.T) {
+	assert.True(t, CanRetranslate(""))
+	assert.True(t, CanRetranslate(StatusUntranslated))
+	assert.True(t, CanRetranslate(StatusMachine))
+	assert.False(t, CanRetranslate(StatusReviewed))
+	assert.False(t, CanRetranslate(StatusApproved))
+}
@@ -0,0 +1,38 @@
+package models
+
+// Translation statuses of a resource. Translators produce machine translations, which reviewers move to reviewed and approved.
+const (
+	StatusUntranslated = "untranslated"
+	StatusMachine      = "machine"
+	StatusReviewed     = "reviewed"
+	StatusApproved     = "approved"
+)
+
+// StatusEdited is the status of a text edited by hand, the editor counts as its reviewer.
+const StatusEdited = StatusReviewed
+
+var statusTransitions = map[string][]string{
+	StatusUntranslated: {StatusReviewed},
+	StatusMachine:      {StatusReviewed},
+	StatusReviewed:     {StatusApproved},
+	StatusApproved:     {StatusReviewed},
+}
+
+func IsValidStatus(status string) bool {
+	_, exists := statusTransitions[status]
+	return exists
+}
+
+// CanTransition reports whether a reviewer can move a resource from one status to the other.
+// Texts are approved after being reviewed, and approved texts can be sent back to review.
+func CanTransition(from, to string) bool {
+	if from == "" {
+		from = StatusUntranslated
+	}
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		expected bool
+	}{
+		{StatusMachine, StatusReviewed, true},
+		{"", StatusReviewed, true},
+		{StatusReviewed, StatusApproved, true},
+		{StatusApproved, StatusReviewed, true},
+		{StatusMachine, StatusApproved, false},
+		{StatusReviewed, StatusMachine, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CanTransition(tt.from, tt.to))
+		})
+	}
+}
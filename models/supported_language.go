@@ -0,0 +1,8 @@
+package models
+
+// SupportedLanguage is a language a translator can translate from, to the Targets languages.
+type SupportedLanguage struct {
+	Code    string   `json:"code"`
+	Name    string   `json:"name"`
+	Targets []string `json:"targets"`
+}
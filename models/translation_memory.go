@@ -0,0 +1,79 @@
+package models
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// ProviderMemory is the Provider of translations reused from the translation memory.
+const ProviderMemory = "memory"
+
+// MemoryEntry is an approved translation of a source text.
+type MemoryEntry struct {
+	SourceLanguage string
+	SourceText     string
+	TargetLanguage string
+	TargetText     string
+}
+
+// MemoryMatch is an entry with a source text similar to the searched text, Similarity is 1 for the same text.
+type MemoryMatch struct {
+	MemoryEntry
+	Similarity float64
+}
+
+// Similarity compares the texts by their Levenshtein distance, from 0 for different texts to 1 for the same text.
+func Similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	source, target := []rune(a), []rune(b)
+	longest := max(len(source), len(target))
+	return 1 - float64(levenshtein(source, target))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// SimilarLengths returns the range of lengths texts can have to be at least minSimilarity similar to the text,
+// to filter the entries before comparing them. minSimilarity must be above 0.
+func SimilarLengths(text string, minSimilarity float64) (shortest, longest int) {
+	length := utf8.RuneCountInString(text)
+	return int(float64(length) * minSimilarity), int(float64(length) / minSimilarity)
+}
+
+// FindMemoryMatches returns up to limit entries with a source text at least minSimilarity similar to the text,
+// the most similar first.
+func FindMemoryMatches(text string, entries []MemoryEntry, minSimilarity float64, limit int) []MemoryMatch {
+	matches := []MemoryMatch{}
+	for _, entry := range entries {
+		if similarity := Similarity(text, entry.SourceText); similarity >= minSimilarity {
+			matches = append(matches, MemoryMatch{MemoryEntry: entry, Similarity: similarity})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Similarity > matches[j].Similarity })
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
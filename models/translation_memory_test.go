@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimilarity_ShouldCompareByEditDistance(t *testing.T) {
+	assert.Equal(t, 1.0, Similarity("Save changes", "Save changes"))
+	assert.Equal(t, 0.0, Similarity("abc", "xyz"))
+	assert.InDelta(t, 0.75, Similarity("Save", "Sav"), 0.001)
+	assert.InDelta(t, 1-1.0/7, Similarity("Päivitä", "Päivita"), 0.001, "characters should be compared, not bytes")
+}
+
+func TestFindMemoryMatches_ShouldReturnSimilarEntriesMostSimilarFirst(t *testing.T) {
+	entries := []MemoryEntry{
+		{SourceText: "Save all changes", TargetText: "Alle Änderungen speichern"},
+		{SourceText: "Delete account", TargetText: "Konto löschen"},
+		{SourceText: "Save changes", TargetText: "Änderungen speichern"},
+		{SourceText: "Save change", TargetText: "Änderung speichern"},
+	}
+
+	matches := FindMemoryMatches("Save changes", entries, 0.7, 2)
+
+	assert.Len(t, matches, 2)
+	assert.Equal(t, "Änderungen speichern", matches[0].TargetText)
+	assert.Equal(t, 1.0, matches[0].Similarity)
+	assert.Equal(t, "Änderung speichern", matches[1].TargetText)
+}
+
+func TestFindMemoryMatches_WhenNothingIsSimilarEnough_ShouldReturnEmpty(t *testing.T) {
+	entries := []MemoryEntry{{SourceText: "Delete account", TargetText: "Konto löschen"}}
+
+	matches := FindMemoryMatches("Save changes", entries, 0.7, 5)
+
+	assert.Empty(t, matches)
+}
+
+func TestSimilarLengths_ShouldIncludeEveryLengthThatCanBeSimilarEnough(t *testing.T) {
+	shortest, longest := SimilarLengths("Save changes", 0.75)
+
+	assert.Equal(t, 9, shortest)
+	assert.Equal(t, 16, longest)
+	assert.GreaterOrEqual(t, Similarity("Save changes", "Save chan"), 0.75)
+	assert.GreaterOrEqual(t, Similarity("Save changes", "Save changes all"), 0.75)
+}
@@ -0,0 +1,24 @@
+package models
+
+// ConflictPolicy decides what happens when an upserted resource already exists for its project, key and language.
+type ConflictPolicy string
+
+const (
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	ConflictKeep      ConflictPolicy = "keep"
+	ConflictFail      ConflictPolicy = "fail"
+	// ConflictStale only overwrites the resources that are still stale machine translations, the re-translations use
+	// it so translations reviewed or translated again since they were found stale are kept. Requests can't choose it.
+	ConflictStale ConflictPolicy = "stale"
+)
+
+func (p ConflictPolicy) IsValid() bool {
+	return p == ConflictOverwrite || p == ConflictKeep || p == ConflictFail
+}
+
+// UpsertResult counts the resources that were created, updated, and skipped because they were kept or unchanged.
+type UpsertResult struct {
+	Created int64
+	Updated int64
+	Skipped int64
+}